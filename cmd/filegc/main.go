@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"rttask/internal/app"
+	"rttask/internal/config"
+	"rttask/internal/domain/service/file"
+	"rttask/internal/infrastructure/persistence/postgres"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// filegc разовый запуск сборщика файлов без ссылок.
+// По умолчанию только печатает отчет, для удаления нужен -dry-run=false
+func main() {
+	cfg := config.MustLoadConfig()
+
+	dryRun := flag.Bool("dry-run", true, "only report orphaned files, do not delete")
+	grace := flag.Duration("grace", cfg.FileGC.GraceDuration(), "skip files modified within this period")
+	verbose := flag.Bool("v", false, "print every orphaned file")
+	flag.Parse()
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	db := postgres.MustNewConn(cfg.Database, logger)
+	container := app.NewContainer(cfg, db, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := container.FileGC.Run(ctx, file.GCOptions{GracePeriod: *grace, DryRun: *dryRun})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "file gc failed:", err)
+		os.Exit(1)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *verbose {
+		fmt.Fprintln(w, "PATH\tSIZE\tMODIFIED")
+		for _, orphan := range report.Orphaned {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", orphan.Path, orphan.Size, orphan.ModTime.Format(time.RFC3339))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "dry run:\t%t\n", report.DryRun)
+	fmt.Fprintf(w, "grace period:\t%s\n", *grace)
+	fmt.Fprintf(w, "scanned files:\t%d\n", report.Scanned)
+	fmt.Fprintf(w, "referenced paths:\t%d\n", report.Referenced)
+	fmt.Fprintf(w, "orphaned files:\t%d (%d bytes)\n", len(report.Orphaned), report.OrphanedBytes())
+	if !report.DryRun {
+		fmt.Fprintf(w, "deleted files:\t%d (%d bytes)\n", report.Deleted, report.FreedBytes)
+		fmt.Fprintf(w, "failed deletions:\t%d\n", report.Failed)
+	}
+	fmt.Fprintf(w, "duration:\t%s\n", report.Duration.Round(time.Millisecond))
+	w.Flush()
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"rttask/internal/config"
 	"rttask/internal/domain/model"
 	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/service/file"
 	"rttask/internal/infrastructure/persistence/postgres"
 	"rttask/internal/scripts"
 	"rttask/internal/transport/http/handlers"
 	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/socket"
 	"time"
+	_ "time/tzdata"
 
 	_ "rttask/docs"
 
@@ -49,9 +52,25 @@ func main() {
 		&model.User{},
 		&model.File{},
 		&model.Task{},
+		&model.ChecklistItem{},
+		&model.TaskDependency{},
+		&model.TaskAssignee{},
+		&model.Recurrence{},
+		&model.TaskTemplate{},
+		&model.TaskReminder{},
+		&model.Notification{},
+		&model.EmailSettings{},
+		&model.EmailDigestItem{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+		&model.TelegramLink{},
+		&model.TelegramLinkCode{},
 		&model.Comment{},
+		&model.Mention{},
 		&model.InviteLink{},
+		&model.Upload{},
 	)
+	postgres.MustMigrateSearch(db, logger)
 	logger.Info("config loaded", zap.String("ENV", cfg.Env))
 
 	container := app.NewContainer(cfg, db, logger)
@@ -63,13 +82,40 @@ func main() {
 	scripts.CreateAdminRoleIfNotExists(ctx, logger, container.RoleRepository)
 	scripts.AssignAdminRoleToAdmin(ctx, cfg.Admin, logger, container.RoleRepository, db)
 
+	if cfg.FileGC.Enabled {
+		go container.FileGC.Start(ctx, cfg.FileGC.IntervalDuration(), file.GCOptions{
+			GracePeriod: cfg.FileGC.GraceDuration(),
+			DryRun:      cfg.FileGC.DryRun,
+		})
+	}
+
+	if cfg.Recurrence.Enabled {
+		go container.RecurrenceScheduler.Start(ctx, cfg.Recurrence.IntervalDuration())
+	}
+	if cfg.Reminders.Enabled {
+		go container.DeadlineScheduler.Start(ctx, cfg.Reminders.IntervalDuration())
+	}
+	if cfg.Mail.Enabled {
+		go container.EmailService.Start(ctx)
+		go container.DigestScheduler.Start(ctx)
+	}
+	if cfg.Webhooks.Enabled {
+		go container.WebhookDispatcher.Start(ctx, cfg.Webhooks.IntervalDuration())
+	}
+	if cfg.Telegram.Enabled {
+		go container.TelegramService.Start(ctx)
+		if cfg.Telegram.Mode != "webhook" {
+			go container.TelegramPoller.Start(ctx)
+		}
+	}
+
 	router := gin.Default()
 	router.Use(middleware.TraceMiddleware())
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"https://rt-task-frontend.vercel.app", "https://realtimemap.ru", "http://localhost:5173", "http://localhost:1420", "http://localhost:8080"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Trace-Id"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Trace-Id", "Range", "Upload-Length", "Upload-Offset", "Upload-Metadata", "Tus-Resumable"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition", "Content-Range", "Accept-Ranges", "Location", "Upload-Offset", "Upload-Length", "Upload-Expires", "Upload-File-Id", "Tus-Resumable"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -97,11 +143,24 @@ func main() {
 		c.Redirect(302, "/swagger/index.html")
 	})
 
+	// socket.io клиенты подключаются по websocket и авторизуются событием authenticate
+	socketServer := socket.NewSocketServer(container.TaskService, container.JWTManager, container.EventBus, logger)
+	router.GET("/socket.io/*any", gin.WrapH(socketServer.HttpHandler()))
+
 	handlers.InitAuthHandler(router.Group("/"), container.JWTManager, container.AuthService)
 	handlers.InitInviteHandler(router.Group("/"), container.InviteService, logger, container.JWTManager, container.Mapper)
 	handlers.InitRoleHandler(router.Group("/"), container.RoleService, logger, container.JWTManager, container.Mapper)
 	handlers.InitCompanyHandler(router.Group("/"), container.CompanyService, logger, container.JWTManager, container.Mapper)
 	handlers.InitTaskHandler(router.Group("/"), container.TaskService, logger, container.JWTManager, container.Mapper)
+	handlers.InitCommentHandler(router.Group("/"), container.CommentService, logger, container.JWTManager, container.Mapper)
+	handlers.InitTemplateHandler(router.Group("/"), container.TemplateService, logger, container.JWTManager, container.Mapper)
+	handlers.InitRecurrenceHandler(router.Group("/"), container.TaskService, logger, container.JWTManager, container.Mapper)
+	handlers.InitFileHandler(router.Group("/"), container.FileService, logger, container.JWTManager, container.Mapper)
+	handlers.InitUploadHandler(router.Group("/"), container.UploadService, logger, container.JWTManager, container.Mapper)
+	handlers.InitNotificationHandler(router.Group("/"), container.NotificationService, container.EmailService, logger, container.JWTManager, container.Mapper)
+	handlers.InitWebhookHandler(router.Group("/"), container.WebhookService, logger, container.JWTManager, container.Mapper)
+	handlers.InitTelegramHandler(router.Group("/"), container.TelegramService, logger, container.JWTManager, container.Mapper)
+	handlers.InitSearchHandler(router.Group("/"), container.SearchService, logger, container.JWTManager, container.Mapper)
 
 	router.Run(":8081")
 }
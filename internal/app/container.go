@@ -1,17 +1,30 @@
 package app
 
 import (
+	"context"
 	"rttask/internal/config"
+	"rttask/internal/domain/event"
 	"rttask/internal/domain/repository"
 	"rttask/internal/domain/service/auth"
+	"rttask/internal/domain/service/comment"
 	"rttask/internal/domain/service/company"
 	"rttask/internal/domain/service/file"
 	"rttask/internal/domain/service/invite"
+	"rttask/internal/domain/service/mention"
+	"rttask/internal/domain/service/notification"
 	"rttask/internal/domain/service/role"
+	"rttask/internal/domain/service/search"
 	"rttask/internal/domain/service/task"
+	"rttask/internal/domain/service/telegram"
+	"rttask/internal/domain/service/template"
+	"rttask/internal/domain/service/webhook"
+	"rttask/internal/infrastructure/antivirus"
+	"rttask/internal/infrastructure/mail"
 	"rttask/internal/infrastructure/persistence/postgres"
 	"rttask/internal/infrastructure/security"
 	"rttask/internal/infrastructure/storage"
+	telegramclient "rttask/internal/infrastructure/telegram"
+	webhookclient "rttask/internal/infrastructure/webhook"
 	"rttask/internal/transport/http/response"
 
 	"go.uber.org/zap"
@@ -19,11 +32,28 @@ import (
 )
 
 type Container struct {
-	AuthService    *auth.AuthService
-	InviteService  *invite.InviteService
-	RoleService    *role.RoleService
-	CompanyService *company.CompanyService
-	TaskService    *task.TaskService
+	AuthService         *auth.AuthService
+	InviteService       *invite.InviteService
+	RoleService         *role.RoleService
+	CompanyService      *company.CompanyService
+	TaskService         *task.TaskService
+	CommentService      *comment.CommentService
+	FileService         *file.FileService
+	UploadService       *file.UploadService
+	SearchService       *search.SearchService
+	TemplateService     *template.TemplateService
+	NotificationService *notification.NotificationService
+	EmailService        *notification.EmailService
+	WebhookService      *webhook.WebhookService
+	TelegramService     *telegram.TelegramService
+
+	FileGC              *file.GarbageCollector
+	RecurrenceScheduler *task.RecurrenceScheduler
+	DeadlineScheduler   *task.DeadlineScheduler
+	DigestScheduler     *notification.DigestScheduler
+	WebhookDispatcher   *webhook.WebhookDispatcher
+	TelegramPoller      *telegram.TelegramPoller
+	EventBus            *event.Bus
 
 	JWTManager security.JWTManager
 	Mapper     *response.ErrorMapper
@@ -41,27 +71,102 @@ func NewContainer(cfg config.Config, db *gorm.DB, logger *zap.Logger) *Container
 	roleRepo := postgres.NewPgRoleRepository(db, logger)
 	companyRepo := postgres.NewPgCompanyRepository(db, logger)
 	taskRepo := postgres.NewPgTaskRepository(db, logger)
+	checklistRepo := postgres.NewPgChecklistRepository(db, logger)
+	dependencyRepo := postgres.NewPgDependencyRepository(db, logger)
+	assigneeRepo := postgres.NewPgAssigneeRepository(db, logger)
+	recurrenceRepo := postgres.NewPgRecurrenceRepository(db, logger)
+	templateRepo := postgres.NewPgTemplateRepository(db, logger)
+	reminderRepo := postgres.NewPgReminderRepository(db, logger)
+	notificationRepo := postgres.NewPgNotificationRepository(db, logger)
+	emailRepo := postgres.NewPgEmailRepository(db, logger)
+	webhookRepo := postgres.NewPgWebhookRepository(db, logger)
+	telegramRepo := postgres.NewPgTelegramRepository(db, logger)
+	fileRepo := postgres.NewPgFileRepository(db, logger)
+	uploadRepo := postgres.NewPgUploadRepository(db, logger)
+	searchRepo := postgres.NewPgSearchRepository(db, logger)
+	commentRepo := postgres.NewPgCommentRepository(db, logger)
+	mentionRepo := postgres.NewPgMentionRepository(db, logger)
+	uow := postgres.NewPgUnitOfWork(db, logger)
 	// JWT хелперы
 
 	passwordHasher := security.NewBcryptHasher()
 	manager := security.NewCustomJWTManager(cfg.JWT.Secret)
 	mapper := response.NewErrorMapper()
 
-	store := storage.NewLocalStorage("./store")
+	store := storage.MustNewStorage(context.Background(), cfg.Storage, logger)
+	scanner := antivirus.NewScanner(cfg.Antivirus)
+	mailer := mail.NewMailer(cfg.Mail)
+	bot := telegramclient.NewClient(cfg.Telegram)
+	eventBus := event.NewBus(logger)
+	quota := file.Quota{CompanyBytes: cfg.Quota.CompanyBytes(), UserBytes: cfg.Quota.UserBytes()}
 
 	// Сервисы
-	fileService := file.NewFileService(store, logger)
-	authService := auth.NewAuthService(userRepo, inviteRepo, fileService, passwordHasher, manager, cfg.JWT.AccessTokenTimeDuration(), cfg.JWT.RefreshTokenTimeDuration(), logger)
+	fileService := file.NewFileService(store, scanner, fileRepo, userRepo, uow, quota, logger)
+	uploadService := file.NewUploadService(fileService, uploadRepo, logger)
+	fileGC := file.NewGarbageCollector(store, fileRepo, logger)
+	searchService := search.NewSearchService(searchRepo, userRepo, logger)
+	authService := auth.NewAuthService(userRepo, inviteRepo, uow, fileService, passwordHasher, manager, cfg.JWT.AccessTokenTimeDuration(), cfg.JWT.RefreshTokenTimeDuration(), logger)
 	inviteService := invite.NewInviteService(inviteRepo, userRepo, roleRepo, logger)
 	roleService := role.NewRoleService(roleRepo, userRepo, logger)
-	companyService := company.NewCompanyService(companyRepo, userRepo, fileService, logger)
-	taskService := task.NewTaskService(taskRepo, userRepo, companyRepo, fileService, logger)
+	companyService := company.NewCompanyService(companyRepo, userRepo, uow, fileService, logger)
+	mentionService := mention.NewMentionService(mentionRepo, userRepo, logger)
+	taskService := task.NewTaskService(taskRepo, checklistRepo, dependencyRepo, assigneeRepo, recurrenceRepo, reminderRepo, userRepo, companyRepo, uow, fileService, uploadService, mentionService, eventBus, logger)
+	commentService := comment.NewCommentService(commentRepo, taskRepo, userRepo, uow, fileService, uploadService, mentionService, eventBus, logger)
+	templateService := template.NewTemplateService(templateRepo, userRepo, companyRepo, uow, taskService, fileService, uploadService, logger)
+	notificationService := notification.NewNotificationService(notificationRepo, eventBus, logger)
+	notificationService.Subscribe(eventBus)
+	emailService := notification.NewEmailService(emailRepo, userRepo, uow, mailer, mail.MustNewRenderer(), notification.EmailOptions{
+		AppURL:    cfg.Mail.AppURL,
+		Locale:    cfg.Mail.Locale,
+		QueueSize: cfg.Mail.QueueSize,
+	}, logger)
+	if cfg.Mail.Enabled {
+		emailService.Subscribe(eventBus)
+	}
+	webhookService := webhook.NewWebhookService(webhookRepo, userRepo, uow, webhookclient.MustNewHTTPSender(cfg.Webhooks.TimeoutDuration(), cfg.Webhooks.AllowedNetworks), webhook.Options{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		Backoff:     cfg.Webhooks.BackoffDuration(),
+		MaxBackoff:  cfg.Webhooks.MaxBackoffDuration(),
+		Lease:       2 * cfg.Webhooks.TimeoutDuration(),
+	}, logger)
+	if cfg.Webhooks.Enabled {
+		webhookService.Subscribe(eventBus)
+	}
+	telegramService := telegram.NewTelegramService(telegramRepo, userRepo, uow, taskService, bot, telegram.Options{
+		BotUsername:   cfg.Telegram.BotUsername,
+		WebhookSecret: cfg.Telegram.WebhookSecret,
+		CodeTTL:       cfg.Telegram.CodeTTLDuration(),
+		QueueSize:     cfg.Telegram.QueueSize,
+	}, logger)
+	if cfg.Telegram.Enabled {
+		telegramService.Subscribe(eventBus)
+	}
 	return &Container{
-		AuthService:    authService,
-		InviteService:  inviteService,
-		RoleService:    roleService,
-		CompanyService: companyService,
-		TaskService:    taskService,
+		AuthService:         authService,
+		InviteService:       inviteService,
+		RoleService:         roleService,
+		CompanyService:      companyService,
+		TaskService:         taskService,
+		CommentService:      commentService,
+		FileService:         fileService,
+		UploadService:       uploadService,
+		SearchService:       searchService,
+		TemplateService:     templateService,
+		NotificationService: notificationService,
+		EmailService:        emailService,
+		WebhookService:      webhookService,
+		TelegramService:     telegramService,
+
+		FileGC:              fileGC,
+		RecurrenceScheduler: task.NewRecurrenceScheduler(taskService, cfg.Recurrence.HorizonDuration(), logger),
+		DeadlineScheduler: task.NewDeadlineScheduler(taskService, task.DeadlineOptions{
+			Offsets:  cfg.Reminders.OffsetDurations(),
+			Escalate: cfg.Reminders.Escalate,
+		}, logger),
+		DigestScheduler:   notification.NewDigestScheduler(emailService, cfg.Mail.DigestHour, cfg.Mail.DigestLocation(), logger),
+		WebhookDispatcher: webhook.NewWebhookDispatcher(webhookService, logger),
+		TelegramPoller:    telegram.NewTelegramPoller(telegramService, bot, cfg.Telegram.PollTimeout, logger),
+		EventBus:          eventBus,
 
 		JWTManager: manager,
 		Mapper:     mapper,
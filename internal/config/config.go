@@ -49,11 +49,207 @@ type Admin struct {
 	Password string `yaml:"password" env:"ADMIN_PASSWORD" env-default:"admin1admin"`
 }
 
+type S3 struct {
+	Endpoint string `yaml:"endpoint" env:"S3_ENDPOINT" env-default:"localhost:9000"`
+	// PublicEndpoint адрес хранилища, доступный клиентам, для временных ссылок. Пустой отключает
+	// ссылки, файлы отдаются через приложение
+	PublicEndpoint string `yaml:"publicEndpoint" env:"S3_PUBLIC_ENDPOINT"`
+	PublicUseSSL   bool   `yaml:"publicUseSSL" env:"S3_PUBLIC_USE_SSL" env-default:"true"`
+	AccessKey      string `yaml:"accessKey" env:"S3_ACCESS_KEY"`
+	SecretKey      string `yaml:"secretKey" env:"S3_SECRET_KEY"`
+	Bucket         string `yaml:"bucket" env:"S3_BUCKET" env-default:"rttask"`
+	Region         string `yaml:"region" env:"S3_REGION" env-default:"us-east-1"`
+	UseSSL         bool   `yaml:"useSSL" env:"S3_USE_SSL" env-default:"false"`
+	PartSize       uint64 `yaml:"partSize" env:"S3_PART_SIZE" env-default:"16"`
+	PresignTTL     int    `yaml:"presignTTL" env:"S3_PRESIGN_TTL" env-default:"15"`
+}
+
+// PartSizeBytes размер части multipart загрузки, в конфиге задается в мегабайтах
+func (s S3) PartSizeBytes() uint64 {
+	return s.PartSize * 1024 * 1024
+}
+
+func (s S3) PresignDuration() time.Duration {
+	return time.Duration(s.PresignTTL) * time.Minute
+}
+
+type Storage struct {
+	Driver    string `yaml:"driver" env:"STORAGE_DRIVER" env-default:"local"`
+	LocalPath string `yaml:"localPath" env:"STORAGE_LOCAL_PATH" env-default:"./store"`
+	S3        S3     `yaml:"s3"`
+}
+
+type Antivirus struct {
+	Enabled bool   `yaml:"enabled" env:"ANTIVIRUS_ENABLED" env-default:"false"`
+	Address string `yaml:"address" env:"CLAMD_ADDRESS" env-default:"localhost:3310"`
+	Timeout int    `yaml:"timeout" env:"CLAMD_TIMEOUT" env-default:"30"`
+}
+
+// Quota ограничения места в хранилище в мегабайтах, 0 без ограничения
+type Quota struct {
+	CompanyMB int64 `yaml:"companyMB" env:"QUOTA_COMPANY_MB" env-default:"10240"`
+	UserMB    int64 `yaml:"userMB" env:"QUOTA_USER_MB" env-default:"2048"`
+}
+
+func (q Quota) CompanyBytes() int64 {
+	return q.CompanyMB * 1024 * 1024
+}
+
+func (q Quota) UserBytes() int64 {
+	return q.UserMB * 1024 * 1024
+}
+
+// FileGC сборщик файлов без ссылок. Интервал и срок в часах
+type FileGC struct {
+	Enabled     bool `yaml:"enabled" env:"FILE_GC_ENABLED" env-default:"false"`
+	Interval    int  `yaml:"interval" env:"FILE_GC_INTERVAL" env-default:"24"`
+	GracePeriod int  `yaml:"gracePeriod" env:"FILE_GC_GRACE_PERIOD" env-default:"48"`
+	DryRun      bool `yaml:"dryRun" env:"FILE_GC_DRY_RUN" env-default:"false"`
+}
+
+func (g FileGC) IntervalDuration() time.Duration {
+	return time.Duration(g.Interval) * time.Hour
+}
+
+func (g FileGC) GraceDuration() time.Duration {
+	return time.Duration(g.GracePeriod) * time.Hour
+}
+
+// Recurrence планировщик повторяющихся задач. Интервал в минутах, горизонт в часах:
+// задачи создаются на столько вперед
+type Recurrence struct {
+	Enabled  bool `yaml:"enabled" env:"RECURRENCE_ENABLED" env-default:"true"`
+	Interval int  `yaml:"interval" env:"RECURRENCE_INTERVAL" env-default:"15"`
+	Horizon  int  `yaml:"horizon" env:"RECURRENCE_HORIZON" env-default:"168"`
+}
+
+func (r Recurrence) IntervalDuration() time.Duration {
+	return time.Duration(r.Interval) * time.Minute
+}
+
+func (r Recurrence) HorizonDuration() time.Duration {
+	return time.Duration(r.Horizon) * time.Hour
+}
+
+// Reminders напоминания о сроках задач. Offsets за сколько минут до срока напоминать,
+// интервал проверки в минутах
+type Reminders struct {
+	Enabled  bool  `yaml:"enabled" env:"REMINDERS_ENABLED" env-default:"true"`
+	Interval int   `yaml:"interval" env:"REMINDERS_INTERVAL" env-default:"5"`
+	Offsets  []int `yaml:"offsets" env:"REMINDERS_OFFSETS" env-default:"1440,60"`
+	Escalate bool  `yaml:"escalate" env:"REMINDERS_ESCALATE" env-default:"false"`
+}
+
+func (r Reminders) IntervalDuration() time.Duration {
+	return time.Duration(r.Interval) * time.Minute
+}
+
+func (r Reminders) OffsetDurations() []time.Duration {
+	offsets := make([]time.Duration, 0, len(r.Offsets))
+	for _, offset := range r.Offsets {
+		offsets = append(offsets, time.Duration(offset)*time.Minute)
+	}
+	return offsets
+}
+
+// Webhooks доставка событий во внешние системы. Interval и Timeout в секундах, Backoff пауза
+// после первой неудачной попытки в секундах, MaxBackoff предел паузы в минутах
+type Webhooks struct {
+	Enabled     bool `yaml:"enabled" env:"WEBHOOKS_ENABLED" env-default:"true"`
+	Interval    int  `yaml:"interval" env:"WEBHOOKS_INTERVAL" env-default:"10"`
+	Timeout     int  `yaml:"timeout" env:"WEBHOOKS_TIMEOUT" env-default:"10"`
+	MaxAttempts int  `yaml:"maxAttempts" env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"8"`
+	Backoff     int  `yaml:"backoff" env:"WEBHOOKS_BACKOFF" env-default:"30"`
+	MaxBackoff  int  `yaml:"maxBackoff" env:"WEBHOOKS_MAX_BACKOFF" env-default:"360"`
+	// AllowedNetworks CIDR внутренних сетей, куда разрешена доставка. Остальные внутренние адреса запрещены
+	AllowedNetworks []string `yaml:"allowedNetworks" env:"WEBHOOKS_ALLOWED_NETWORKS" env-separator:","`
+}
+
+func (w Webhooks) IntervalDuration() time.Duration {
+	return time.Duration(w.Interval) * time.Second
+}
+
+func (w Webhooks) TimeoutDuration() time.Duration {
+	return time.Duration(w.Timeout) * time.Second
+}
+
+func (w Webhooks) BackoffDuration() time.Duration {
+	return time.Duration(w.Backoff) * time.Second
+}
+
+func (w Webhooks) MaxBackoffDuration() time.Duration {
+	return time.Duration(w.MaxBackoff) * time.Minute
+}
+
+// Telegram бот для уведомлений и быстрых действий. BaseURL меняется на локальный сервер в тестах.
+// Mode polling забирает обновления сам, webhook ждет их на /telegram/webhook с WebhookSecret в заголовке.
+// PollTimeout в секундах, CodeTTL время жизни кода привязки в минутах
+type Telegram struct {
+	Enabled       bool   `yaml:"enabled" env:"TELEGRAM_ENABLED" env-default:"false"`
+	Token         string `yaml:"token" env:"TELEGRAM_TOKEN"`
+	BaseURL       string `yaml:"baseURL" env:"TELEGRAM_BASE_URL" env-default:"https://api.telegram.org"`
+	BotUsername   string `yaml:"botUsername" env:"TELEGRAM_BOT_USERNAME"`
+	Mode          string `yaml:"mode" env:"TELEGRAM_MODE" env-default:"polling"`
+	WebhookSecret string `yaml:"webhookSecret" env:"TELEGRAM_WEBHOOK_SECRET"`
+	PollTimeout   int    `yaml:"pollTimeout" env:"TELEGRAM_POLL_TIMEOUT" env-default:"30"`
+	CodeTTL       int    `yaml:"codeTTL" env:"TELEGRAM_CODE_TTL" env-default:"10"`
+	QueueSize     int    `yaml:"queueSize" env:"TELEGRAM_QUEUE_SIZE" env-default:"256"`
+}
+
+// TimeoutDuration таймаут запроса к Bot API, с запасом на long polling
+func (t Telegram) TimeoutDuration() time.Duration {
+	return time.Duration(t.PollTimeout+10) * time.Second
+}
+
+func (t Telegram) CodeTTLDuration() time.Duration {
+	return time.Duration(t.CodeTTL) * time.Minute
+}
+
+type SMTP struct {
+	Host     string `yaml:"host" env:"SMTP_HOST" env-default:"localhost"`
+	Port     int    `yaml:"port" env:"SMTP_PORT" env-default:"587"`
+	User     string `yaml:"user" env:"SMTP_USER"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+}
+
+// Mail почтовые уведомления. Driver smtp или file (письма .eml в FileDir), сводка уходит
+// раз в день в DigestHour по часовому поясу DigestTimezone
+type Mail struct {
+	Enabled        bool   `yaml:"enabled" env:"MAIL_ENABLED" env-default:"false"`
+	Driver         string `yaml:"driver" env:"MAIL_DRIVER" env-default:"file"`
+	From           string `yaml:"from" env:"MAIL_FROM" env-default:"RTTask <noreply@rttask.local>"`
+	FileDir        string `yaml:"fileDir" env:"MAIL_FILE_DIR" env-default:"./mail"`
+	AppURL         string `yaml:"appURL" env:"MAIL_APP_URL" env-default:"https://rt-task-frontend.vercel.app"`
+	Locale         string `yaml:"locale" env:"MAIL_LOCALE" env-default:"ru"`
+	DigestHour     int    `yaml:"digestHour" env:"MAIL_DIGEST_HOUR" env-default:"8"`
+	DigestTimezone string `yaml:"digestTimezone" env:"MAIL_DIGEST_TIMEZONE" env-default:"Europe/Moscow"`
+	QueueSize      int    `yaml:"queueSize" env:"MAIL_QUEUE_SIZE" env-default:"256"`
+	SMTP           SMTP   `yaml:"smtp"`
+}
+
+// DigestLocation часовой пояс сводки, неизвестный пояс считается UTC
+func (m Mail) DigestLocation() *time.Location {
+	location, err := time.LoadLocation(m.DigestTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
 type Config struct {
-	Env      string   `env:"ENV" env-default:"local"`
-	Database Database `yaml:"database"`
-	JWT      JWT      `yaml:"jwt"`
-	Admin    Admin    `yaml:"admin"`
+	Env        string     `env:"ENV" env-default:"local"`
+	Database   Database   `yaml:"database"`
+	JWT        JWT        `yaml:"jwt"`
+	Admin      Admin      `yaml:"admin"`
+	Storage    Storage    `yaml:"storage"`
+	Antivirus  Antivirus  `yaml:"antivirus"`
+	Quota      Quota      `yaml:"quota"`
+	FileGC     FileGC     `yaml:"fileGC"`
+	Recurrence Recurrence `yaml:"recurrence"`
+	Reminders  Reminders  `yaml:"reminders"`
+	Mail       Mail       `yaml:"mail"`
+	Webhooks   Webhooks   `yaml:"webhooks"`
+	Telegram   Telegram   `yaml:"telegram"`
 }
 
 func MustLoadConfig() Config {
@@ -17,6 +17,7 @@ const (
 	ErrorTypeDatabase     ErrorType = "DATABASE_ERROR"
 	ErrorTypeNotFound     ErrorType = "NOT_FOUND"
 	ErrorTypeAlreadyExist ErrorType = "ALREADY_EXIST_ERROR"
+	ErrorTypeConflict     ErrorType = "CONFLICT"
 
 	// Авторизация
 
@@ -81,6 +82,14 @@ func NewAlreadyExistsError(entity, field, value string) *DomainError {
 	}
 }
 
+// NewConflictError состояние ресурса изменилось и операцию нельзя применить
+func NewConflictError(message string) *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeConflict,
+		Message: message,
+	}
+}
+
 func NewDatabaseError(message string, err error) *DomainError {
 	return &DomainError{
 		Type:    ErrorTypeDatabase,
@@ -105,6 +114,15 @@ func NewForbiddenError(message string) *DomainError {
 	}
 }
 
+// NewPrivilegeEscalationError попытка выдать права, которых нет у самого пользователя
+func NewPrivilegeEscalationError(permissions []string) *DomainError {
+	return &DomainError{
+		Type:    ErrorTypeForbidden,
+		Message: "cannot grant permissions you do not have",
+		Meta:    map[string]interface{}{"permissions": permissions},
+	}
+}
+
 func NewInternalError(message string, err error) *DomainError {
 	return &DomainError{
 		Type:    ErrorTypeInternal,
@@ -0,0 +1,59 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Handler func(ctx context.Context, e Event)
+
+// Bus синхронная шина событий внутри процесса. Обработчики должны быть быстрыми,
+// долгую работу они выносят в свои очереди
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]Handler
+	logger   *zap.Logger
+}
+
+func NewBus(logger *zap.Logger) *Bus {
+	return &Bus{
+		handlers: make(map[Type][]Handler),
+		logger:   logger,
+	}
+}
+
+func (b *Bus) Subscribe(t Type, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[t] = append(b.handlers[t], handler)
+}
+
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers[e.Type]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.call(ctx, handler, e)
+	}
+}
+
+// call паника одного подписчика не должна ломать запрос и остальных подписчиков
+func (b *Bus) call(ctx context.Context, handler Handler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("event handler panicked",
+				zap.String("event", string(e.Type)),
+				zap.Any("panic", r),
+			)
+		}
+	}()
+	handler(ctx, e)
+}
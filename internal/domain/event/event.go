@@ -0,0 +1,91 @@
+package event
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type Type string
+
+const (
+	TaskCreated      Type = "task.created"
+	TaskMoved        Type = "task.moved"
+	BlockerCompleted Type = "task.blocker_completed"
+	DeadlineReminder Type = "task.deadline_reminder"
+	TaskOverdue      Type = "task.overdue"
+	TaskAssigned     Type = "task.assigned"
+	CommentCreated   Type = "comment.created"
+	UserMentioned    Type = "comment.mentioned"
+
+	NotificationCreated Type = "notification.created"
+)
+
+// Event доменное событие. Публикуется сервисами после фиксации транзакции
+type Event struct {
+	Type       Type
+	CompanyID  uint
+	ActorID    uint
+	OccurredAt time.Time
+	Payload    any
+}
+
+// Publisher через него сервисы сообщают о событиях, не зная о подписчиках
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// TaskCreatedPayload создана задача, вручную, из шаблона или по повторению
+type TaskCreatedPayload struct {
+	Task *model.Task
+}
+
+// TaskMovedPayload задача перемещена на доске: сменила статус и/или позицию в колонке
+type TaskMovedPayload struct {
+	Task       *model.Task
+	FromStatus model.Status
+	PrevTaskID uint
+	NextTaskID uint
+}
+
+// BlockerCompletedPayload выполнена задача Blocker, которую ждала Task. OpenBlockers сколько блокеров у Task осталось
+type BlockerCompletedPayload struct {
+	Task         *model.Task
+	Blocker      *model.Task
+	OpenBlockers int
+}
+
+// DeadlinePayload до срока Task осталось не больше Ahead, для просрочки Ahead 0.
+// Escalated задача при этом переведена в статус "Срочная"
+type DeadlinePayload struct {
+	Task       *model.Task
+	Ahead      time.Duration
+	Recipients []uint
+	Escalated  bool
+}
+
+// TaskAssignedPayload пользователи, которые только что стали исполнителями или наблюдателями Task
+type TaskAssignedPayload struct {
+	Task        *model.Task
+	ExecutorIDs []uint
+	WatcherIDs  []uint
+}
+
+// CommentCreatedPayload к Task добавлен комментарий
+type CommentCreatedPayload struct {
+	Task    *model.Task
+	Comment *model.Comment
+}
+
+// UserMentionedPayload пользователей UserIDs упомянули в комментарии к Task
+type UserMentionedPayload struct {
+	Task    *model.Task
+	Comment *model.Comment
+	UserIDs []uint
+}
+
+// NotificationCreatedPayload сохранено уведомление, Unread непрочитанных у получателя вместе с ним
+type NotificationCreatedPayload struct {
+	Notification *model.Notification
+	Unread       int64
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+type AssigneeRole string
+
+const (
+	// ExecutorRole отвечает за задачу и отдельно подтверждает свою часть работы
+	ExecutorRole AssigneeRole = "executor"
+	// WatcherRole получает уведомления, но за задачу не отвечает
+	WatcherRole AssigneeRole = "watcher"
+)
+
+// TaskAssignee участник задачи. Пользователь участвует в задаче только в одной роли
+type TaskAssignee struct {
+	ID             uint `gorm:"primarykey"`
+	TaskID         uint `gorm:"uniqueIndex:idx_task_assignee"`
+	UserID         uint `gorm:"uniqueIndex:idx_task_assignee;index"`
+	Role           AssigneeRole
+	AcknowledgedAt time.Time
+	CreatedAt      time.Time
+}
+
+func (a *TaskAssignee) Acknowledged() bool {
+	return !a.AcknowledgedAt.IsZero()
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChecklistItem пункт чек-листа внутри задачи. Отмечать пункты может исполнитель без права task:update
+type ChecklistItem struct {
+	gorm.Model
+	TaskID   uint `gorm:"index"`
+	Title    string
+	Done     bool
+	Position int
+	DoneByID uint
+	DoneAt   time.Time
+}
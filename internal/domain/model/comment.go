@@ -10,4 +10,6 @@ type Comment struct {
 	TaskID  uint
 	Task    Task    `gorm:"foreignKey:TaskID"`
 	Files   []*File `gorm:"type:jsonb;serializer:json"`
+	// Mentions загружаются вместе с комментарием и не хранятся в его строке
+	Mentions []*Mention `gorm:"-"`
 }
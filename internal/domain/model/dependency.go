@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// TaskDependency задача TaskID не может перейти в работу, пока BlockerID не выполнена
+type TaskDependency struct {
+	ID        uint `gorm:"primarykey"`
+	TaskID    uint `gorm:"uniqueIndex:idx_task_dependency"`
+	BlockerID uint `gorm:"uniqueIndex:idx_task_dependency;index"`
+	CreatedAt time.Time
+}
+
+// TaskLink краткое описание связанной задачи для ответов
+type TaskLink struct {
+	ID         uint
+	Title      string
+	Status     Status
+	ExecutorID uint
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+)
+
+// EmailMode способ доставки уведомлений одного типа на почту
+type EmailMode string
+
+const (
+	EmailInstant EmailMode = "instant"
+	EmailDigest  EmailMode = "digest"
+	EmailOff     EmailMode = "off"
+)
+
+var EmailModes = []EmailMode{EmailInstant, EmailDigest, EmailOff}
+
+// DefaultEmailModes то, что касается пользователя лично, приходит сразу, остальное попадает в сводку
+var DefaultEmailModes = map[NotificationType]EmailMode{
+	TaskAssignedNotification:     EmailInstant,
+	StatusChangedNotification:    EmailDigest,
+	CommentAddedNotification:     EmailDigest,
+	MentionedNotification:        EmailInstant,
+	DeadlineReminderNotification: EmailInstant,
+	TaskOverdueNotification:      EmailInstant,
+	BlockerCompletedNotification: EmailDigest,
+}
+
+// EmailSettings почтовые настройки пользователя. Типы, которых нет в Modes, берутся из DefaultEmailModes,
+// пустой Locale означает язык по умолчанию из конфига
+type EmailSettings struct {
+	UserID    uint `gorm:"primarykey;autoIncrement:false"`
+	Locale    string
+	Modes     map[NotificationType]EmailMode `gorm:"type:jsonb;serializer:json"`
+	UpdatedAt time.Time
+}
+
+func (s *EmailSettings) Mode(notificationType NotificationType) EmailMode {
+	if mode, ok := s.Modes[notificationType]; ok {
+		return mode
+	}
+	if mode, ok := DefaultEmailModes[notificationType]; ok {
+		return mode
+	}
+	return EmailOff
+}
+
+// EmailDigestItem уведомление, которое ждет ежедневной сводки
+type EmailDigestItem struct {
+	ID             uint `gorm:"primarykey"`
+	CreatedAt      time.Time
+	UserID         uint `gorm:"index"`
+	NotificationID uint `gorm:"uniqueIndex"`
+	Notification   *Notification
+}
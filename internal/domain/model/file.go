@@ -1,6 +1,8 @@
 package model
 
 import (
+	"mime"
+	"strings"
 	"time"
 )
 
@@ -12,6 +14,71 @@ type File struct {
 	MimeType   string    `json:"mimeType"`
 	UploaderID uint      `json:"uploaderId"`
 	UploadedAt time.Time `json:"uploadedAt"`
+
+	Variants map[string]*FileVariant `json:"variants,omitempty"`
+
+	ScanStatus    ScanStatus `json:"scanStatus,omitempty"`
+	ScanSignature string     `json:"scanSignature,omitempty"`
+}
+
+type ScanStatus string
+
+const (
+	ScanStatusClean       ScanStatus = "clean"
+	ScanStatusQuarantined ScanStatus = "quarantined"
+	// ScanStatusSkipped антивирус выключен, файл не проверялся
+	ScanStatusSkipped ScanStatus = "skipped"
+)
+
+// IsQuarantined файл заражен и не отдается пользователям
+func (f *File) IsQuarantined() bool {
+	return f.ScanStatus == ScanStatusQuarantined
+}
+
+// ContentDisposition изображения открываются в браузере, остальные файлы скачиваются.
+// Одинаково для отдачи через приложение и по временной ссылке хранилища
+func (f *File) ContentDisposition() string {
+	disposition := "attachment"
+	if strings.HasPrefix(f.MimeType, "image/") {
+		disposition = "inline"
+	}
+	return mime.FormatMediaType(disposition, map[string]string{"filename": f.Name})
+}
+
+// FileVariant уменьшенная копия изображения
+type FileVariant struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+// Variant возвращает файл с путем и типом выбранного варианта. Пустое имя или original это сам файл
+func (f *File) Variant(name string) (*File, bool) {
+	if name == "" || name == OriginalVariant {
+		return f, true
+	}
+	variant, ok := f.Variants[name]
+	if !ok {
+		return nil, false
+	}
+	result := *f
+	result.Path = variant.Path
+	result.Size = variant.Size
+	result.MimeType = variant.MimeType
+	return &result, true
+}
+
+// AllPaths пути самого файла и всех его вариантов в хранилище
+func (f *File) AllPaths() []string {
+	paths := []string{f.Path}
+	for _, variant := range f.Variants {
+		if variant.Path != f.Path {
+			paths = append(paths, variant.Path)
+		}
+	}
+	return paths
 }
 
 func NewFile(id, name, path string, size int64, mimeType string, uploaderID uint) *File {
@@ -25,3 +92,51 @@ func NewFile(id, name, path string, size int64, mimeType string, uploaderID uint
 		UploadedAt: time.Now(),
 	}
 }
+
+const OriginalVariant = "original"
+
+type FileOwnerType string
+
+const (
+	FileOwnerTask     FileOwnerType = "task"
+	FileOwnerComment  FileOwnerType = "comment"
+	FileOwnerCompany  FileOwnerType = "company"
+	FileOwnerUser     FileOwnerType = "user"
+	FileOwnerTemplate FileOwnerType = "template"
+)
+
+// FileRef файл и сущность, которая на него ссылается
+type FileRef struct {
+	File      *File
+	OwnerType FileOwnerType
+	OwnerID   uint
+	CompanyID uint // компания задачи, для задач, комментариев и шаблонов
+}
+
+// IsPublic аватары компаний и пользователей доступны без проверки прав
+func (r *FileRef) IsPublic() bool {
+	return r.OwnerType == FileOwnerCompany || r.OwnerType == FileOwnerUser
+}
+
+// StorageUsage место, занятое файлами компании, с разбивкой по типу сущности и загрузившим пользователям
+type StorageUsage struct {
+	CompanyID    uint
+	TotalBytes   int64
+	QuotaBytes   int64 // 0 без ограничения
+	ByEntityType map[FileOwnerType]int64
+	ByUploader   map[uint]int64
+}
+
+func NewStorageUsage(companyID uint) *StorageUsage {
+	return &StorageUsage{
+		CompanyID:    companyID,
+		ByEntityType: make(map[FileOwnerType]int64),
+		ByUploader:   make(map[uint]int64),
+	}
+}
+
+func (u *StorageUsage) Add(entityType FileOwnerType, uploaderID uint, bytes int64) {
+	u.TotalBytes += bytes
+	u.ByEntityType[entityType] += bytes
+	u.ByUploader[uploaderID] += bytes
+}
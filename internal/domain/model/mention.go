@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// MentionSource где находится упоминание: в описании задачи или в комментарии
+type MentionSource string
+
+const (
+	MentionInTask    MentionSource = "task"
+	MentionInComment MentionSource = "comment"
+)
+
+// Mention упоминание участника компании в тексте. Offset и Length в UTF-16 единицах, как индексы
+// строк в JavaScript, и включают @
+type Mention struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	SourceType MentionSource `gorm:"size:16;index:idx_mention_source,priority:1"`
+	SourceID   uint          `gorm:"index:idx_mention_source,priority:2"`
+	TaskID     uint          `gorm:"index"`
+	UserID     uint          `gorm:"index"`
+	User       *User         `gorm:"foreignKey:UserID"`
+	Offset     int
+	Length     int
+}
+
+// MentionedUserIDs упомянутые пользователи без повторов в порядке первого упоминания
+func MentionedUserIDs(mentions []*Mention) []uint {
+	ids := make([]uint, 0, len(mentions))
+	seen := make(map[uint]struct{}, len(mentions))
+	for _, mention := range mentions {
+		if _, ok := seen[mention.UserID]; ok {
+			continue
+		}
+		seen[mention.UserID] = struct{}{}
+		ids = append(ids, mention.UserID)
+	}
+	return ids
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+type NotificationType string
+
+const (
+	TaskAssignedNotification     NotificationType = "task_assigned"
+	StatusChangedNotification    NotificationType = "status_changed"
+	CommentAddedNotification     NotificationType = "comment_added"
+	MentionedNotification        NotificationType = "mentioned"
+	DeadlineReminderNotification NotificationType = "deadline_reminder"
+	TaskOverdueNotification      NotificationType = "task_overdue"
+	BlockerCompletedNotification NotificationType = "blocker_completed"
+)
+
+// Notification уведомление пользователя в приложении. Payload подробности события для клиента,
+// ActorID 0 у уведомлений от планировщиков
+type Notification struct {
+	ID          uint      `gorm:"primarykey"`
+	CreatedAt   time.Time `gorm:"index:idx_notification_recipient,priority:3"`
+	RecipientID uint      `gorm:"index:idx_notification_recipient,priority:1"`
+	Read        bool      `gorm:"index:idx_notification_recipient,priority:2"`
+	ReadAt      time.Time
+	Type        NotificationType `gorm:"size:64"`
+	CompanyID   uint
+	TaskID      uint
+	ActorID     uint
+	Payload     map[string]any `gorm:"type:jsonb;serializer:json"`
+}
@@ -215,6 +215,15 @@ var PermissionsRegister = map[Permission]PermissionInfo{
 	},
 }
 
+// PermissionNames преобразует список прав в строки
+func PermissionNames(permissions []Permission) []string {
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		result = append(result, string(p))
+	}
+	return result
+}
+
 // GetAllPermissions получение всех прав в отсортированном ввиде по группам
 func GetAllPermissions() []PermissionInfo {
 	result := make([]PermissionInfo, 0, len(PermissionsRegister))
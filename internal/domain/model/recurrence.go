@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Recurrence шаблон повторяющейся задачи. Планировщик заранее создает по нему задачи
+// с RecurrenceID и OccurrenceAt, сдвигая начало и срок
+type Recurrence struct {
+	gorm.Model
+	CompanyID   uint `gorm:"index"`
+	CreatorID   uint
+	Title       string
+	Description string
+	Priority    uint `gorm:"default:1"`
+	ExecutorID  uint
+	ExecutorIDs []uint `gorm:"type:jsonb;serializer:json"`
+	WatcherIDs  []uint `gorm:"type:jsonb;serializer:json"`
+
+	// RRule правило повторения RFC 5545 без префикса RRULE:, StartAt его DTSTART
+	RRule    string
+	Timezone string
+	StartAt  time.Time
+	// Duration срок выполнения каждой задачи от ее начала
+	Duration time.Duration
+
+	// GeneratedUntil повторения не позже этого момента уже созданы
+	GeneratedUntil time.Time
+	Generated      int
+	Active         bool `gorm:"index"`
+	// LastError почему планировщик остановил шаблон
+	LastError string
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+// TaskReminder отметка об отправленном напоминании о сроке. Ahead за сколько до срока, 0 для просрочки.
+// Срок входит в ключ, поэтому после переноса срока напоминания приходят снова
+type TaskReminder struct {
+	ID         uint          `gorm:"primarykey"`
+	TaskID     uint          `gorm:"uniqueIndex:idx_task_reminder"`
+	Ahead      time.Duration `gorm:"uniqueIndex:idx_task_reminder"`
+	DeadlineAt time.Time     `gorm:"uniqueIndex:idx_task_reminder"`
+	SentAt     time.Time
+}
+
+// Overdue напоминание о просроченной задаче
+func (r *TaskReminder) Overdue() bool {
+	return r.Ahead == 0
+}
@@ -0,0 +1,27 @@
+package model
+
+// Маркеры подсветки совпадений в Snippet. Символы редкие, поэтому транспорт может
+// безопасно экранировать текст и заменить их на разметку
+const (
+	HighlightStart = "⟦"
+	HighlightStop  = "⟧"
+)
+
+type SearchEntityType string
+
+const (
+	SearchEntityTask    SearchEntityType = "task"
+	SearchEntityComment SearchEntityType = "comment"
+)
+
+// SearchHit найденная задача или комментарий. Для комментария Title это название его задачи
+type SearchHit struct {
+	EntityType SearchEntityType
+	ID         uint
+	TaskID     uint
+	CompanyID  uint
+	Title      string
+	Snippet    string
+	Status     Status
+	Rank       float64
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,6 +17,38 @@ const (
 	ImmediateStatus  Status = "Срочная"
 )
 
+// MaxTaskDepth глубина дерева подзадач: задача, подзадача и подзадача подзадачи
+const MaxTaskDepth = 3
+
+var Statuses = []Status{CreatedStatus, InWorkStatus, InProgressStatus, CompletedStatus, ImmediateStatus}
+
+// statusTransitions допустимые переходы между статусами. Выполненная задача возвращается только в доработку
+var statusTransitions = map[Status][]Status{
+	CreatedStatus:    {InWorkStatus, ImmediateStatus},
+	ImmediateStatus:  {InWorkStatus},
+	InWorkStatus:     {CompletedStatus, ImmediateStatus},
+	InProgressStatus: {InWorkStatus, CompletedStatus, ImmediateStatus},
+	CompletedStatus:  {InProgressStatus},
+}
+
+// CanTransitionTo переход в тот же статус разрешен, это перестановка внутри колонки
+func (s Status) CanTransitionTo(next Status) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions статусы, в которые можно перевести задачу из s
+func (s Status) AllowedTransitions() []Status {
+	return statusTransitions[s]
+}
+
 type Task struct {
 	gorm.Model
 	CreatorID   uint
@@ -24,13 +57,78 @@ type Task struct {
 	Executor    User `gorm:"foreignkey:ExecutorID"`
 	Title       string
 	Description string
-	Status      Status
-	Priority    uint `gorm:"default:1"`
-	CompanyID   uint
+	Status      Status  `gorm:"index:idx_task_board,priority:2"`
+	Priority    uint    `gorm:"default:1"`
+	CompanyID   uint    `gorm:"index:idx_task_board,priority:1"`
 	Company     Company `gorm:"foreignkey:CompanyID"`
 	StartAt     time.Time
 	DeadlineAt  time.Time
 	CompletedAt time.Time
+	// BoardRank позиция в колонке доски, сравнивается побайтно: ORDER BY board_rank COLLATE "C"
+	BoardRank string `gorm:"size:255"`
+	ParentID  *uint  `gorm:"index"`
+	// RecurrenceID и OccurrenceAt у задач, созданных по шаблону повторения. Пара уникальна,
+	// поэтому одно повторение не создается дважды
+	RecurrenceID *uint      `gorm:"uniqueIndex:idx_task_occurrence"`
+	OccurrenceAt *time.Time `gorm:"uniqueIndex:idx_task_occurrence"`
+
+	Files     []*File          `gorm:"type:jsonb;serializer:json"`
+	Checklist []*ChecklistItem `gorm:"foreignKey:TaskID"`
+	// Assignees все исполнители и наблюдатели. ExecutorID остается основным исполнителем
+	Assignees []*TaskAssignee `gorm:"foreignKey:TaskID"`
+	// Subtasks считается по прямым подзадачам при чтении и не хранится
+	Subtasks SubtaskProgress `gorm:"-"`
+	// BlockedBy задачи, которые должны быть выполнены до начала работы, Blocks задачи, которые ждут эту
+	BlockedBy []TaskLink `gorm:"-"`
+	Blocks    []TaskLink `gorm:"-"`
+	// Mentions упоминания в описании, загружаются вместе с зависимостями
+	Mentions []*Mention `gorm:"-"`
+}
+
+type SubtaskProgress struct {
+	Total     int64
+	Completed int64
+}
+
+// Percent доля выполненных подзадач, 0 если подзадач нет
+func (p SubtaskProgress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return int(p.Completed * 100 / p.Total)
+}
+
+// ExecutorIDs основной исполнитель первым. У задач, созданных до появления участников, только ExecutorID
+func (t *Task) ExecutorIDs() []uint {
+	ids := []uint{t.ExecutorID}
+	for _, assignee := range t.Assignees {
+		if assignee.Role == ExecutorRole && assignee.UserID != t.ExecutorID {
+			ids = append(ids, assignee.UserID)
+		}
+	}
+	return ids
+}
+
+func (t *Task) WatcherIDs() []uint {
+	var ids []uint
+	for _, assignee := range t.Assignees {
+		if assignee.Role == WatcherRole {
+			ids = append(ids, assignee.UserID)
+		}
+	}
+	return ids
+}
+
+func (t *Task) IsExecutor(userID uint) bool {
+	return slices.Contains(t.ExecutorIDs(), userID)
+}
 
-	Files []*File `gorm:"type:jsonb;serializer:json"`
+// Assignee участие пользователя в задаче, nil если он не участник
+func (t *Task) Assignee(userID uint) *TaskAssignee {
+	for _, assignee := range t.Assignees {
+		if assignee.UserID == userID {
+			return assignee
+		}
+	}
+	return nil
 }
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// TelegramLink привязка пользователя к личному чату с ботом. Один Telegram аккаунт привязан к одному пользователю
+type TelegramLink struct {
+	UserID         uint  `gorm:"primarykey;autoIncrement:false"`
+	ChatID         int64 `gorm:"uniqueIndex"`
+	TelegramUserID int64 `gorm:"uniqueIndex"`
+	Username       string
+	CreatedAt      time.Time
+}
+
+// TelegramLinkCode одноразовый код, который пользователь отправляет боту, чтобы привязать аккаунт
+type TelegramLinkCode struct {
+	Code      string `gorm:"primarykey;size:16"`
+	UserID    uint   `gorm:"index"`
+	ExpiresAt time.Time
+}
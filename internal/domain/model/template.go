@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskTemplate заготовка задачи компании. В Title и Description допускаются плейсхолдеры {{name}},
+// значения подставляются при создании задачи
+type TaskTemplate struct {
+	gorm.Model
+	CompanyID   uint `gorm:"index"`
+	CreatorID   uint
+	Name        string
+	Title       string
+	Description string
+	Priority    uint `gorm:"default:1"`
+	// ExecutorID исполнитель по умолчанию, 0 если его выбирают при создании задачи
+	ExecutorID uint
+	Checklist  []string `gorm:"type:jsonb;serializer:json"`
+	// Files копируются в каждую созданную задачу
+	Files []*File `gorm:"type:jsonb;serializer:json"`
+	// DeadlineAfter срок задачи от ее начала
+	DeadlineAfter time.Duration
+}
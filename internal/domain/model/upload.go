@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+type UploadStatus string
+
+const (
+	UploadStatusPending   UploadStatus = "pending"
+	UploadStatusCompleted UploadStatus = "completed"
+	UploadStatusAttached  UploadStatus = "attached"
+	UploadStatusRejected  UploadStatus = "rejected"
+)
+
+// Upload возобновляемая загрузка файла частями. После получения всех байт
+// части собираются в File, который потом прикрепляется к задаче или комментарию по ID
+type Upload struct {
+	ID         string `gorm:"primaryKey;type:uuid"`
+	UploaderID uint   `gorm:"index"`
+	EntityType string
+	FileName   string
+	MimeType   string
+	Length     int64
+	Offset     int64
+	Parts      []*UploadPart `gorm:"type:jsonb;serializer:json"`
+	Status     UploadStatus  `gorm:"index"`
+	File       *File         `gorm:"type:jsonb;serializer:json"`
+	ExpiresAt  time.Time
+	// AssemblingUntil до этого времени части собирает один запрос, nil если сборка не идет
+	AssemblingUntil *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// UploadPart одна принятая часть загрузки, хранится отдельным объектом до сборки
+type UploadPart struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+}
+
+// IsExpired незавершенная загрузка или собранный, но не прикрепленный файл больше не хранятся
+func (u *Upload) IsExpired() bool {
+	if u.Status != UploadStatusPending && u.Status != UploadStatusCompleted {
+		return false
+	}
+	return time.Now().After(u.ExpiresAt)
+}
@@ -71,6 +71,27 @@ func (u *User) CanAny(permissions ...rbac.Permission) bool {
 	return false
 }
 
+// MissingPermissions возвращает права ролей, которых нет у пользователя.
+// Используется для защиты от выдачи прав сверх собственных
+func (u *User) MissingPermissions(roles ...rbac.Role) []rbac.Permission {
+	owned := u.GetPermissions()
+	seen := make(map[rbac.Permission]struct{})
+	result := make([]rbac.Permission, 0)
+
+	for _, role := range roles {
+		for _, p := range role.Permissions {
+			if _, exists := seen[p]; exists {
+				continue
+			}
+			seen[p] = struct{}{}
+			if !slices.Contains(owned, p) {
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
+
 func (u *User) HasRole(roleName string) bool {
 	for _, role := range u.Roles {
 		if role.Name == roleName && role.IsActive {
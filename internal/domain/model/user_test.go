@@ -0,0 +1,87 @@
+package model
+
+import (
+	"reflect"
+	"rttask/internal/domain/model/rbac"
+	"slices"
+	"testing"
+)
+
+func TestMissingPermissions(t *testing.T) {
+	var all []rbac.Permission
+	for permission := range rbac.PermissionsRegister {
+		all = append(all, permission)
+	}
+	slices.Sort(all)
+	member := rbac.Role{Permissions: []rbac.Permission{rbac.TaskView, rbac.TaskCreate, rbac.CommentView}, IsActive: true}
+	admin := rbac.Role{Name: "admin", Permissions: all, IsActive: true}
+	// права admin сверх member: RoleCreate из первой роли, затем остальные в порядке admin
+	beyondMember := []rbac.Permission{rbac.RoleCreate}
+	for _, permission := range all {
+		if !member.HasPermission(permission) && permission != rbac.RoleCreate {
+			beyondMember = append(beyondMember, permission)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		userRoles []rbac.Role
+		roles     []rbac.Role
+		want      []rbac.Permission
+	}{
+		{
+			name:      "subset",
+			userRoles: []rbac.Role{member},
+			roles:     []rbac.Role{{Permissions: []rbac.Permission{rbac.TaskView, rbac.CommentView}}},
+			want:      []rbac.Permission{},
+		},
+		{
+			name:      "superset",
+			userRoles: []rbac.Role{member},
+			roles:     []rbac.Role{{Permissions: []rbac.Permission{rbac.TaskView, rbac.RoleAssign, rbac.TaskCreate, rbac.UserDelete}}},
+			want:      []rbac.Permission{rbac.RoleAssign, rbac.UserDelete},
+		},
+		{
+			name:      "repeated across roles",
+			userRoles: []rbac.Role{member},
+			roles: []rbac.Role{
+				{Permissions: []rbac.Permission{rbac.RoleAssign, rbac.TaskView}},
+				{Permissions: []rbac.Permission{rbac.RoleAssign, rbac.CompanyDelete}},
+			},
+			want: []rbac.Permission{rbac.RoleAssign, rbac.CompanyDelete},
+		},
+		{
+			name:      "admin grants admin",
+			userRoles: []rbac.Role{admin},
+			roles:     []rbac.Role{admin, member},
+			want:      []rbac.Permission{},
+		},
+		{
+			name:      "member grants admin",
+			userRoles: []rbac.Role{member},
+			roles:     []rbac.Role{{Permissions: []rbac.Permission{rbac.TaskView, rbac.RoleCreate}}, admin},
+			want:      beyondMember,
+		},
+		{
+			name:      "empty role",
+			userRoles: nil,
+			roles:     []rbac.Role{{}},
+			want:      []rbac.Permission{},
+		},
+		{
+			name:      "inactive role does not count",
+			userRoles: []rbac.Role{{Permissions: []rbac.Permission{rbac.RoleAssign}}},
+			roles:     []rbac.Role{{Permissions: []rbac.Permission{rbac.RoleAssign}}},
+			want:      []rbac.Permission{rbac.RoleAssign},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &User{Roles: tt.userRoles}
+			got := user.MissingPermissions(tt.roles...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingPermissions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
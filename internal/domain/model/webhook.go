@@ -0,0 +1,54 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook подписка компании на события. Тело каждой доставки подписывается HMAC-SHA256 с Secret
+type Webhook struct {
+	gorm.Model
+	CompanyID uint `gorm:"index"`
+	CreatorID uint
+	URL       string
+	Events    []string `gorm:"type:jsonb;serializer:json"`
+	Secret    string
+	Active    bool `gorm:"default:true"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryDead попытки кончились, доставку можно только повторить вручную
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// WebhookDelivery одно событие для одного вебхука. EventID общий у доставок одного события разным вебхукам
+// и не меняется при повторах, по нему получатель отсекает дубли. Payload хранится уже готовым телом запроса,
+// чтобы повторные попытки отправляли байт в байт то же самое
+type WebhookDelivery struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	WebhookID     uint            `gorm:"index"`
+	EventID       string          `gorm:"size:36;uniqueIndex"`
+	Event         string          `gorm:"size:64"`
+	Payload       json.RawMessage `gorm:"type:jsonb"`
+	Status        DeliveryStatus  `gorm:"size:16;index:idx_webhook_delivery_due,priority:1"`
+	NextAttemptAt time.Time       `gorm:"index:idx_webhook_delivery_due,priority:2"`
+	AttemptCount  int
+	DeliveredAt   *time.Time
+	// Attempts журнал попыток, последняя в конце
+	Attempts []DeliveryAttempt `gorm:"type:jsonb;serializer:json"`
+}
+
+type DeliveryAttempt struct {
+	At         time.Time     `json:"at"`
+	StatusCode int           `json:"statusCode,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type AssigneeRepository interface {
+	// Replace заменяет всех участников задачи переданным списком
+	Replace(ctx context.Context, taskID uint, assignees []*model.TaskAssignee) error
+	// SetAcknowledged отмечает (или снимает нулевым временем) подтверждение исполнителя
+	SetAcknowledged(ctx context.Context, taskID uint, userID uint, at time.Time) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+)
+
+type ChecklistRepository interface {
+	Create(ctx context.Context, item *model.ChecklistItem) (*model.ChecklistItem, error)
+	// GetByID пункт ищется только внутри своей задачи
+	GetByID(ctx context.Context, taskID uint, id uint) (*model.ChecklistItem, error)
+	Update(ctx context.Context, item *model.ChecklistItem) error
+	Delete(ctx context.Context, item *model.ChecklistItem) error
+	// NextPosition позиция для нового пункта в конце чек-листа
+	NextPosition(ctx context.Context, taskID uint) (int, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/valueobject"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *model.Comment) (*model.Comment, error)
+	// GetByID комментарий вместе с задачей
+	GetByID(ctx context.Context, id uint) (*model.Comment, error)
+	// ListByTask комментарии задачи от старых к новым и их общее количество. В курсорном режиме
+	// на один больше Limit, см. valueobject.NewCursorPage
+	ListByTask(ctx context.Context, taskID uint, params valueobject.PaginationParams) ([]*model.Comment, int64, error)
+	UpdateContent(ctx context.Context, id uint, content string) error
+	Delete(ctx context.Context, comment *model.Comment) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+)
+
+type DependencyRepository interface {
+	// Create повторное добавление существующей зависимости ничего не меняет
+	Create(ctx context.Context, dependency *model.TaskDependency) error
+	Delete(ctx context.Context, taskID uint, blockerID uint) error
+	// LockGraph сериализует изменения графа зависимостей компании до конца транзакции
+	LockGraph(ctx context.Context, companyID uint) error
+	// IsBlockedBy true, если задача taskID прямо или через цепочку ждет blockerID
+	IsBlockedBy(ctx context.Context, taskID uint, blockerID uint) (bool, error)
+	// GetLinks блокирующие и ожидающие задачи для каждой задачи из taskIDs
+	GetLinks(ctx context.Context, taskIDs []uint) (blockedBy map[uint][]model.TaskLink, blocks map[uint][]model.TaskLink, err error)
+	// GetOpenBlockers невыполненные задачи, которые блокируют taskID
+	GetOpenBlockers(ctx context.Context, taskID uint) ([]model.TaskLink, error)
+	// GetDependents задачи, которые ждут blockerID
+	GetDependents(ctx context.Context, blockerID uint) ([]*model.Task, error)
+}
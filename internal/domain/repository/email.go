@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+)
+
+type EmailRepository interface {
+	// GetSettings возвращает nil, если пользователь ничего не настраивал
+	GetSettings(ctx context.Context, userID uint) (*model.EmailSettings, error)
+	SaveSettings(ctx context.Context, settings *model.EmailSettings) error
+	AddDigestItem(ctx context.Context, item *model.EmailDigestItem) error
+	// GetDigestUserIDs пользователи, у которых накопилась сводка
+	GetDigestUserIDs(ctx context.Context) ([]uint, error)
+	// LockDigest блокирует сводку пользователя с уведомлениями, занятые другим экземпляром строки пропускаются
+	LockDigest(ctx context.Context, userID uint) ([]*model.EmailDigestItem, error)
+	DeleteDigestItems(ctx context.Context, ids []uint) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+)
+
+type FileRepository interface {
+	GetRefByID(ctx context.Context, fileID string) (*model.FileRef, error)
+	Detach(ctx context.Context, ref *model.FileRef) error
+	// GetCompanyUsage место, занятое файлами задач, комментариев, шаблонов и логотипом компании
+	GetCompanyUsage(ctx context.Context, companyID uint) (*model.StorageUsage, error)
+	// GetUserUsage место, занятое файлами, загруженными пользователем, и заявленный размер его незавершенных загрузок
+	GetUserUsage(ctx context.Context, userID uint) (int64, error)
+	// LockQuota сериализует проверки квот компании и пользователя до конца транзакции, нулевой id пропускается
+	LockQuota(ctx context.Context, companyID uint, userID uint) error
+	// GetReferencedPaths пути в хранилище всех файлов, на которые ссылаются живые сущности и загрузки
+	GetReferencedPaths(ctx context.Context) (map[string]struct{}, error)
+}
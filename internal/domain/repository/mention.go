@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+)
+
+type MentionRepository interface {
+	// Replace заменяет упоминания источника на mentions. Вызывается внутри транзакции
+	Replace(ctx context.Context, source model.MentionSource, sourceID uint, mentions []*model.Mention) error
+	// ListBySources упоминания с пользователями по источникам в порядке появления в тексте
+	ListBySources(ctx context.Context, source model.MentionSource, sourceIDs []uint) (map[uint][]*model.Mention, error)
+	DeleteBySource(ctx context.Context, source model.MentionSource, sourceID uint) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/valueobject"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notifications []*model.Notification) error
+	// List уведомления получателя, новые первыми
+	List(ctx context.Context, recipientID uint, unreadOnly bool, params valueobject.PaginationParams) ([]*model.Notification, int64, error)
+	CountUnread(ctx context.Context, recipientID uint) (int64, error)
+	// MarkRead отмечает прочитанными уведомления получателя, пустой ids отмечает все. Возвращает число отмеченных
+	MarkRead(ctx context.Context, recipientID uint, ids []uint) (int64, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type RecurrenceRepository interface {
+	Create(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error)
+	GetByID(ctx context.Context, id uint) (*model.Recurrence, error)
+	ListByCompany(ctx context.Context, companyID uint) ([]*model.Recurrence, error)
+	Update(ctx context.Context, recurrence *model.Recurrence) error
+	Delete(ctx context.Context, recurrence *model.Recurrence) error
+	// GetDueIDs активные шаблоны, у которых повторения созданы не до horizon
+	GetDueIDs(ctx context.Context, horizon time.Time) ([]uint, error)
+	// LockDue блокирует шаблон до конца транзакции. nil, если его уже обрабатывает другой экземпляр
+	// или он больше не требует обработки
+	LockDue(ctx context.Context, id uint, horizon time.Time) (*model.Recurrence, error)
+}
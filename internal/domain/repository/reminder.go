@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type ReminderRepository interface {
+	// GetDueTasks невыполненные задачи со сроком в (from, to], по которым еще нет напоминания ahead
+	GetDueTasks(ctx context.Context, ahead time.Duration, from time.Time, to time.Time, limit int) ([]*model.Task, error)
+	// Create false, если такое напоминание уже отправлено
+	Create(ctx context.Context, reminder *model.TaskReminder) (bool, error)
+}
@@ -0,0 +1,139 @@
+package repotest
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"slices"
+	"strconv"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// UnitOfWork выполняет fn в подделке транзакции. Подделки репозиториев регистрируют через AfterTx
+// снятие блокировок и фиксацию изменений, они выполняются в конце Do
+type UnitOfWork struct{}
+
+type tx struct {
+	mu    sync.Mutex
+	after []func(committed bool)
+}
+
+type txKey struct{}
+
+func (UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	t := &tx{}
+	err := fn(context.WithValue(ctx, txKey{}, t))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// в обратном порядке, как defer: изменения фиксируются раньше, чем снимаются взятые до них блокировки
+	for _, after := range slices.Backward(t.after) {
+		after(err == nil)
+	}
+	return err
+}
+
+// AfterTx fn выполнится в конце транзакции ctx, committed false при откате.
+// Вне транзакции fn выполняется сразу, как автокоммит
+func AfterTx(ctx context.Context, fn func(committed bool)) {
+	t, ok := ctx.Value(txKey{}).(*tx)
+	if !ok {
+		fn(true)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.after = append(t.after, fn)
+}
+
+// LockUntilEnd блокировка, которая держится до конца транзакции, как pg_advisory_xact_lock
+func LockUntilEnd(ctx context.Context, lock *sync.Mutex) {
+	lock.Lock()
+	AfterTx(ctx, func(bool) { lock.Unlock() })
+}
+
+// UserRepo пользователи по id и участники компаний
+type UserRepo struct {
+	repository.UserRepository
+	Users   map[uint]*model.User
+	Members map[uint][]uint // компания -> участники
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
+	user, ok := r.Users[id]
+	if !ok {
+		return nil, domainerrors.NewNotFoundError("user", strconv.FormatUint(uint64(id), 10))
+	}
+	return user, nil
+}
+
+func (r *UserRepo) GetUserByIDWithRoles(ctx context.Context, id uint) (*model.User, error) {
+	return r.GetUserByID(ctx, id)
+}
+
+func (r *UserRepo) IsUserInCompany(ctx context.Context, userID uint, companyID uint) (bool, error) {
+	return slices.Contains(r.Members[companyID], userID), nil
+}
+
+func (r *UserRepo) ShareCompany(ctx context.Context, userID uint, otherID uint) (bool, error) {
+	for _, members := range r.Members {
+		if slices.Contains(members, userID) && slices.Contains(members, otherID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *UserRepo) AssignRoles(ctx context.Context, user *model.User, roles []rbac.Role) error {
+	user.Roles = append(user.Roles, roles...)
+	return nil
+}
+
+// NewUser пользователь с одной активной ролью из permissions
+func NewUser(id uint, permissions ...rbac.Permission) *model.User {
+	return &model.User{
+		Model: gorm.Model{ID: id},
+		Roles: []rbac.Role{{Permissions: permissions, IsActive: true}},
+	}
+}
+
+// RoleRepo роли по id
+type RoleRepo struct {
+	repository.RoleRepository
+	Roles map[uint]rbac.Role
+}
+
+func (r *RoleRepo) GetByIDs(ctx context.Context, ids []uint) ([]rbac.Role, error) {
+	var roles []rbac.Role
+	for _, id := range ids {
+		if role, ok := r.Roles[id]; ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+// MentionRepo задачи и комментарии без упоминаний
+type MentionRepo struct {
+	repository.MentionRepository
+}
+
+func (r *MentionRepo) ListBySources(ctx context.Context, source model.MentionSource, sourceIDs []uint) (map[uint][]*model.Mention, error) {
+	return nil, nil
+}
+
+// DependencyRepo задачи без зависимостей
+type DependencyRepo struct {
+	repository.DependencyRepository
+}
+
+func (r *DependencyRepo) GetLinks(ctx context.Context, taskIDs []uint) (map[uint][]model.TaskLink, map[uint][]model.TaskLink, error) {
+	return nil, nil, nil
+}
+
+func (r *DependencyRepo) GetOpenBlockers(ctx context.Context, taskID uint) ([]model.TaskLink, error) {
+	return nil, nil
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/valueobject"
+)
+
+type SearchRepository interface {
+	// SearchTasks найденные задачи по убыванию релевантности и общее число совпадений
+	SearchTasks(ctx context.Context, params valueobject.SearchParams) ([]*model.SearchHit, int64, error)
+	SearchComments(ctx context.Context, params valueobject.SearchParams) ([]*model.SearchHit, int64, error)
+}
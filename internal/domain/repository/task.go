@@ -8,7 +8,33 @@ import (
 
 type TaskRepository interface {
 	Create(ctx context.Context, task *model.Task) (*model.Task, error)
-	GetUserTasks(ctx context.Context, params valueobject.PaginationParams, userID uint) ([]*model.Task, error)
-	// GetAllForCompany()
-	// GetByID()
+	// List задачи по фильтру и общее количество подходящих задач без учета пагинации.
+	// В курсорном режиме возвращает до Limit+1 задач в порядке отображения
+	List(ctx context.Context, filter valueobject.TaskFilter, params valueobject.PaginationParams) ([]*model.Task, int64, error)
+	GetByID(ctx context.Context, id uint) (*model.Task, error)
+	// GetByIDForUpdate блокирует строку задачи до конца транзакции
+	GetByIDForUpdate(ctx context.Context, id uint) (*model.Task, error)
+	// UpdateExecutor меняет основного исполнителя
+	UpdateExecutor(ctx context.Context, id uint, executorID uint) error
+	// UpdateBoardPosition сохраняет статус, ранг и время завершения задачи
+	UpdateBoardPosition(ctx context.Context, task *model.Task) error
+
+	// GetBoardColumn первые limit задач колонки доски в порядке рангов и общее количество задач в колонке
+	GetBoardColumn(ctx context.Context, companyID uint, status model.Status, limit int) ([]*model.Task, int64, error)
+	// GetLastRank наибольший ранг в колонке без учета задачи excludeID, пустая строка для пустой колонки
+	GetLastRank(ctx context.Context, companyID uint, status model.Status, excludeID uint) (string, error)
+	// CountBetween количество задач колонки строго между after и before. nil означает край колонки
+	CountBetween(ctx context.Context, companyID uint, status model.Status, excludeID uint, after, before *model.Task) (int64, error)
+	// GetSubtaskProgress количество прямых подзадач и выполненных из них для каждой задачи из taskIDs
+	GetSubtaskProgress(ctx context.Context, taskIDs []uint) (map[uint]model.SubtaskProgress, error)
+	// GetAncestorIDs цепочка родителей задачи от ближайшего к корню
+	GetAncestorIDs(ctx context.Context, id uint) ([]uint, error)
+	// GetSubtreeHeight сколько уровней подзадач под задачей, 0 для задачи без подзадач
+	GetSubtreeHeight(ctx context.Context, id uint) (int, error)
+	UpdateParent(ctx context.Context, id uint, parentID *uint) error
+	// LockTree сериализует изменения дерева подзадач компании до конца транзакции
+	LockTree(ctx context.Context, companyID uint) error
+
+	// RebalanceColumn заново раздает задачам колонки равномерные ранги, сохраняя порядок
+	RebalanceColumn(ctx context.Context, companyID uint, status model.Status) error
 }
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type TelegramRepository interface {
+	// ReplaceCode выдает пользователю новый код, прежние коды перестают действовать. Вызывается внутри транзакции
+	ReplaceCode(ctx context.Context, code *model.TelegramLinkCode) error
+	// ConsumeCode удаляет действующий код и возвращает его, nil если кода нет или он истек
+	ConsumeCode(ctx context.Context, code string, now time.Time) (*model.TelegramLinkCode, error)
+	// SaveLink заменяет привязку пользователя и отвязывает Telegram аккаунт от прежнего пользователя.
+	// Вызывается внутри транзакции
+	SaveLink(ctx context.Context, link *model.TelegramLink) error
+	// GetLinkByUser возвращает nil, если пользователь не привязан
+	GetLinkByUser(ctx context.Context, userID uint) (*model.TelegramLink, error)
+	// GetLinkByTelegramUser возвращает nil, если аккаунт не привязан
+	GetLinkByTelegramUser(ctx context.Context, telegramUserID int64) (*model.TelegramLink, error)
+	DeleteLink(ctx context.Context, userID uint) (bool, error)
+}
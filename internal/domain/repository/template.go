@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+)
+
+type TemplateRepository interface {
+	Create(ctx context.Context, template *model.TaskTemplate) (*model.TaskTemplate, error)
+	GetByID(ctx context.Context, id uint) (*model.TaskTemplate, error)
+	ListByCompany(ctx context.Context, companyID uint) ([]*model.TaskTemplate, error)
+	Update(ctx context.Context, template *model.TaskTemplate) error
+	Delete(ctx context.Context, template *model.TaskTemplate) error
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "context"
+
+// UnitOfWork выполняет fn в одной транзакции.
+// Репозитории, вызванные с ctx внутри fn, работают в этой транзакции
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type UploadRepository interface {
+	Create(ctx context.Context, upload *model.Upload) (*model.Upload, error)
+	GetByID(ctx context.Context, id string) (*model.Upload, error)
+	// AppendPart добавляет часть, только если смещение загрузки не изменилось с момента чтения
+	AppendPart(ctx context.Context, upload *model.Upload, part *model.UploadPart) (bool, error)
+	// ClaimAssembly занимает сборку принятой целиком загрузки до until. false, если загрузка уже не pending
+	// или ее собирает другой запрос
+	ClaimAssembly(ctx context.Context, id string, until time.Time) (bool, error)
+	// ReleaseAssembly освобождает сборку после ошибки, чтобы повтор мог начать ее сразу
+	ReleaseAssembly(ctx context.Context, id string) error
+	// Finish сохраняет итог сборки: статус, файл, части и срок хранения, только если загрузка еще pending
+	Finish(ctx context.Context, upload *model.Upload) (bool, error)
+	GetCompletedByIDs(ctx context.Context, fileIDs []string, uploaderID uint) ([]*model.Upload, error)
+	MarkAttached(ctx context.Context, ids []string) error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
 )
 
 type UserRepository interface {
@@ -11,4 +12,10 @@ type UserRepository interface {
 	GetUserByIDWithRoles(ctx context.Context, id uint) (*model.User, error)
 	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
 	IsUserInCompany(ctx context.Context, userID uint, companyID uint) (bool, error)
+	// ShareCompany пользователи состоят хотя бы в одной общей компании
+	ShareCompany(ctx context.Context, userID uint, otherID uint) (bool, error)
+	// FindCompanyMembers участники компании, у которых e-mail или его часть до @ совпадает
+	// с одним из handles в нижнем регистре
+	FindCompanyMembers(ctx context.Context, companyID uint, handles []string) ([]*model.User, error)
+	AssignRoles(ctx context.Context, user *model.User, roles []rbac.Role) error
 }
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/valueobject"
+	"time"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error)
+	GetByID(ctx context.Context, id uint) (*model.Webhook, error)
+	ListByCompany(ctx context.Context, companyID uint) ([]*model.Webhook, error)
+	// ListSubscribed активные вебхуки компании, подписанные на событие
+	ListSubscribed(ctx context.Context, companyID uint, eventType string) ([]*model.Webhook, error)
+	Update(ctx context.Context, webhook *model.Webhook) error
+	Delete(ctx context.Context, webhook *model.Webhook) error
+
+	CreateDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error
+	GetDelivery(ctx context.Context, webhookID uint, deliveryID uint) (*model.WebhookDelivery, error)
+	// ListDeliveries доставки вебхука, новые первыми
+	ListDeliveries(ctx context.Context, webhookID uint, params valueobject.PaginationParams) ([]*model.WebhookDelivery, int64, error)
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	// GetDueDeliveryIDs ожидающие доставки, время попытки которых наступило
+	GetDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]uint, error)
+	// LockDueDelivery блокирует доставку до конца транзакции. nil, если ее уже забрал другой экземпляр
+	// или она больше не ждет отправки
+	LockDueDelivery(ctx context.Context, id uint, now time.Time) (*model.WebhookDelivery, error)
+}
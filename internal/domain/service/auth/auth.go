@@ -30,6 +30,7 @@ func NewTokens(accessToken, refreshToken string) *Tokens {
 type AuthService struct {
 	userRepo        repository.UserRepository
 	inviteRepo      repository.InviteRepository
+	uow             repository.UnitOfWork
 	fileService     *file.FileService
 	passwordHasher  security.PasswordHasher
 	jwtManager      security.JWTManager
@@ -41,6 +42,7 @@ type AuthService struct {
 func NewAuthService(
 	userRepo repository.UserRepository,
 	inviteRepo repository.InviteRepository,
+	uow repository.UnitOfWork,
 	fileService *file.FileService,
 	passwordHasher security.PasswordHasher,
 	jwtManager security.JWTManager,
@@ -51,6 +53,7 @@ func NewAuthService(
 	return &AuthService{
 		userRepo:        userRepo,
 		inviteRepo:      inviteRepo,
+		uow:             uow,
 		fileService:     fileService,
 		passwordHasher:  passwordHasher,
 		jwtManager:      jwtManager,
@@ -136,12 +139,17 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput, fileInp
 		Avatar:         avatar,
 	}
 
-	createdUser, err := s.userRepo.CreateUser(ctx, newUser)
+	var createdUser *model.User
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		createdUser, err = s.userRepo.CreateUser(ctx, newUser)
+		return err
+	})
 	if err != nil {
 		s.logger.Error("failed to create user",
 			zap.String("email", input.Email.String()),
 			zap.Error(err),
 		)
+		s.fileService.DeleteFiles(ctx, avatar)
 		return nil, err
 	}
 
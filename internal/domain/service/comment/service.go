@@ -0,0 +1,283 @@
+package comment
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/service/file"
+	"rttask/internal/domain/service/mention"
+	"rttask/internal/domain/valueobject"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// MaxContentLength длина текста комментария в символах
+const MaxContentLength = 10000
+
+type CommentInput struct {
+	TaskID  uint
+	Content string
+	// FileIDs файлы, заранее загруженные через /uploads
+	FileIDs []string
+}
+
+type CommentService struct {
+	commentRepo    repository.CommentRepository
+	taskRepo       repository.TaskRepository
+	userRepo       repository.UserRepository
+	uow            repository.UnitOfWork
+	fileService    *file.FileService
+	uploadService  *file.UploadService
+	mentionService *mention.MentionService
+	events         event.Publisher
+	logger         *zap.Logger
+}
+
+func NewCommentService(commentRepo repository.CommentRepository, taskRepo repository.TaskRepository, userRepo repository.UserRepository, uow repository.UnitOfWork, fileService *file.FileService, uploadService *file.UploadService, mentionService *mention.MentionService, events event.Publisher, logger *zap.Logger) *CommentService {
+	return &CommentService{
+		commentRepo:    commentRepo,
+		taskRepo:       taskRepo,
+		userRepo:       userRepo,
+		uow:            uow,
+		fileService:    fileService,
+		uploadService:  uploadService,
+		mentionService: mentionService,
+		events:         events,
+		logger:         logger,
+	}
+}
+
+// CreateComment комментарий к задаче. Упомянутые через @ должны быть участниками компании задачи
+func (s *CommentService) CreateComment(ctx context.Context, input CommentInput, userID uint) (*model.Comment, error) {
+	content, err := validateContent(input.Content)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.taskForUser(ctx, input.TaskID, userID, rbac.CommentCreate)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := s.mentionService.Resolve(ctx, task.CompanyID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &model.Comment{
+		Content: content,
+		UserID:  userID,
+		TaskID:  task.ID,
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		attached, err := s.uploadService.AttachUploads(ctx, input.FileIDs, userID)
+		if err != nil {
+			return err
+		}
+		if len(attached) > 0 {
+			// квота пользователя учтена при загрузке, компании проверяется сейчас под блокировкой,
+			// чтобы параллельные прикрепления не превысили ее вместе
+			if err := s.fileService.LockQuota(ctx, task.CompanyID, 0); err != nil {
+				return err
+			}
+			if err := s.fileService.CheckQuota(ctx, task.CompanyID, 0, filesSize(attached)); err != nil {
+				return err
+			}
+		}
+		comment.Files = attached
+
+		if _, err := s.commentRepo.Create(ctx, comment); err != nil {
+			return err
+		}
+		comment.Mentions, err = s.mentionService.Save(ctx, model.MentionInComment, comment.ID, task.ID, mentions)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to create comment", zap.Error(err))
+		return nil, err
+	}
+	comment.Task = *task
+
+	s.events.Publish(ctx, event.Event{
+		Type:      event.CommentCreated,
+		CompanyID: task.CompanyID,
+		ActorID:   userID,
+		Payload:   event.CommentCreatedPayload{Task: task, Comment: comment},
+	})
+	s.publishMentioned(ctx, task, comment, model.MentionedUserIDs(comment.Mentions), userID)
+	return comment, nil
+}
+
+// ListComments комментарии задачи от старых к новым
+func (s *CommentService) ListComments(ctx context.Context, taskID uint, params valueobject.PaginationParams, userID uint) (*valueobject.Page[*model.Comment], error) {
+	task, err := s.taskForUser(ctx, taskID, userID, rbac.CommentView)
+	if err != nil {
+		return nil, err
+	}
+	comments, total, err := s.commentRepo.ListByTask(ctx, task.ID, params)
+	if err != nil {
+		s.logger.Error("failed to list comments", zap.Error(err))
+		return nil, err
+	}
+
+	page := &valueobject.Page[*model.Comment]{Items: comments, Total: total}
+	if params.CursorMode {
+		page = valueobject.NewCursorPage(comments, total, params, func(comment *model.Comment) (time.Time, uint) {
+			return comment.CreatedAt, comment.ID
+		})
+	}
+	if err := s.mentionService.FillComments(ctx, page.Items...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// UpdateComment меняет текст своего комментария. Уведомление получают только впервые упомянутые
+func (s *CommentService) UpdateComment(ctx context.Context, id uint, content string, userID uint) (*model.Comment, error) {
+	content, err := validateContent(content)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := s.commentForAuthor(ctx, id, userID, rbac.CommentUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mentionService.FillComments(ctx, comment); err != nil {
+		return nil, err
+	}
+	previous := model.MentionedUserIDs(comment.Mentions)
+
+	mentions, err := s.mentionService.Resolve(ctx, comment.Task.CompanyID, content)
+	if err != nil {
+		return nil, err
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.commentRepo.UpdateContent(ctx, comment.ID, content); err != nil {
+			return err
+		}
+		comment.Mentions, err = s.mentionService.Save(ctx, model.MentionInComment, comment.ID, comment.TaskID, mentions)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to update comment", zap.Error(err))
+		return nil, err
+	}
+	comment.Content = content
+
+	var added []uint
+	for _, mentionedID := range model.MentionedUserIDs(comment.Mentions) {
+		if !slices.Contains(previous, mentionedID) {
+			added = append(added, mentionedID)
+		}
+	}
+	if len(added) > 0 {
+		// участники задачи нужны подписчикам событий, у задачи из комментария они не загружены
+		task, err := s.taskRepo.GetByID(ctx, comment.TaskID)
+		if err != nil {
+			s.logger.Error("failed to get comment task", zap.Error(err))
+			return comment, nil
+		}
+		s.publishMentioned(ctx, task, comment, added, userID)
+	}
+	return comment, nil
+}
+
+// DeleteComment удаляет свой комментарий вместе с упоминаниями
+func (s *CommentService) DeleteComment(ctx context.Context, id uint, userID uint) error {
+	comment, err := s.commentForAuthor(ctx, id, userID, rbac.CommentDelete)
+	if err != nil {
+		return err
+	}
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.mentionService.Delete(ctx, model.MentionInComment, comment.ID); err != nil {
+			return err
+		}
+		return s.commentRepo.Delete(ctx, comment)
+	})
+}
+
+// taskForUser задача, комментарии которой пользователь может видеть или писать: он состоит в ее компании и имеет permission
+func (s *CommentService) taskForUser(ctx context.Context, taskID uint, userID uint, permission rbac.Permission) (*model.Task, error) {
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Can(permission) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMember(ctx, userID, task.CompanyID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// commentForAuthor менять и удалять комментарий может только его автор, пока состоит в компании
+func (s *CommentService) commentForAuthor(ctx context.Context, id uint, userID uint, permission rbac.Permission) (*model.Comment, error) {
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMember(ctx, userID, comment.Task.CompanyID); err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID || !user.Can(permission) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+	return comment, nil
+}
+
+func (s *CommentService) checkMember(ctx context.Context, userID uint, companyID uint) error {
+	inCompany, err := s.userRepo.IsUserInCompany(ctx, userID, companyID)
+	if err != nil {
+		return err
+	}
+	if !inCompany {
+		return domainerrors.NewForbiddenError("user not in company").WithMeta("companyId", companyID)
+	}
+	return nil
+}
+
+func (s *CommentService) publishMentioned(ctx context.Context, task *model.Task, comment *model.Comment, userIDs []uint, actorID uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	s.events.Publish(ctx, event.Event{
+		Type:      event.UserMentioned,
+		CompanyID: task.CompanyID,
+		ActorID:   actorID,
+		Payload:   event.UserMentionedPayload{Task: task, Comment: comment, UserIDs: userIDs},
+	})
+}
+
+func validateContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", domainerrors.NewValidationError("Comment is empty")
+	}
+	if utf8.RuneCountInString(content) > MaxContentLength {
+		return "", domainerrors.NewValidationError("Comment is too long").
+			WithMeta("maxLength", MaxContentLength)
+	}
+	return content, nil
+}
+
+func filesSize(files []*model.File) int64 {
+	var size int64
+	for _, f := range files {
+		size += f.Size
+	}
+	return size
+}
@@ -15,14 +15,16 @@ import (
 type CompanyService struct {
 	companyRepo repository.CompanyRepository
 	userRepo    repository.UserRepository
+	uow         repository.UnitOfWork
 	fileService *file.FileService
 	logger      *zap.Logger
 }
 
-func NewCompanyService(companyRepo repository.CompanyRepository, userRepo repository.UserRepository, fileService *file.FileService, logger *zap.Logger) *CompanyService {
+func NewCompanyService(companyRepo repository.CompanyRepository, userRepo repository.UserRepository, uow repository.UnitOfWork, fileService *file.FileService, logger *zap.Logger) *CompanyService {
 	return &CompanyService{
 		companyRepo: companyRepo,
 		userRepo:    userRepo,
+		uow:         uow,
 		fileService: fileService,
 		logger:      logger,
 	}
@@ -49,8 +51,13 @@ func (s *CompanyService) CreateCompany(ctx context.Context, input CompanyInput,
 		Avatar:      logo,
 	}
 
-	newCompany, err := s.companyRepo.Create(ctx, company)
+	var newCompany *model.Company
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		newCompany, err = s.companyRepo.Create(ctx, company)
+		return err
+	})
 	if err != nil {
+		s.fileService.DeleteFiles(ctx, logo)
 		return nil, err
 	}
 
@@ -67,6 +74,16 @@ func (s *CompanyService) GetAll(ctx context.Context, params valueobject.Paginati
 	return companies, count, nil
 }
 
+// GetStorageUsage место, занятое файлами компании, с разбивкой по типам сущностей
+func (s *CompanyService) GetStorageUsage(ctx context.Context, companyID uint, userID uint) (*model.StorageUsage, error) {
+	s.logger.Info("start CompanyService.GetStorageUsage")
+
+	if _, err := s.companyRepo.GetByID(ctx, companyID); err != nil {
+		return nil, err
+	}
+	return s.fileService.GetCompanyUsage(ctx, companyID, userID)
+}
+
 func (s *CompanyService) validateCompanyUnique(ctx context.Context, input CompanyInput) error {
 	existCompany, err := s.companyRepo.GetByName(ctx, input.Name)
 	if err != nil {
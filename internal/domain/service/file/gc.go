@@ -0,0 +1,129 @@
+package file
+
+import (
+	"context"
+	"rttask/internal/domain/repository"
+	"rttask/internal/infrastructure/storage"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type GCOptions struct {
+	// GracePeriod файлы моложе этого срока не удаляются: ссылка на них может быть еще не сохранена
+	GracePeriod time.Duration
+	DryRun      bool
+}
+
+type OrphanedFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+type GCReport struct {
+	StartedAt  time.Time
+	Duration   time.Duration
+	DryRun     bool
+	Scanned    int
+	Referenced int
+	Orphaned   []OrphanedFile
+	// Deleted и FreedBytes в режиме DryRun остаются нулевыми
+	Deleted    int
+	FreedBytes int64
+	Failed     int
+}
+
+// OrphanedBytes суммарный размер найденных файлов без ссылок
+func (r *GCReport) OrphanedBytes() int64 {
+	var size int64
+	for _, f := range r.Orphaned {
+		size += f.Size
+	}
+	return size
+}
+
+// GarbageCollector удаляет из хранилища файлы, на которые не ссылается ни одна сущность.
+// Файлы хранятся только в jsonb колонках, поэтому после удаления задач и неудачных запросов остаются в хранилище
+type GarbageCollector struct {
+	storage  storage.FileStorage
+	fileRepo repository.FileRepository
+	logger   *zap.Logger
+}
+
+func NewGarbageCollector(storage storage.FileStorage, fileRepo repository.FileRepository, logger *zap.Logger) *GarbageCollector {
+	return &GarbageCollector{
+		storage:  storage,
+		fileRepo: fileRepo,
+		logger:   logger,
+	}
+}
+
+// Run один проход сборщика. Ссылки читаются до обхода хранилища, поэтому файл,
+// сохраненный во время прохода, либо уже в ссылках, либо моложе GracePeriod
+func (g *GarbageCollector) Run(ctx context.Context, opts GCOptions) (*GCReport, error) {
+	report := &GCReport{StartedAt: time.Now(), DryRun: opts.DryRun}
+
+	referenced, err := g.fileRepo.GetReferencedPaths(ctx)
+	if err != nil {
+		g.logger.Error("failed to collect referenced files", zap.Error(err))
+		return nil, err
+	}
+	report.Referenced = len(referenced)
+
+	threshold := report.StartedAt.Add(-opts.GracePeriod)
+	err = g.storage.Walk(ctx, func(path string, info storage.FileInfo) error {
+		report.Scanned++
+		if _, ok := referenced[path]; ok || info.ModTime.After(threshold) {
+			return nil
+		}
+		report.Orphaned = append(report.Orphaned, OrphanedFile{Path: path, Size: info.Size, ModTime: info.ModTime})
+		return nil
+	})
+	if err != nil {
+		g.logger.Error("failed to walk storage", zap.Error(err))
+		return nil, err
+	}
+
+	if !opts.DryRun {
+		for _, orphan := range report.Orphaned {
+			if err := g.storage.Delete(ctx, orphan.Path); err != nil {
+				g.logger.Error("failed to delete orphaned file", zap.String("path", orphan.Path), zap.Error(err))
+				report.Failed++
+				continue
+			}
+			report.Deleted++
+			report.FreedBytes += orphan.Size
+		}
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	g.logger.Info("file gc finished",
+		zap.Bool("dryRun", report.DryRun),
+		zap.Int("scanned", report.Scanned),
+		zap.Int("referenced", report.Referenced),
+		zap.Int("orphaned", len(report.Orphaned)),
+		zap.Int64("orphanedBytes", report.OrphanedBytes()),
+		zap.Int("deleted", report.Deleted),
+		zap.Int("failed", report.Failed),
+		zap.Duration("duration", report.Duration),
+	)
+	return report, nil
+}
+
+// Start запускает сборщик по расписанию до отмены контекста
+func (g *GarbageCollector) Start(ctx context.Context, interval time.Duration, opts GCOptions) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := g.Run(ctx, opts); err != nil {
+				g.logger.Error("file gc failed", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	_ "image/gif" // gif декодируется только первым кадром, анимация для аватаров не нужна
+	"image/jpeg"
+	"image/png"
+	"io"
+	domainerrors "rttask/internal/domain/errors"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxImagePixels защита от изображений, которые занимают гигабайты после декодирования
+const maxImagePixels = 50 * 1000 * 1000
+
+const jpegQuality = 85
+
+type ImageVariant struct {
+	Name    string
+	MaxSide int
+}
+
+// encodedImage перекодированное изображение, готовое к сохранению
+type encodedImage struct {
+	Data     []byte
+	MimeType string
+	Ext      string
+	Width    int
+	Height   int
+}
+
+type processedImage struct {
+	Original encodedImage
+	Variants map[string]encodedImage
+}
+
+// processImage декодирует изображение, поворачивает по EXIF, перекодирует без метаданных
+// и строит уменьшенные копии. jpeg остается jpeg, остальные форматы сохраняются в png
+func processImage(r io.Reader, variants []ImageVariant) (*processedImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, domainerrors.NewInternalError("failed to read image", err)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, domainerrors.NewValidationError("Invalid image")
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, domainerrors.NewValidationError("Image resolution too big")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, domainerrors.NewValidationError("Invalid image")
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, exifOrientation(data))
+	}
+
+	original, err := encodeImage(img, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &processedImage{Original: original, Variants: make(map[string]encodedImage, len(variants))}
+	for _, variant := range variants {
+		encoded, err := encodeImage(resizeImage(img, variant.MaxSide), format)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants[variant.Name] = encoded
+	}
+	return result, nil
+}
+
+func encodeImage(img image.Image, format string) (encodedImage, error) {
+	var buf bytes.Buffer
+	bounds := img.Bounds()
+
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return encodedImage{}, domainerrors.NewInternalError("failed to encode image", err)
+		}
+		return encodedImage{Data: buf.Bytes(), MimeType: "image/jpeg", Ext: ".jpg", Width: bounds.Dx(), Height: bounds.Dy()}, nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return encodedImage{}, domainerrors.NewInternalError("failed to encode image", err)
+	}
+	return encodedImage{Data: buf.Bytes(), MimeType: "image/png", Ext: ".png", Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+// resizeImage уменьшает изображение так, чтобы большая сторона была не больше maxSide. Не увеличивает
+func resizeImage(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*maxSide/width)
+		width = maxSide
+	} else {
+		width = max(1, width*maxSide/height)
+		height = maxSide
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// exifOrientation читает тег Orientation (0x0112) из APP1 сегмента jpeg. 1 если тега нет
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		size := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if marker == 0xDA || size < 2 || pos+2+size > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xE1 && len(segment) > 14 && string(segment[:6]) == "Exif\x00\x00" {
+			return parseTIFFOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+	return 1
+}
+
+func parseTIFFOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	// смещение сравнивается до приведения к int, чтобы большое значение не стало отрицательным
+	offset := order.Uint32(tiff[4:8])
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return 1
+	}
+	ifd := int(offset)
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		// Orientation типа SHORT (3), значение в первых двух байтах поля значения
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			if order.Uint16(tiff[entry+2:entry+4]) != 3 {
+				return 1
+			}
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// applyOrientation поворачивает и отражает изображение согласно EXIF, т.к. метаданные не сохраняются
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
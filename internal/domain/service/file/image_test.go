@@ -0,0 +1,169 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+type ifdEntry struct {
+	tag, kind uint16
+	value     uint16
+}
+
+// tiffBlock TIFF заголовок и один IFD по смещению 8
+func tiffBlock(order binary.ByteOrder, entries ...ifdEntry) []byte {
+	var buf bytes.Buffer
+	if order == binary.LittleEndian {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+	binary.Write(&buf, order, uint16(42))
+	binary.Write(&buf, order, uint32(8))
+	binary.Write(&buf, order, uint16(len(entries)))
+	for _, e := range entries {
+		binary.Write(&buf, order, e.tag)
+		binary.Write(&buf, order, e.kind)
+		binary.Write(&buf, order, uint32(1))
+		binary.Write(&buf, order, e.value)
+		binary.Write(&buf, order, uint16(0))
+	}
+	binary.Write(&buf, order, uint32(0)) // следующего IFD нет
+	return buf.Bytes()
+}
+
+func segment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// jpegWith SOI, сегменты и начало скана. Данные изображения exifOrientation не читает
+func jpegWith(segments ...[]byte) []byte {
+	data := []byte{0xFF, 0xD8}
+	for _, seg := range segments {
+		data = append(data, seg...)
+	}
+	return append(data, 0xFF, 0xDA, 0x00, 0x02)
+}
+
+func exifSegment(tiff []byte) []byte {
+	return segment(0xE1, append([]byte("Exif\x00\x00"), tiff...))
+}
+
+func TestExifOrientation(t *testing.T) {
+	orders := map[string]binary.ByteOrder{"II": binary.LittleEndian, "MM": binary.BigEndian}
+	for name, order := range orders {
+		for orientation := 1; orientation <= 8; orientation++ {
+			tiff := tiffBlock(order,
+				ifdEntry{tag: 0x010F, kind: 2, value: 0x4142}, // Make перед Orientation
+				ifdEntry{tag: 0x0112, kind: 3, value: uint16(orientation)},
+			)
+			jfif := segment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+			if got := exifOrientation(jpegWith(jfif, exifSegment(tiff))); got != orientation {
+				t.Errorf("%s orientation %d: got %d", name, orientation, got)
+			}
+		}
+	}
+}
+
+func TestExifOrientationMalformed(t *testing.T) {
+	le := binary.LittleEndian
+	valid := tiffBlock(le, ifdEntry{tag: 0x0112, kind: 3, value: 6})
+
+	withIFDOffset := func(offset uint32) []byte {
+		tiff := bytes.Clone(valid)
+		le.PutUint32(tiff[4:8], offset)
+		return tiff
+	}
+	// запись Orientation дальше конца данных
+	withEntryCount := func(count uint16) []byte {
+		tiff := tiffBlock(le, ifdEntry{tag: 0x010F, kind: 2, value: 1})
+		le.PutUint16(tiff[8:10], count)
+		return tiff
+	}
+	truncatedAPP1 := exifSegment(valid)
+	binary.BigEndian.PutUint16(truncatedAPP1[2:4], uint16(len(truncatedAPP1)+100))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not jpeg", []byte("\x89PNG\r\n\x1a\n")},
+		{"only SOI", []byte{0xFF, 0xD8}},
+		{"no exif", jpegWith(segment(0xE0, []byte("JFIF\x00")))},
+		{"xmp app1", jpegWith(segment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x/>")))},
+		{"exif after scan", append(jpegWith(), exifSegment(valid)...)},
+		{"truncated app1", append([]byte{0xFF, 0xD8}, truncatedAPP1[:len(truncatedAPP1)-4]...)},
+		{"segment size below 2", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0x00}},
+		{"garbage between segments", []byte{0xFF, 0xD8, 0x00, 0xFF, 0xE1, 0x00, 0x04}},
+		{"short exif", jpegWith(exifSegment([]byte("II*\x00")))},
+		{"bad byte order", jpegWith(exifSegment(append([]byte("XX"), valid[2:]...)))},
+		{"ifd offset past end", jpegWith(exifSegment(withIFDOffset(uint32(len(valid)))))},
+		{"ifd offset overflow", jpegWith(exifSegment(withIFDOffset(0xFFFFFFFF)))},
+		{"entry count past end", jpegWith(exifSegment(withEntryCount(50)))},
+		{"no orientation tag", jpegWith(exifSegment(tiffBlock(le, ifdEntry{tag: 0x010F, kind: 2, value: 1})))},
+		{"orientation zero", jpegWith(exifSegment(tiffBlock(le, ifdEntry{tag: 0x0112, kind: 3, value: 0})))},
+		{"orientation nine", jpegWith(exifSegment(tiffBlock(le, ifdEntry{tag: 0x0112, kind: 3, value: 9})))},
+		{"orientation wrong type", jpegWith(exifSegment(tiffBlock(le, ifdEntry{tag: 0x0112, kind: 4, value: 6})))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exifOrientation(tt.data); got != 1 {
+				t.Fatalf("exifOrientation = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func FuzzExifOrientation(f *testing.F) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		f.Add(jpegWith(exifSegment(tiffBlock(order, ifdEntry{tag: 0x0112, kind: 3, value: 6}))))
+	}
+	f.Add(jpegWith(segment(0xE0, []byte("JFIF\x00"))))
+	f.Add([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x10, 'E', 'x', 'i', 'f', 0, 0, 'M', 'M', 0, 42, 0xFF, 0xFF, 0xFF, 0xFF})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if got := exifOrientation(data); got < 1 || got > 8 {
+			t.Fatalf("exifOrientation = %d, want 1..8", got)
+		}
+	})
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 2x1: красный слева, синий справа
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	tests := []struct {
+		orientation   int
+		width, height int
+		redAt         image.Point
+	}{
+		{1, 2, 1, image.Pt(0, 0)},
+		{2, 2, 1, image.Pt(1, 0)},
+		{3, 2, 1, image.Pt(1, 0)},
+		{4, 2, 1, image.Pt(0, 0)},
+		{5, 1, 2, image.Pt(0, 0)},
+		{6, 1, 2, image.Pt(0, 0)},
+		{7, 1, 2, image.Pt(0, 1)},
+		{8, 1, 2, image.Pt(0, 1)},
+	}
+	for _, tt := range tests {
+		img := applyOrientation(src, tt.orientation)
+		bounds := img.Bounds()
+		if bounds.Dx() != tt.width || bounds.Dy() != tt.height {
+			t.Errorf("orientation %d: size %dx%d, want %dx%d", tt.orientation, bounds.Dx(), bounds.Dy(), tt.width, tt.height)
+			continue
+		}
+		if got := color.RGBAModel.Convert(img.At(tt.redAt.X, tt.redAt.Y)); got != red {
+			t.Errorf("orientation %d: pixel at %v = %v, want red", tt.orientation, tt.redAt, got)
+		}
+	}
+}
@@ -10,6 +10,7 @@ type FileInput struct {
 	FileHeader *multipart.FileHeader
 	EntityType string
 	UploaderID uint
+	CompanyID  uint // компания, на квоту которой записывается файл, 0 если файл не относится к компании
 }
 
 // NewFileInput создает FileInput из multipart.FileHeader
@@ -31,3 +32,17 @@ func NewFileInput(
 		UploaderID: uploaderID,
 	}, nil
 }
+
+type UploadInput struct {
+	Length     int64
+	FileName   string
+	MimeType   string
+	EntityType string
+	UploaderID uint
+}
+
+// Quota ограничения места в хранилище в байтах, 0 без ограничения
+type Quota struct {
+	CompanyBytes int64
+	UserBytes    int64
+}
@@ -1,21 +1,53 @@
 package file
 
+import domainerrors "rttask/internal/domain/errors"
+
+// multipartOverhead запас на заголовки частей и текстовые поля формы сверх MaxTotalSize
+const multipartOverhead = 1024 * 1024
+
 type ValidationProfile struct {
 	MaxFileSize  int64
+	MaxTotalSize int64 // суммарный размер файлов одного запроса, 0 без ограничения
 	AllowedMimes []string
 	Description  string
+
+	// ImageVariants если задано, изображения перекодируются без метаданных
+	// и дополнительно сохраняются уменьшенными копиями
+	ImageVariants []ImageVariant
+}
+
+// MaxRequestSize ограничение тела multipart запроса с файлами профиля, 0 без ограничения
+func (p ValidationProfile) MaxRequestSize() int64 {
+	if p.MaxTotalSize == 0 {
+		return 0
+	}
+	return p.MaxTotalSize + multipartOverhead
+}
+
+// TotalSizeError файлы одного запроса больше MaxTotalSize
+func (p ValidationProfile) TotalSizeError() error {
+	return domainerrors.NewValidationError("Total files size too big").
+		WithMeta("maxTotalSize", p.MaxTotalSize)
+}
+
+var imageVariants = []ImageVariant{
+	{Name: "64", MaxSide: 64},
+	{Name: "256", MaxSide: 256},
 }
 
 var CompanyProfile = ValidationProfile{
-	MaxFileSize: 5 * 1024 * 1024, // 10 MB
+	MaxFileSize:  5 * 1024 * 1024, // 5 MB
+	MaxTotalSize: 5 * 1024 * 1024, // 5 MB
 	AllowedMimes: []string{
 		"image/jpeg", "image/png", "image/gif", "image/webp",
 	},
-	Description: "Company validation files Profile",
+	Description:   "Company validation files Profile",
+	ImageVariants: imageVariants,
 }
 
 var TaskProfile = ValidationProfile{
-	MaxFileSize: 10 * 1024 * 1024, // 10 MB
+	MaxFileSize:  10 * 1024 * 1024, // 10 MB
+	MaxTotalSize: 50 * 1024 * 1024, // 50 MB
 	AllowedMimes: []string{
 		"application/pdf",
 		"application/msword",
@@ -32,3 +64,10 @@ var TaskProfile = ValidationProfile{
 	},
 	Description: "task files",
 }
+
+// UploadProfile файлы задач и комментариев, загружаемые частями через /uploads
+var UploadProfile = ValidationProfile{
+	MaxFileSize:  2 * 1024 * 1024 * 1024, // 2 GB
+	AllowedMimes: TaskProfile.AllowedMimes,
+	Description:  "resumable task and comment files",
+}
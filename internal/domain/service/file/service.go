@@ -1,15 +1,25 @@
 package file
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"path/filepath"
 	domainerrors "rttask/internal/domain/errors"
 	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/infrastructure/antivirus"
 	"rttask/internal/infrastructure/storage"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/gabriel-vasile/mimetype"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -19,6 +29,7 @@ var mimeMap = map[string]string{
 	".jpeg": "image/jpeg",
 	".png":  "image/png",
 	".gif":  "image/gif",
+	".webp": "image/webp",
 	".pdf":  "application/pdf",
 	".doc":  "application/msword",
 	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
@@ -29,39 +40,521 @@ var mimeMap = map[string]string{
 	".zip":  "application/zip",
 }
 
+// sniffFallbacks общие типы, которые определяются по содержимому для форматов
+// без собственной сигнатуры (docx это zip, doc это ole контейнер, csv это текст)
+var sniffFallbacks = map[string][]string{
+	"application/msword":       {"application/x-ole-storage"},
+	"application/vnd.ms-excel": {"application/x-ole-storage"},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": {"application/zip"},
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       {"application/zip"},
+	"text/csv": {"text/plain"},
+}
+
+const maxFileNameLength = 255
+
+// Download содержимое файла для отдачи клиенту.
+// Если хранилище умеет выдавать временные ссылки, заполнен только URL
+type Download struct {
+	File    *model.File
+	Content io.ReadSeekCloser
+	ModTime time.Time
+	URL     string
+}
+
 type FileService struct {
-	storage storage.FileStorage
-	logger  *zap.Logger
+	storage  storage.FileStorage
+	scanner  antivirus.FileScanner
+	fileRepo repository.FileRepository
+	userRepo repository.UserRepository
+	uow      repository.UnitOfWork
+	quota    Quota
+	logger   *zap.Logger
 }
 
-func NewFileService(storage storage.FileStorage, logger *zap.Logger) *FileService {
+func NewFileService(storage storage.FileStorage, scanner antivirus.FileScanner, fileRepo repository.FileRepository, userRepo repository.UserRepository, uow repository.UnitOfWork, quota Quota, logger *zap.Logger) *FileService {
 	return &FileService{
-		storage: storage,
-		logger:  logger,
+		storage:  storage,
+		scanner:  scanner,
+		fileRepo: fileRepo,
+		userRepo: userRepo,
+		uow:      uow,
+		quota:    quota,
+		logger:   logger,
 	}
 }
 
 func (s *FileService) UploadFile(ctx context.Context, input FileInput, profile ValidationProfile) (*model.File, error) {
-	if err := s.validate(input, profile); err != nil {
+	if err := s.CheckQuota(ctx, input.CompanyID, input.UploaderID, input.FileHeader.Size); err != nil {
 		return nil, err
 	}
+	return s.uploadFile(ctx, input, profile)
+}
+
+func (s *FileService) uploadFile(ctx context.Context, input FileInput, profile ValidationProfile) (*model.File, error) {
+	mimeType, err := s.validate(input, profile)
+	if err != nil {
+		return nil, err
+	}
+	fileName := sanitizeFileName(input.FileHeader.Filename)
 	fileID := uuid.New().String()
-	filePath := s.generateFilePath(input.EntityType, fileID, input.FileHeader.Filename)
+
+	scan, err := s.scan(ctx, input.FileHeader.Filename, input.File)
+	if err != nil {
+		return nil, err
+	}
+	if scan.Infected {
+		return s.uploadQuarantined(ctx, input, fileID, fileName, mimeType, scan.Signature)
+	}
+
+	if len(profile.ImageVariants) > 0 && strings.HasPrefix(mimeType, "image/") {
+		return s.uploadImage(ctx, input, profile.ImageVariants, fileID, fileName, scanStatus(scan))
+	}
+
+	filePath := s.generateFilePath(input.EntityType, fileID, fileName)
 
 	if err := s.storage.Save(ctx, input.File, filePath); err != nil {
 		return nil, err
 	}
 	newFile := model.NewFile(
 		fileID,
-		input.FileHeader.Filename,
+		fileName,
 		filePath,
 		input.FileHeader.Size,
-		input.FileHeader.Header.Get("Content-Type"),
+		mimeType,
 		input.UploaderID,
 	)
+	newFile.ScanStatus = scanStatus(scan)
+	return newFile, nil
+}
+
+// uploadQuarantined сохраняет зараженный файл отдельно от остальных, чтобы его можно было разобрать.
+// Такой файл остается привязан к сущности, но не отдается при скачивании
+func (s *FileService) uploadQuarantined(ctx context.Context, input FileInput, fileID string, fileName string, mimeType string, signature string) (*model.File, error) {
+	s.logger.Warn("infected file quarantined",
+		zap.String("fileID", fileID),
+		zap.String("fileName", fileName),
+		zap.String("signature", signature),
+		zap.Uint("uploaderID", input.UploaderID),
+	)
+
+	filePath := "quarantine/" + s.generateFilePath(input.EntityType, fileID, fileName)
+	if err := s.storage.Save(ctx, input.File, filePath); err != nil {
+		return nil, err
+	}
+	newFile := model.NewFile(fileID, fileName, filePath, input.FileHeader.Size, mimeType, input.UploaderID)
+	newFile.ScanStatus = model.ScanStatusQuarantined
+	newFile.ScanSignature = signature
+	return newFile, nil
+}
+
+// scan проверяет файл антивирусом и возвращает чтение в начало файла
+func (s *FileService) scan(ctx context.Context, fileName string, content io.ReadSeeker) (*antivirus.ScanResult, error) {
+	result, err := s.scanner.Scan(ctx, content)
+	if errors.Is(err, antivirus.ErrStreamTooLarge) {
+		return nil, domainerrors.NewValidationError("File is too large for antivirus scan")
+	}
+	if err != nil {
+		s.logger.Error("failed to scan file", zap.String("fileName", fileName), zap.Error(err))
+		return nil, domainerrors.NewInternalError("failed to scan file", err)
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return nil, domainerrors.NewInternalError("failed to read file", err)
+	}
+	return result, nil
+}
+
+// scanStatus статус файла по результату антивируса
+func scanStatus(scan *antivirus.ScanResult) model.ScanStatus {
+	switch {
+	case scan.Infected:
+		return model.ScanStatusQuarantined
+	case scan.Skipped:
+		return model.ScanStatusSkipped
+	}
+	return model.ScanStatusClean
+}
+
+// uploadImage сохраняет перекодированное изображение и его уменьшенные копии
+func (s *FileService) uploadImage(ctx context.Context, input FileInput, variants []ImageVariant, fileID string, fileName string, status model.ScanStatus) (*model.File, error) {
+	processed, err := processImage(input.File, variants)
+	if err != nil {
+		return nil, err
+	}
+
+	original := processed.Original
+	if s.mimeTypeByExtension(fileName) != original.MimeType {
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + original.Ext
+	}
+	filePath := s.generateFilePath(input.EntityType, fileID, fileName)
+	if err := s.storage.Save(ctx, bytes.NewReader(original.Data), filePath); err != nil {
+		return nil, err
+	}
+
+	newFile := model.NewFile(fileID, fileName, filePath, int64(len(original.Data)), original.MimeType, input.UploaderID)
+	newFile.ScanStatus = status
+	newFile.Variants = map[string]*model.FileVariant{
+		model.OriginalVariant: {
+			Path:     filePath,
+			Size:     int64(len(original.Data)),
+			MimeType: original.MimeType,
+			Width:    original.Width,
+			Height:   original.Height,
+		},
+	}
+
+	for name, variant := range processed.Variants {
+		variantPath := s.generateFilePath(input.EntityType, fileID+"_"+name, fileName)
+		if err := s.storage.Save(ctx, bytes.NewReader(variant.Data), variantPath); err != nil {
+			s.DeleteFiles(ctx, newFile)
+			return nil, err
+		}
+		newFile.Variants[name] = &model.FileVariant{
+			Path:     variantPath,
+			Size:     int64(len(variant.Data)),
+			MimeType: variant.MimeType,
+			Width:    variant.Width,
+			Height:   variant.Height,
+		}
+	}
+	return newFile, nil
+}
+
+// UploadFiles загружает несколько файлов одного запроса. Суммарный размер ограничен MaxTotalSize профиля,
+// при ошибке уже загруженные файлы удаляются
+func (s *FileService) UploadFiles(ctx context.Context, inputs []FileInput, profile ValidationProfile) ([]*model.File, error) {
+	if err := s.validateTotalSize(inputs, profile); err != nil {
+		return nil, err
+	}
+	if err := s.checkQuotaForInputs(ctx, inputs); err != nil {
+		return nil, err
+	}
+
+	uploaded := make([]*model.File, 0, len(inputs))
+	for _, input := range inputs {
+		newFile, err := s.uploadFile(ctx, input, profile)
+		if err != nil {
+			s.DeleteFiles(ctx, uploaded...)
+			return nil, err
+		}
+		uploaded = append(uploaded, newFile)
+	}
+	return uploaded, nil
+}
+
+// CopyFiles копирует файлы в хранилище под новыми id, например из шаблона в задачу.
+// Зараженные файлы не копируются, при ошибке уже скопированные удаляются
+func (s *FileService) CopyFiles(ctx context.Context, files []*model.File, entityType string, uploaderID uint) ([]*model.File, error) {
+	copied := make([]*model.File, 0, len(files))
+	for _, f := range files {
+		if f == nil || f.IsQuarantined() {
+			continue
+		}
+		newFile, err := s.copyFile(ctx, f, entityType, uploaderID)
+		if err != nil {
+			s.logger.Error("failed to copy file", zap.String("fileID", f.ID), zap.Error(err))
+			s.DeleteFiles(ctx, copied...)
+			return nil, err
+		}
+		copied = append(copied, newFile)
+	}
+	return copied, nil
+}
+
+func (s *FileService) copyFile(ctx context.Context, f *model.File, entityType string, uploaderID uint) (*model.File, error) {
+	fileID := uuid.New().String()
+	newFile := model.NewFile(fileID, f.Name, s.generateFilePath(entityType, fileID, f.Name), f.Size, f.MimeType, uploaderID)
+	newFile.ScanStatus = f.ScanStatus
+	if err := s.copyObject(ctx, f.Path, newFile.Path); err != nil {
+		return nil, err
+	}
+
+	if len(f.Variants) > 0 {
+		newFile.Variants = make(map[string]*model.FileVariant, len(f.Variants))
+	}
+	for name, variant := range f.Variants {
+		newVariant := *variant
+		if variant.Path == f.Path {
+			newVariant.Path = newFile.Path
+		} else {
+			newVariant.Path = s.generateFilePath(entityType, fileID+"_"+name, f.Name)
+			if err := s.copyObject(ctx, variant.Path, newVariant.Path); err != nil {
+				s.DeleteFiles(ctx, newFile)
+				return nil, err
+			}
+		}
+		newFile.Variants[name] = &newVariant
+	}
 	return newFile, nil
 }
 
+func (s *FileService) copyObject(ctx context.Context, from string, to string) error {
+	content, err := s.storage.Open(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return s.storage.Save(ctx, content, to)
+}
+
+// CheckQuota проверяет, что size байт помещаются в квоты компании и пользователя.
+// Нулевой companyID или userID пропускает соответствующую проверку. Окончательная проверка
+// делается в транзакции, сохраняющей файлы, после LockQuota
+func (s *FileService) CheckQuota(ctx context.Context, companyID uint, userID uint, size int64) error {
+	if companyID != 0 && s.quota.CompanyBytes > 0 {
+		usage, err := s.fileRepo.GetCompanyUsage(ctx, companyID)
+		if err != nil {
+			return err
+		}
+		if usage.TotalBytes+size > s.quota.CompanyBytes {
+			return domainerrors.NewValidationError("Company storage quota exceeded").
+				WithMeta("quotaBytes", s.quota.CompanyBytes).
+				WithMeta("usedBytes", usage.TotalBytes).
+				WithMeta("requestedBytes", size)
+		}
+	}
+	if userID != 0 && s.quota.UserBytes > 0 {
+		used, err := s.fileRepo.GetUserUsage(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if used+size > s.quota.UserBytes {
+			return domainerrors.NewValidationError("User storage quota exceeded").
+				WithMeta("quotaBytes", s.quota.UserBytes).
+				WithMeta("usedBytes", used).
+				WithMeta("requestedBytes", size)
+		}
+	}
+	return nil
+}
+
+// LockQuota блокирует квоты компании и пользователя до конца транзакции, вызывается в ней перед CheckQuota.
+// Без блокировки параллельные загрузки видят одно и то же свободное место и вместе превышают квоту
+func (s *FileService) LockQuota(ctx context.Context, companyID uint, userID uint) error {
+	if s.quota.CompanyBytes <= 0 {
+		companyID = 0
+	}
+	if s.quota.UserBytes <= 0 {
+		userID = 0
+	}
+	if companyID == 0 && userID == 0 {
+		return nil
+	}
+	return s.fileRepo.LockQuota(ctx, companyID, userID)
+}
+
+// checkQuotaForInputs файлы одного запроса проверяются суммарно, а не по одному
+func (s *FileService) checkQuotaForInputs(ctx context.Context, inputs []FileInput) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+	var total int64
+	for _, input := range inputs {
+		total += input.FileHeader.Size
+	}
+	return s.CheckQuota(ctx, inputs[0].CompanyID, inputs[0].UploaderID, total)
+}
+
+// GetCompanyUsage место, занятое файлами компании. Доступно участникам компании с правом просмотра компании
+func (s *FileService) GetCompanyUsage(ctx context.Context, companyID uint, userID uint) (*model.StorageUsage, error) {
+	if err := s.checkCompanyAccess(ctx, userID, companyID, rbac.CompanyView); err != nil {
+		return nil, err
+	}
+	usage, err := s.fileRepo.GetCompanyUsage(ctx, companyID)
+	if err != nil {
+		s.logger.Error("failed to get storage usage", zap.Uint("companyID", companyID), zap.Error(err))
+		return nil, err
+	}
+	usage.QuotaBytes = s.quota.CompanyBytes
+	return usage, nil
+}
+
+// Download открывает файл, если у пользователя есть доступ к сущности-владельцу.
+// userID == 0 для анонимных запросов, им доступны только публичные аватары.
+// variant выбирает уменьшенную копию изображения, пустая строка это исходный файл
+func (s *FileService) Download(ctx context.Context, fileID string, variant string, userID uint) (*Download, error) {
+	ref, err := s.getRef(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeRead(ctx, ref, userID); err != nil {
+		return nil, err
+	}
+	if ref.File.IsQuarantined() {
+		return nil, domainerrors.NewForbiddenError("file is quarantined").WithMeta("fileId", fileID)
+	}
+
+	requested, ok := ref.File.Variant(variant)
+	if !ok {
+		return nil, domainerrors.NewValidationError("Unknown file variant").WithMeta("variant", variant)
+	}
+	ref = &model.FileRef{File: requested, OwnerType: ref.OwnerType, OwnerID: ref.OwnerID, CompanyID: ref.CompanyID}
+
+	if presigner, ok := s.storage.(storage.Presigner); ok {
+		url, err := presigner.PresignGet(ctx, ref.File.Path, ref.File.MimeType, ref.File.ContentDisposition())
+		if err != nil {
+			s.logger.Error("failed to presign file", zap.String("fileID", fileID), zap.Error(err))
+			return nil, err
+		}
+		// пустая ссылка: хранилище недоступно клиентам напрямую, файл отдается через приложение
+		if url != "" {
+			return &Download{File: ref.File, URL: url}, nil
+		}
+	}
+
+	info, err := s.storage.Stat(ctx, ref.File.Path)
+	if err != nil {
+		s.logger.Error("failed to stat file", zap.String("fileID", fileID), zap.Error(err))
+		return nil, err
+	}
+	content, err := s.storage.Open(ctx, ref.File.Path)
+	if err != nil {
+		s.logger.Error("failed to open file", zap.String("fileID", fileID), zap.Error(err))
+		return nil, err
+	}
+	return &Download{File: ref.File, Content: content, ModTime: info.ModTime}, nil
+}
+
+// DeleteFile убирает файл из сущности-владельца и удаляет его из хранилища
+func (s *FileService) DeleteFile(ctx context.Context, fileID string, userID uint) error {
+	ref, err := s.getRef(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeDelete(ctx, ref, userID); err != nil {
+		return err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.fileRepo.Detach(ctx, ref)
+	})
+	if err != nil {
+		s.logger.Error("failed to detach file", zap.String("fileID", fileID), zap.Error(err))
+		return err
+	}
+
+	s.DeleteFiles(ctx, ref.File)
+	s.logger.Info("file deleted", zap.String("fileID", fileID), zap.Uint("userID", userID))
+	return nil
+}
+
+// DeleteFiles удаляет загруженные файлы. Используется как компенсация,
+// если операция, к которой относились файлы, не удалась
+func (s *FileService) DeleteFiles(ctx context.Context, files ...*model.File) {
+	ctx = context.WithoutCancel(ctx)
+	for _, f := range files {
+		if f == nil {
+			continue
+		}
+		for _, path := range f.AllPaths() {
+			if err := s.storage.Delete(ctx, path); err != nil {
+				s.logger.Error("failed to delete file",
+					zap.String("fileID", f.ID),
+					zap.String("path", path),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+func (s *FileService) getRef(ctx context.Context, fileID string) (*model.FileRef, error) {
+	ref, err := s.fileRepo.GetRefByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if ref.File == nil {
+		return nil, domainerrors.NewNotFoundError("file", fileID)
+	}
+	return ref, nil
+}
+
+// authorizeRead задачи и комментарии видят только участники компании с правом просмотра
+func (s *FileService) authorizeRead(ctx context.Context, ref *model.FileRef, userID uint) error {
+	if ref.IsPublic() {
+		return nil
+	}
+	switch ref.OwnerType {
+	case model.FileOwnerTask:
+		return s.checkCompanyAccess(ctx, userID, ref.CompanyID, rbac.TaskView)
+	case model.FileOwnerComment:
+		return s.checkCompanyAccess(ctx, userID, ref.CompanyID, rbac.CommentView)
+	case model.FileOwnerTemplate:
+		return s.checkCompanyAccess(ctx, userID, ref.CompanyID, rbac.TaskView)
+	}
+	return domainerrors.NewForbiddenError("dont have permission")
+}
+
+// authorizeDelete загрузивший файл может удалить его сам, иначе нужны права на изменение владельца
+func (s *FileService) authorizeDelete(ctx context.Context, ref *model.FileRef, userID uint) error {
+	if userID == 0 {
+		return domainerrors.NewUnauthorizedError("Not authorized")
+	}
+	switch ref.OwnerType {
+	case model.FileOwnerTask, model.FileOwnerTemplate:
+		if ref.File.UploaderID == userID {
+			return s.checkCompanyAccess(ctx, userID, ref.CompanyID, rbac.TaskView)
+		}
+		return s.checkCompanyAccess(ctx, userID, ref.CompanyID, rbac.TaskUpdate)
+	case model.FileOwnerComment:
+		if ref.File.UploaderID == userID {
+			return s.checkCompanyAccess(ctx, userID, ref.CompanyID, rbac.CommentView)
+		}
+		return s.checkCompanyAccess(ctx, userID, ref.CompanyID, rbac.CommentUpdate)
+	case model.FileOwnerCompany:
+		// право CompanyUpdate глобальное, менять аватар можно только своей компании
+		return s.checkCompanyAccess(ctx, userID, ref.OwnerID, rbac.CompanyUpdate)
+	case model.FileOwnerUser:
+		if ref.OwnerID == userID {
+			return nil
+		}
+		// право UserUpdate глобальное, чужой аватар можно удалить только коллеге по компании
+		return s.checkSharedCompany(ctx, userID, ref.OwnerID, rbac.UserUpdate)
+	}
+	return domainerrors.NewForbiddenError("dont have permission")
+}
+
+func (s *FileService) checkCompanyAccess(ctx context.Context, userID uint, companyID uint, permission rbac.Permission) error {
+	if err := s.checkPermission(ctx, userID, permission); err != nil {
+		return err
+	}
+	inCompany, err := s.userRepo.IsUserInCompany(ctx, userID, companyID)
+	if err != nil {
+		return err
+	}
+	if !inCompany {
+		return domainerrors.NewForbiddenError("user not in company")
+	}
+	return nil
+}
+
+func (s *FileService) checkSharedCompany(ctx context.Context, userID uint, otherID uint, permission rbac.Permission) error {
+	if err := s.checkPermission(ctx, userID, permission); err != nil {
+		return err
+	}
+	shared, err := s.userRepo.ShareCompany(ctx, userID, otherID)
+	if err != nil {
+		return err
+	}
+	if !shared {
+		return domainerrors.NewForbiddenError("user not in company")
+	}
+	return nil
+}
+
+func (s *FileService) checkPermission(ctx context.Context, userID uint, permission rbac.Permission) error {
+	if userID == 0 {
+		return domainerrors.NewUnauthorizedError("Not authorized")
+	}
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.Can(permission) {
+		return domainerrors.NewForbiddenError("dont have permission")
+	}
+	return nil
+}
+
 func (s *FileService) generateFilePath(entityType string, fileID string, fileName string) string {
 	now := time.Now()
 	ext := filepath.Ext(fileName)
@@ -70,25 +563,101 @@ func (s *FileService) generateFilePath(entityType string, fileID string, fileNam
 	return fmt.Sprintf("%s/%d/%02d/%s%s", entityType, now.Year(), now.Month(), fileID, ext)
 }
 
-func (s *FileService) validate(input FileInput, profile ValidationProfile) error {
-	if input.FileHeader.Size > profile.MaxFileSize {
-		return domainerrors.NewValidationError("File size too big")
+// validate проверяет размер и тип файла. Тип по расширению, заявленный клиентом и определенный
+// по содержимому должны совпадать. Возвращает проверенный mime тип
+func (s *FileService) validate(input FileInput, profile ValidationProfile) (string, error) {
+	extMime, err := s.validateMeta(input.FileHeader.Filename, input.FileHeader.Header.Get("Content-Type"), input.FileHeader.Size, profile)
+	if err != nil {
+		return "", err
+	}
+	if err := s.validateContent(input.FileHeader.Filename, extMime, input.File); err != nil {
+		return "", err
+	}
+	return extMime, nil
+}
+
+// validateMeta проверки, которые не требуют содержимого файла
+func (s *FileService) validateMeta(fileName string, contentType string, size int64, profile ValidationProfile) (string, error) {
+	if size > profile.MaxFileSize {
+		return "", domainerrors.NewValidationError("File size too big")
+	}
+	if size == 0 {
+		return "", domainerrors.NewValidationError("File is empty")
+	}
+
+	extMime := s.mimeTypeByExtension(fileName)
+	if extMime == "" || !s.isAllowedMime(extMime, profile.AllowedMimes) {
+		return "", domainerrors.NewValidationError("Mime type not allowed")
+	}
+
+	declared, _, _ := mime.ParseMediaType(contentType)
+	if declared != "" && declared != "application/octet-stream" && !strings.EqualFold(declared, extMime) {
+		return "", domainerrors.NewValidationError("File content type does not match extension").
+			WithMeta("declared", declared).
+			WithMeta("expected", extMime)
+	}
+	return extMime, nil
+}
+
+// validateContent сверяет тип по магическим байтам с типом по расширению
+func (s *FileService) validateContent(fileName string, extMime string, content io.ReadSeeker) error {
+	sniffed, err := s.sniff(content)
+	if err != nil {
+		return err
 	}
-	if input.FileHeader.Size == 0 {
-		return domainerrors.NewValidationError("File is empty")
+	if !s.sniffedMatches(sniffed, extMime) {
+		s.logger.Warn("file content does not match extension",
+			zap.String("fileName", fileName),
+			zap.String("sniffed", sniffed.String()),
+			zap.String("expected", extMime),
+		)
+		return domainerrors.NewValidationError("File content does not match extension").
+			WithMeta("detected", sniffed.String()).
+			WithMeta("expected", extMime)
 	}
-	mimiType := input.FileHeader.Header.Get("Content-Type")
-	if mimiType == "" {
-		mimiType = s.mimeTypeByExtension(input.FileHeader.Filename)
+	return nil
+}
+
+func (s *FileService) validateTotalSize(inputs []FileInput, profile ValidationProfile) error {
+	if profile.MaxTotalSize == 0 {
+		return nil
+	}
+	var total int64
+	for _, input := range inputs {
+		total += input.FileHeader.Size
 	}
-	if !s.isAllowedMime(mimiType, profile.AllowedMimes) {
-		return domainerrors.NewValidationError("Mime type not allowed")
+	if total > profile.MaxTotalSize {
+		return profile.TotalSizeError()
 	}
 	return nil
 }
 
+// sniff определяет тип по магическим байтам и возвращает чтение файла в начало
+func (s *FileService) sniff(content io.ReadSeeker) (*mimetype.MIME, error) {
+	sniffed, err := mimetype.DetectReader(content)
+	if err != nil {
+		return nil, domainerrors.NewInternalError("failed to read file", err)
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return nil, domainerrors.NewInternalError("failed to read file", err)
+	}
+	return sniffed, nil
+}
+
+func (s *FileService) sniffedMatches(sniffed *mimetype.MIME, expected string) bool {
+	if sniffed.Is(expected) {
+		return true
+	}
+	for _, fallback := range sniffFallbacks[expected] {
+		if sniffed.Is(fallback) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *FileService) mimeTypeByExtension(fileName string) string {
-	ext := filepath.Ext(fileName)
+	ext := strings.ToLower(filepath.Ext(fileName))
 
 	if mime, ok := mimeMap[ext]; ok {
 		return mime
@@ -105,3 +674,33 @@ func (s *FileService) isAllowedMime(mime string, allowedMimes []string) bool {
 	}
 	return false
 }
+
+// sanitizeFileName оставляет только имя файла без пути, управляющих и запрещенных символов
+func sanitizeFileName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = name[strings.LastIndex(name, "/")+1:]
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`<>:"|?*`, r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Trim(strings.TrimSpace(name), ".")
+
+	if len(name) > maxFileNameLength {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		base := name[:maxFileNameLength-len(ext)]
+		for !utf8.ValidString(base) {
+			base = base[:len(base)-1]
+		}
+		name = base + ext
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,95 @@
+package file
+
+import (
+	"context"
+	"errors"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/repository/repotest"
+	"rttask/internal/infrastructure/antivirus"
+	"rttask/internal/infrastructure/storage"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type fakeFileRepo struct {
+	repository.FileRepository
+	refs     map[string]*model.FileRef
+	detached []*model.FileRef
+}
+
+func (r *fakeFileRepo) GetRefByID(ctx context.Context, fileID string) (*model.FileRef, error) {
+	ref, ok := r.refs[fileID]
+	if !ok {
+		return nil, domainerrors.NewNotFoundError("file", fileID)
+	}
+	return ref, nil
+}
+
+func (r *fakeFileRepo) Detach(ctx context.Context, ref *model.FileRef) error {
+	r.detached = append(r.detached, ref)
+	return nil
+}
+
+func TestDeleteAvatarRequiresMembership(t *testing.T) {
+	const companyID, otherCompanyID = 10, 20
+	const ownerID = 4
+	avatar := &model.File{ID: "avatar", Path: "avatars/avatar.png", UploaderID: 1}
+	companyAvatar := &model.FileRef{File: avatar, OwnerType: model.FileOwnerCompany, OwnerID: companyID, CompanyID: companyID}
+	userAvatar := &model.FileRef{File: avatar, OwnerType: model.FileOwnerUser, OwnerID: ownerID}
+
+	tests := []struct {
+		name    string
+		ref     *model.FileRef
+		userID  uint
+		wantErr domainerrors.ErrorType
+	}{
+		{name: "member with company update", ref: companyAvatar, userID: 1},
+		{name: "company update in another company", ref: companyAvatar, userID: 2, wantErr: domainerrors.ErrorTypeForbidden},
+		{name: "member without company update", ref: companyAvatar, userID: 3, wantErr: domainerrors.ErrorTypeForbidden},
+		{name: "anonymous", ref: companyAvatar, userID: 0, wantErr: domainerrors.ErrorTypeUnauthorize},
+		{name: "own user avatar", ref: userAvatar, userID: ownerID},
+		{name: "colleague with user update", ref: userAvatar, userID: 5},
+		{name: "user update in another company", ref: userAvatar, userID: 6, wantErr: domainerrors.ErrorTypeForbidden},
+		{name: "colleague without user update", ref: userAvatar, userID: 3, wantErr: domainerrors.ErrorTypeForbidden},
+		{name: "anonymous on user avatar", ref: userAvatar, userID: 0, wantErr: domainerrors.ErrorTypeUnauthorize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileRepo := &fakeFileRepo{refs: map[string]*model.FileRef{avatar.ID: tt.ref}}
+			userRepo := &repotest.UserRepo{
+				Users: map[uint]*model.User{
+					1:       repotest.NewUser(1, rbac.CompanyUpdate),
+					2:       repotest.NewUser(2, rbac.CompanyUpdate),
+					3:       repotest.NewUser(3, rbac.CompanyView),
+					ownerID: repotest.NewUser(ownerID),
+					5:       repotest.NewUser(5, rbac.UserUpdate),
+					6:       repotest.NewUser(6, rbac.UserUpdate),
+				},
+				Members: map[uint][]uint{companyID: {1, 3, ownerID, 5}, otherCompanyID: {2, 6}},
+			}
+			service := NewFileService(storage.NewLocalStorage(t.TempDir()), antivirus.NewNoopScanner(), fileRepo, userRepo, repotest.UnitOfWork{}, Quota{}, zap.NewNop())
+
+			err := service.DeleteFile(context.Background(), avatar.ID, tt.userID)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("DeleteFile: %v", err)
+				}
+				if len(fileRepo.detached) != 1 {
+					t.Fatalf("detached %d files, want 1", len(fileRepo.detached))
+				}
+				return
+			}
+			var domainErr *domainerrors.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Type != tt.wantErr {
+				t.Fatalf("DeleteFile error = %v, want %s", err, tt.wantErr)
+			}
+			if len(fileRepo.detached) != 0 {
+				t.Fatalf("file detached despite error")
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package file
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/infrastructure/storage"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// uploadTTL сколько живет незавершенная загрузка
+const uploadTTL = 24 * time.Hour
+
+// assemblyLease сколько сборку держит один запрос. Больше таймаута антивируса, чтобы сборку
+// не занял повтор, пока первый запрос еще работает, но после падения запроса сборка освобождается
+const assemblyLease = 15 * time.Minute
+
+var uploadEntityTypes = []string{"task", "comment", "template"}
+
+// UploadService возобновляемые загрузки (протокол в стиле tus). Каждая часть сохраняется
+// отдельным объектом в FileStorage, после получения всех байт части собираются в один файл
+type UploadService struct {
+	files      *FileService
+	uploadRepo repository.UploadRepository
+	logger     *zap.Logger
+}
+
+func NewUploadService(files *FileService, uploadRepo repository.UploadRepository, logger *zap.Logger) *UploadService {
+	return &UploadService{
+		files:      files,
+		uploadRepo: uploadRepo,
+		logger:     logger,
+	}
+}
+
+// CreateUpload проверяет размер и тип файла до получения данных и создает загрузку
+func (s *UploadService) CreateUpload(ctx context.Context, input UploadInput) (*model.Upload, error) {
+	if !slices.Contains(uploadEntityTypes, input.EntityType) {
+		return nil, domainerrors.NewValidationError("Invalid entity type").WithMeta("allowed", uploadEntityTypes)
+	}
+	fileName := sanitizeFileName(input.FileName)
+	if _, err := s.files.validateMeta(fileName, input.MimeType, input.Length, UploadProfile); err != nil {
+		return nil, err
+	}
+
+	upload := &model.Upload{
+		ID:         uuid.New().String(),
+		UploaderID: input.UploaderID,
+		EntityType: input.EntityType,
+		FileName:   fileName,
+		MimeType:   input.MimeType,
+		Length:     input.Length,
+		Status:     model.UploadStatusPending,
+		ExpiresAt:  time.Now().Add(uploadTTL),
+	}
+	// загрузка сразу занимает заявленный размер в квоте пользователя. Компания станет известна
+	// только при прикреплении, ее квота проверяется там
+	err := s.files.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.files.LockQuota(ctx, 0, input.UploaderID); err != nil {
+			return err
+		}
+		if err := s.files.CheckQuota(ctx, 0, input.UploaderID, input.Length); err != nil {
+			return err
+		}
+		created, err := s.uploadRepo.Create(ctx, upload)
+		upload = created
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to create upload", zap.Error(err))
+		return nil, err
+	}
+	return upload, nil
+}
+
+// GetUpload загрузки видны только загрузившему их пользователю
+func (s *UploadService) GetUpload(ctx context.Context, id string, userID uint) (*model.Upload, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, domainerrors.NewNotFoundError("upload", id)
+	}
+	upload, err := s.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UploaderID != userID || upload.IsExpired() {
+		return nil, domainerrors.NewNotFoundError("upload", id)
+	}
+	return upload, nil
+}
+
+// WriteChunk принимает часть файла с указанного смещения. Смещение должно совпадать с уже принятым объемом.
+// Часть с последними байтами сразу собирает файл, см. complete
+func (s *UploadService) WriteChunk(ctx context.Context, id string, offset int64, chunk io.Reader, userID uint) (*model.Upload, error) {
+	upload, err := s.GetUpload(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != model.UploadStatusPending {
+		return nil, domainerrors.NewConflictError("upload is already finished").WithMeta("status", upload.Status)
+	}
+	if offset != upload.Offset {
+		return nil, domainerrors.NewConflictError("upload offset mismatch").WithMeta("offset", upload.Offset)
+	}
+
+	counter := &countingReader{r: io.LimitReader(chunk, upload.Length-upload.Offset)}
+	partPath := fmt.Sprintf("uploads/%s/%d-%s", upload.ID, upload.Offset, uuid.New().String())
+	if err := s.files.storage.Save(ctx, counter, partPath); err != nil {
+		s.logger.Error("failed to save upload part", zap.String("uploadID", upload.ID), zap.Error(err))
+		return nil, err
+	}
+	if counter.n == 0 {
+		s.deletePaths(ctx, partPath)
+		// все байты уже приняты, но сборка не удалась: пустой PATCH повторяет ее
+		if upload.Offset == upload.Length {
+			if err := s.complete(ctx, upload); err != nil {
+				return nil, err
+			}
+		}
+		return upload, nil
+	}
+
+	part := &model.UploadPart{Path: partPath, Offset: upload.Offset, Size: counter.n}
+	appended, err := s.uploadRepo.AppendPart(ctx, upload, part)
+	if err != nil {
+		s.deletePaths(ctx, partPath)
+		return nil, err
+	}
+	if !appended {
+		s.deletePaths(ctx, partPath)
+		return nil, domainerrors.NewConflictError("upload offset mismatch")
+	}
+
+	if upload.Offset == upload.Length {
+		if err := s.complete(ctx, upload); err != nil {
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// AttachUploads возвращает файлы завершенных загрузок пользователя и помечает их прикрепленными.
+// Вызывается внутри транзакции создания задачи или комментария
+func (s *UploadService) AttachUploads(ctx context.Context, fileIDs []string, userID uint) ([]*model.File, error) {
+	if len(fileIDs) == 0 {
+		return nil, nil
+	}
+	fileIDs = slices.Compact(slices.Sorted(slices.Values(fileIDs)))
+
+	uploads, err := s.uploadRepo.GetCompletedByIDs(ctx, fileIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(uploads) != len(fileIDs) {
+		found := make([]string, 0, len(uploads))
+		for _, upload := range uploads {
+			found = append(found, upload.File.ID)
+		}
+		missing := slices.DeleteFunc(slices.Clone(fileIDs), func(id string) bool {
+			return slices.Contains(found, id)
+		})
+		return nil, domainerrors.NewValidationError("Uploaded files not found").WithMeta("fileIds", missing)
+	}
+
+	ids := make([]string, 0, len(uploads))
+	files := make([]*model.File, 0, len(uploads))
+	for _, upload := range uploads {
+		ids = append(ids, upload.ID)
+		files = append(files, upload.File)
+	}
+	if err := s.uploadRepo.MarkAttached(ctx, ids); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// complete собирает части в итоговый файл, проверяет содержимое и антивирусом. Загрузка отклоняется
+// только если содержимое не прошло проверку, зараженный файл сохраняется в карантине.
+// Сборка и проверка идут внутри последнего PATCH, поэтому его время растет с размером файла
+// и ограничено таймаутом антивируса. Клиент ждет ответа, при 5xx повторяет пустой PATCH,
+// а пока сборку держит другой запрос, получает конфликт.
+// Части удаляются только после того, как итог сохранен, иначе загрузку нельзя было бы собрать повторно
+func (s *UploadService) complete(ctx context.Context, upload *model.Upload) error {
+	claimed, err := s.uploadRepo.ClaimAssembly(ctx, upload.ID, time.Now().Add(assemblyLease))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return domainerrors.NewConflictError("upload is being assembled or already finished")
+	}
+
+	fileID := uuid.New().String()
+	filePath := s.files.generateFilePath(upload.EntityType, fileID, upload.FileName)
+
+	parts := slices.Clone(upload.Parts)
+	slices.SortFunc(parts, func(a, b *model.UploadPart) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+	reader := &partsReader{ctx: ctx, storage: s.files.storage, parts: parts}
+	err = s.files.storage.Save(ctx, reader, filePath)
+	reader.Close()
+	if err != nil {
+		s.logger.Error("failed to assemble upload", zap.String("uploadID", upload.ID), zap.Error(err))
+		s.deletePaths(ctx, filePath)
+		s.releaseAssembly(ctx, upload)
+		return domainerrors.NewInternalError("failed to assemble upload", err)
+	}
+
+	newFile, err := s.checkAssembled(ctx, upload, fileID, filePath)
+	if err != nil {
+		s.deletePaths(ctx, filePath)
+		if domainErr := domainerrors.GetDomainError(err); domainErr == nil || domainErr.Type != domainerrors.ErrorTypeValidation {
+			// хранилище или антивирус недоступны: части и статус остаются, пустой PATCH повторяет сборку
+			s.logger.Error("failed to check upload", zap.String("uploadID", upload.ID), zap.Error(err))
+			s.releaseAssembly(ctx, upload)
+			return domainerrors.NewInternalError("failed to check upload", err)
+		}
+		rejected := *upload
+		rejected.Status = model.UploadStatusRejected
+		rejected.Parts = nil
+		if finishErr := s.finish(ctx, &rejected); finishErr != nil {
+			s.logger.Error("failed to reject upload", zap.String("uploadID", upload.ID), zap.Error(finishErr))
+			return err
+		}
+		s.deleteParts(ctx, upload)
+		*upload = rejected
+		return err
+	}
+
+	completed := *upload
+	completed.File = newFile
+	completed.Status = model.UploadStatusCompleted
+	completed.Parts = nil
+	// после сборки файл нужно прикрепить за uploadTTL, иначе его удалит сборщик мусора
+	completed.ExpiresAt = time.Now().Add(uploadTTL)
+	if err := s.finish(ctx, &completed); err != nil {
+		s.deletePaths(ctx, filePath)
+		return err
+	}
+	s.deleteParts(ctx, upload)
+	*upload = completed
+
+	s.logger.Info("upload completed",
+		zap.String("uploadID", upload.ID),
+		zap.String("fileID", newFile.ID),
+		zap.Int64("size", upload.Length),
+	)
+	return nil
+}
+
+// finish сохраняет итог сборки. Если загрузку уже завершил другой запрос (сборка заняла больше
+// assemblyLease), итог не сохраняется и возвращается конфликт
+func (s *UploadService) finish(ctx context.Context, upload *model.Upload) error {
+	finished, err := s.uploadRepo.Finish(ctx, upload)
+	if err != nil {
+		s.releaseAssembly(ctx, upload)
+		return err
+	}
+	if !finished {
+		return domainerrors.NewConflictError("upload is already finished")
+	}
+	return nil
+}
+
+func (s *UploadService) releaseAssembly(ctx context.Context, upload *model.Upload) {
+	if err := s.uploadRepo.ReleaseAssembly(context.WithoutCancel(ctx), upload.ID); err != nil {
+		s.logger.Error("failed to release upload assembly", zap.String("uploadID", upload.ID), zap.Error(err))
+	}
+}
+
+func (s *UploadService) checkAssembled(ctx context.Context, upload *model.Upload, fileID string, filePath string) (*model.File, error) {
+	content, err := s.files.storage.Open(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	extMime := s.files.mimeTypeByExtension(upload.FileName)
+	if err := s.files.validateContent(upload.FileName, extMime, content); err != nil {
+		return nil, err
+	}
+	scan, err := s.files.scan(ctx, upload.FileName, content)
+	if err != nil {
+		return nil, err
+	}
+
+	newFile := model.NewFile(fileID, upload.FileName, filePath, upload.Length, extMime, upload.UploaderID)
+	newFile.ScanStatus = scanStatus(scan)
+	if scan.Infected {
+		s.logger.Warn("infected upload quarantined",
+			zap.String("uploadID", upload.ID),
+			zap.String("signature", scan.Signature),
+		)
+		newFile.ScanSignature = scan.Signature
+	}
+	return newFile, nil
+}
+
+func (s *UploadService) deleteParts(ctx context.Context, upload *model.Upload) {
+	paths := make([]string, 0, len(upload.Parts))
+	for _, part := range upload.Parts {
+		paths = append(paths, part.Path)
+	}
+	s.deletePaths(ctx, paths...)
+}
+
+func (s *UploadService) deletePaths(ctx context.Context, paths ...string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, path := range paths {
+		if err := s.files.storage.Delete(ctx, path); err != nil {
+			s.logger.Error("failed to delete upload data", zap.String("path", path), zap.Error(err))
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// partsReader читает части загрузки подряд, открывая следующую только после окончания предыдущей
+type partsReader struct {
+	ctx     context.Context
+	storage storage.FileStorage
+	parts   []*model.UploadPart
+	current io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			part, err := r.storage.Open(r.ctx, r.parts[0].Path)
+			if err != nil {
+				return 0, err
+			}
+			r.current = part
+			r.parts = r.parts[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
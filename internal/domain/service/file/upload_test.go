@@ -0,0 +1,480 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"io"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/repository/repotest"
+	"rttask/internal/infrastructure/antivirus"
+	"rttask/internal/infrastructure/storage"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fakeUploadRepo условные обновления выполняются под mu, как одна строка в базе
+type fakeUploadRepo struct {
+	repository.UploadRepository
+	mu        sync.Mutex
+	uploads   map[string]*model.Upload
+	finishErr error
+}
+
+func (r *fakeUploadRepo) GetByID(ctx context.Context, id string) (*model.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upload, ok := r.uploads[id]
+	if !ok {
+		return nil, domainerrors.NewNotFoundError("upload", id)
+	}
+	copied := *upload
+	return &copied, nil
+}
+
+func (r *fakeUploadRepo) AppendPart(ctx context.Context, upload *model.Upload, part *model.UploadPart) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.uploads[upload.ID]
+	if stored.Offset != upload.Offset {
+		return false, nil
+	}
+	upload.Parts = append(upload.Parts, part)
+	upload.Offset += part.Size
+	stored.Parts, stored.Offset = upload.Parts, upload.Offset
+	return true, nil
+}
+
+func (r *fakeUploadRepo) ClaimAssembly(ctx context.Context, id string, until time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.uploads[id]
+	if stored.Status != model.UploadStatusPending || stored.Offset != stored.Length ||
+		(stored.AssemblingUntil != nil && stored.AssemblingUntil.After(time.Now())) {
+		return false, nil
+	}
+	stored.AssemblingUntil = &until
+	return true, nil
+}
+
+func (r *fakeUploadRepo) ReleaseAssembly(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored := r.uploads[id]; stored.Status == model.UploadStatusPending {
+		stored.AssemblingUntil = nil
+	}
+	return nil
+}
+
+func (r *fakeUploadRepo) Finish(ctx context.Context, upload *model.Upload) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.finishErr != nil {
+		return false, r.finishErr
+	}
+	stored := r.uploads[upload.ID]
+	if stored.Status != model.UploadStatusPending {
+		return false, nil
+	}
+	stored.Status, stored.File, stored.Parts, stored.ExpiresAt = upload.Status, upload.File, upload.Parts, upload.ExpiresAt
+	stored.AssemblingUntil = nil
+	return true, nil
+}
+
+// stored состояние загрузки в репозитории
+func (r *fakeUploadRepo) stored(id string) model.Upload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.uploads[id]
+}
+
+// fakeScanner отвечает ошибкой failures раз, затем результатом result
+type fakeScanner struct {
+	failures int
+	err      error
+	result   antivirus.ScanResult
+}
+
+func (s *fakeScanner) Scan(ctx context.Context, file io.Reader) (*antivirus.ScanResult, error) {
+	if _, err := io.Copy(io.Discard, file); err != nil {
+		return nil, err
+	}
+	if s.failures > 0 {
+		s.failures--
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, errors.New("clamd: i/o timeout")
+	}
+	result := s.result
+	return &result, nil
+}
+
+type uploadFixture struct {
+	service *UploadService
+	repo    *fakeUploadRepo
+	store   storage.FileStorage
+	upload  *model.Upload
+}
+
+func newUploadFixture(t *testing.T, scanner antivirus.FileScanner, fileName string, content string) *uploadFixture {
+	t.Helper()
+	store := storage.NewLocalStorage(t.TempDir())
+	upload := &model.Upload{
+		ID:         "0d8f5f5e-8d8b-4c38-9a59-5b0c3c1f2a10",
+		UploaderID: 1,
+		EntityType: "task",
+		FileName:   fileName,
+		Length:     int64(len(content)),
+		Status:     model.UploadStatusPending,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	repo := &fakeUploadRepo{uploads: map[string]*model.Upload{upload.ID: upload}}
+	files := NewFileService(store, scanner, &fakeFileRepo{}, &repotest.UserRepo{}, repotest.UnitOfWork{}, Quota{}, zap.NewNop())
+	return &uploadFixture{service: NewUploadService(files, repo, zap.NewNop()), repo: repo, store: store, upload: upload}
+}
+
+// storedPaths пути всех объектов хранилища: части и собранные файлы
+func (f *uploadFixture) storedPaths(t *testing.T) []string {
+	t.Helper()
+	var paths []string
+	err := f.store.Walk(context.Background(), func(path string, info storage.FileInfo) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	return paths
+}
+
+func (f *uploadFixture) writeAll(t *testing.T, content string) (*model.Upload, error) {
+	t.Helper()
+	half := len(content) / 2
+	if _, err := f.service.WriteChunk(context.Background(), f.upload.ID, 0, strings.NewReader(content[:half]), 1); err != nil {
+		t.Fatalf("first chunk: %v", err)
+	}
+	return f.service.WriteChunk(context.Background(), f.upload.ID, int64(half), strings.NewReader(content[half:]), 1)
+}
+
+func TestUploadCompleteKeepsPartsOnScannerFailure(t *testing.T) {
+	content := strings.Repeat("plain text line\n", 64)
+	f := newUploadFixture(t, &fakeScanner{failures: 1}, "notes.txt", content)
+
+	_, err := f.writeAll(t, content)
+	var domainErr *domainerrors.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Type != domainerrors.ErrorTypeInternal {
+		t.Fatalf("last chunk error = %v, want internal error", err)
+	}
+	stored := f.repo.stored(f.upload.ID)
+	if stored.Status != model.UploadStatusPending || len(stored.Parts) != 2 || stored.Offset != stored.Length {
+		t.Fatalf("upload after failure = status %s, %d parts, offset %d, want pending with both parts", stored.Status, len(stored.Parts), stored.Offset)
+	}
+	if paths := f.storedPaths(t); len(paths) != 2 {
+		t.Fatalf("storage after failure = %v, want only the two parts", paths)
+	}
+
+	// повтор последнего PATCH без данных собирает файл заново
+	upload, err := f.service.WriteChunk(context.Background(), f.upload.ID, stored.Length, strings.NewReader(""), 1)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if upload.Status != model.UploadStatusCompleted || upload.File == nil || upload.File.ScanStatus != model.ScanStatusClean {
+		t.Fatalf("upload after retry = %+v, want completed clean file", upload)
+	}
+	if paths := f.storedPaths(t); len(paths) != 1 || paths[0] != upload.File.Path {
+		t.Fatalf("storage after retry = %v, want only %s", paths, upload.File.Path)
+	}
+}
+
+func TestUploadCompleteRejectsInvalidContent(t *testing.T) {
+	content := strings.Repeat("not a pdf at all\n", 64)
+	f := newUploadFixture(t, &fakeScanner{}, "report.pdf", content)
+
+	_, err := f.writeAll(t, content)
+	var domainErr *domainerrors.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Type != domainerrors.ErrorTypeValidation {
+		t.Fatalf("last chunk error = %v, want validation error", err)
+	}
+	stored := f.repo.stored(f.upload.ID)
+	if stored.Status != model.UploadStatusRejected || len(stored.Parts) != 0 {
+		t.Fatalf("upload = status %s, %d parts, want rejected without parts", stored.Status, len(stored.Parts))
+	}
+	if paths := f.storedPaths(t); len(paths) != 0 {
+		t.Fatalf("storage after reject = %v, want empty", paths)
+	}
+}
+
+func TestUploadCompleteRejectsFileOverScanLimit(t *testing.T) {
+	content := strings.Repeat("plain text line\n", 64)
+	f := newUploadFixture(t, &fakeScanner{failures: 1, err: antivirus.ErrStreamTooLarge}, "notes.txt", content)
+
+	_, err := f.writeAll(t, content)
+	var domainErr *domainerrors.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Type != domainerrors.ErrorTypeValidation {
+		t.Fatalf("last chunk error = %v, want validation error", err)
+	}
+	if stored := f.repo.stored(f.upload.ID); stored.Status != model.UploadStatusRejected {
+		t.Fatalf("upload status = %s, want rejected: a retry would hit the same clamd limit", stored.Status)
+	}
+}
+
+func TestUploadCompleteQuarantinesInfectedFile(t *testing.T) {
+	content := strings.Repeat("X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR\n", 8)
+	f := newUploadFixture(t, &fakeScanner{result: antivirus.ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}}, "eicar.txt", content)
+
+	upload, err := f.writeAll(t, content)
+	if err != nil {
+		t.Fatalf("last chunk: %v", err)
+	}
+	if upload.Status != model.UploadStatusCompleted || !upload.File.IsQuarantined() || upload.File.ScanSignature != "Eicar-Test-Signature" {
+		t.Fatalf("upload = %+v, file %+v, want completed quarantined file", upload, upload.File)
+	}
+}
+
+func TestUploadCompleteScanStatus(t *testing.T) {
+	content := strings.Repeat("plain text line\n", 64)
+	tests := []struct {
+		name    string
+		scanner antivirus.FileScanner
+		want    model.ScanStatus
+	}{
+		{name: "scanned", scanner: &fakeScanner{}, want: model.ScanStatusClean},
+		{name: "antivirus disabled", scanner: antivirus.NewNoopScanner(), want: model.ScanStatusSkipped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUploadFixture(t, tt.scanner, "notes.txt", content)
+
+			upload, err := f.writeAll(t, content)
+			if err != nil {
+				t.Fatalf("last chunk: %v", err)
+			}
+			if upload.File.ScanStatus != tt.want || upload.File.IsQuarantined() {
+				t.Fatalf("scan status = %q, want %q", upload.File.ScanStatus, tt.want)
+			}
+		})
+	}
+}
+
+// blockingScanner первая проверка ждет release, чтобы в это время прошел другой запрос
+type blockingScanner struct {
+	blocked atomic.Bool
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingScanner() *blockingScanner {
+	return &blockingScanner{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (s *blockingScanner) Scan(ctx context.Context, file io.Reader) (*antivirus.ScanResult, error) {
+	if _, err := io.Copy(io.Discard, file); err != nil {
+		return nil, err
+	}
+	if s.blocked.CompareAndSwap(false, true) {
+		close(s.started)
+		<-s.release
+	}
+	return &antivirus.ScanResult{}, nil
+}
+
+// finishInBackground отправляет последнюю часть и ждет, пока сборка дойдет до антивируса
+func (f *uploadFixture) finishInBackground(t *testing.T, scanner *blockingScanner, content string) <-chan error {
+	t.Helper()
+	half := len(content) / 2
+	if _, err := f.service.WriteChunk(context.Background(), f.upload.ID, 0, strings.NewReader(content[:half]), 1); err != nil {
+		t.Fatalf("first chunk: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := f.service.WriteChunk(context.Background(), f.upload.ID, int64(half), strings.NewReader(content[half:]), 1)
+		done <- err
+	}()
+	<-scanner.started
+	return done
+}
+
+func TestUploadCompleteRunsOnce(t *testing.T) {
+	content := strings.Repeat("plain text line\n", 64)
+	scanner := newBlockingScanner()
+	f := newUploadFixture(t, scanner, "notes.txt", content)
+	done := f.finishInBackground(t, scanner, content)
+
+	// клиент не дождался ответа и повторил пустой PATCH, пока первая сборка еще идет
+	_, err := f.service.WriteChunk(context.Background(), f.upload.ID, int64(len(content)), strings.NewReader(""), 1)
+	var domainErr *domainerrors.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Type != domainerrors.ErrorTypeConflict {
+		t.Fatalf("retry during assembly error = %v, want conflict", err)
+	}
+
+	close(scanner.release)
+	if err := <-done; err != nil {
+		t.Fatalf("last chunk: %v", err)
+	}
+	stored := f.repo.stored(f.upload.ID)
+	if stored.Status != model.UploadStatusCompleted || stored.AssemblingUntil != nil {
+		t.Fatalf("upload = status %s, assembling until %v, want completed", stored.Status, stored.AssemblingUntil)
+	}
+	if paths := f.storedPaths(t); len(paths) != 1 || paths[0] != stored.File.Path {
+		t.Fatalf("storage = %v, want only %s", paths, stored.File.Path)
+	}
+}
+
+func TestUploadLateCompletionKeepsAttachedUpload(t *testing.T) {
+	content := strings.Repeat("plain text line\n", 64)
+	scanner := newBlockingScanner()
+	f := newUploadFixture(t, scanner, "notes.txt", content)
+	done := f.finishInBackground(t, scanner, content)
+
+	// первая сборка зависла дольше assemblyLease, повтор собирает файл, и его прикрепляют к задаче
+	f.repo.mu.Lock()
+	expired := time.Now().Add(-time.Second)
+	f.repo.uploads[f.upload.ID].AssemblingUntil = &expired
+	f.repo.mu.Unlock()
+	retried, err := f.service.WriteChunk(context.Background(), f.upload.ID, int64(len(content)), strings.NewReader(""), 1)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	f.repo.mu.Lock()
+	f.repo.uploads[f.upload.ID].Status = model.UploadStatusAttached
+	f.repo.mu.Unlock()
+
+	close(scanner.release)
+	var domainErr *domainerrors.DomainError
+	if err := <-done; !errors.As(err, &domainErr) || domainErr.Type != domainerrors.ErrorTypeConflict {
+		t.Fatalf("late completion error = %v, want conflict", err)
+	}
+	stored := f.repo.stored(f.upload.ID)
+	if stored.Status != model.UploadStatusAttached || stored.File.ID != retried.File.ID {
+		t.Fatalf("upload = status %s, file %s, want attached %s", stored.Status, stored.File.ID, retried.File.ID)
+	}
+	if paths := f.storedPaths(t); len(paths) != 1 || paths[0] != retried.File.Path {
+		t.Fatalf("storage = %v, want only the attached file %s", paths, retried.File.Path)
+	}
+}
+
+func TestUploadCompleteKeepsPartsWhenFinishFails(t *testing.T) {
+	content := strings.Repeat("plain text line\n", 64)
+	f := newUploadFixture(t, &fakeScanner{}, "notes.txt", content)
+	f.repo.finishErr = errors.New("connection reset")
+
+	if _, err := f.writeAll(t, content); err == nil {
+		t.Fatal("last chunk succeeded, want finish error")
+	}
+	stored := f.repo.stored(f.upload.ID)
+	if stored.Status != model.UploadStatusPending || len(stored.Parts) != 2 || stored.AssemblingUntil != nil {
+		t.Fatalf("upload = status %s, %d parts, assembling until %v, want pending with parts and no claim", stored.Status, len(stored.Parts), stored.AssemblingUntil)
+	}
+	if paths := f.storedPaths(t); len(paths) != 2 {
+		t.Fatalf("storage = %v, want only the two parts", paths)
+	}
+
+	f.repo.finishErr = nil
+	upload, err := f.service.WriteChunk(context.Background(), f.upload.ID, int64(len(content)), strings.NewReader(""), 1)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if upload.Status != model.UploadStatusCompleted {
+		t.Fatalf("upload after retry = %s, want completed", upload.Status)
+	}
+}
+
+// quotaDB созданные загрузки видны после фиксации транзакции, блокировка квоты держится до ее конца
+type quotaDB struct {
+	mu        sync.Mutex
+	userLock  sync.Mutex
+	committed int64
+	uploads   int
+}
+
+type quotaFileRepo struct {
+	repository.FileRepository
+	db *quotaDB
+}
+
+func (r *quotaFileRepo) LockQuota(ctx context.Context, companyID uint, userID uint) error {
+	if userID != 0 {
+		repotest.LockUntilEnd(ctx, &r.db.userLock)
+	}
+	return nil
+}
+
+func (r *quotaFileRepo) GetUserUsage(ctx context.Context, userID uint) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return r.db.committed, nil
+}
+
+type quotaUploadRepo struct {
+	repository.UploadRepository
+	db *quotaDB
+}
+
+func (r *quotaUploadRepo) Create(ctx context.Context, upload *model.Upload) (*model.Upload, error) {
+	// окно между проверкой квоты и фиксацией, в которое без блокировки попадают параллельные запросы
+	time.Sleep(time.Millisecond)
+	repotest.AfterTx(ctx, func(committed bool) {
+		if !committed {
+			return
+		}
+		r.db.mu.Lock()
+		defer r.db.mu.Unlock()
+		r.db.committed += upload.Length
+		r.db.uploads++
+	})
+	return upload, nil
+}
+
+func TestCreateUploadReservesUserQuotaUnderLock(t *testing.T) {
+	const (
+		quota    = 100
+		length   = 40
+		parallel = 10
+	)
+	db := &quotaDB{}
+	files := NewFileService(storage.NewLocalStorage(t.TempDir()), &fakeScanner{}, &quotaFileRepo{db: db}, &repotest.UserRepo{}, repotest.UnitOfWork{}, Quota{UserBytes: quota}, zap.NewNop())
+	service := NewUploadService(files, &quotaUploadRepo{db: db}, zap.NewNop())
+
+	var (
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+		rejected atomic.Int32
+	)
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := service.CreateUpload(context.Background(), UploadInput{
+				Length:     length,
+				FileName:   "notes.txt",
+				MimeType:   "text/plain",
+				EntityType: "task",
+				UploaderID: 1,
+			})
+			var domainErr *domainerrors.DomainError
+			if errors.As(err, &domainErr) && domainErr.Type == domainerrors.ErrorTypeValidation {
+				rejected.Add(1)
+			} else if err != nil {
+				t.Errorf("CreateUpload: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	// заявленный размер занимает квоту сразу, поэтому помещаются ровно две загрузки из десяти
+	if db.uploads != quota/length || db.committed > quota {
+		t.Fatalf("created %d uploads with %d bytes reserved, want %d within quota %d", db.uploads, db.committed, quota/length, quota)
+	}
+	if int(rejected.Load()) != parallel-quota/length {
+		t.Fatalf("rejected %d uploads, want %d", rejected.Load(), parallel-quota/length)
+	}
+}
@@ -33,13 +33,13 @@ func (s *InviteService) CreateInvite(ctx context.Context, input InviteInput, use
 	s.logger.Info("start InviteService.CreateInvite", zap.Any("input", input))
 
 	// Проверка пользоваеля
-	err := s.validateUser(ctx, userID, rbac.RoleCreate)
+	inviter, err := s.validateUser(ctx, userID, rbac.RoleCreate)
 	if err != nil {
 		return nil, err
 	}
 
 	// Виладиция ролей
-	roles, err := s.validateRoles(ctx, input)
+	roles, err := s.validateRoles(ctx, input, inviter)
 	if err != nil {
 		return nil, err
 	}
@@ -72,19 +72,20 @@ func (s *InviteService) GetAllInvites(ctx context.Context, userID uint, params v
 	return invites, nil
 }
 
-func (s *InviteService) validateUser(ctx context.Context, userID uint, permission rbac.Permission) error {
+func (s *InviteService) validateUser(ctx context.Context, userID uint, permission rbac.Permission) (*model.User, error) {
 	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
 	if err != nil {
-		return domainerrors.NewUnauthorizedError("Not authorized")
+		return nil, domainerrors.NewUnauthorizedError("Not authorized")
 	}
 	can := user.CanAll(permission)
 	if !can {
-		return domainerrors.NewUnauthorizedError("Not allowed") // Todo обработку прав доступа
+		return nil, domainerrors.NewUnauthorizedError("Not allowed") // Todo обработку прав доступа
 	}
-	return nil
+	return user, nil
 }
 
-func (s *InviteService) validateRoles(ctx context.Context, input InviteInput) ([]rbac.Role, error) {
+// validateRoles проверяет что роли существуют, активны и не дают прав больше, чем есть у пригласившего
+func (s *InviteService) validateRoles(ctx context.Context, input InviteInput, inviter *model.User) ([]rbac.Role, error) {
 	if len(input.RolesIDs) > 0 {
 		roles, err := s.roleRepo.GetByIDs(ctx, input.RolesIDs)
 		if err != nil {
@@ -98,6 +99,13 @@ func (s *InviteService) validateRoles(ctx context.Context, input InviteInput) ([
 				return nil, domainerrors.NewValidationError(fmt.Sprintf("Role %s is not active", role.Name))
 			}
 		}
+		if missing := inviter.MissingPermissions(roles...); len(missing) > 0 {
+			s.logger.Warn("invite grants permissions the inviter does not have",
+				zap.Uint("userID", inviter.ID),
+				zap.Any("permissions", missing),
+			)
+			return nil, domainerrors.NewPrivilegeEscalationError(rbac.PermissionNames(missing))
+		}
 		return roles, nil
 	}
 	return nil, domainerrors.NewValidationError("Invalid role IDs")
@@ -0,0 +1,77 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/repository/repotest"
+	"testing"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type fakeInviteRepo struct {
+	repository.InviteRepository
+	created []*model.InviteLink
+}
+
+func (r *fakeInviteRepo) Create(ctx context.Context, invite *model.InviteLink) (*model.InviteLink, error) {
+	r.created = append(r.created, invite)
+	return invite, nil
+}
+
+const (
+	inviterID = 1
+
+	viewerRoleID  = 10
+	managerRoleID = 11
+)
+
+func TestCreateInviteRejectsPrivilegeEscalation(t *testing.T) {
+	roles := map[uint]rbac.Role{
+		viewerRoleID:  {Model: gorm.Model{ID: viewerRoleID}, Name: "viewer", Permissions: []rbac.Permission{rbac.TaskView}, IsActive: true},
+		managerRoleID: {Model: gorm.Model{ID: managerRoleID}, Name: "manager", Permissions: []rbac.Permission{rbac.TaskView, rbac.RoleAssign, rbac.UserDelete}, IsActive: true},
+	}
+	tests := []struct {
+		name            string
+		permissions     []rbac.Permission
+		rolesIDs        []uint
+		wantPermissions []string // права из Meta ошибки, nil если приглашение создано
+	}{
+		{name: "own permissions", permissions: []rbac.Permission{rbac.RoleCreate, rbac.TaskView}, rolesIDs: []uint{viewerRoleID}},
+		{name: "extra permissions", permissions: []rbac.Permission{rbac.RoleCreate, rbac.TaskView}, rolesIDs: []uint{viewerRoleID, managerRoleID}, wantPermissions: []string{"role:assign", "user:delete"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invites := &fakeInviteRepo{}
+			users := &repotest.UserRepo{Users: map[uint]*model.User{inviterID: repotest.NewUser(inviterID, tt.permissions...)}}
+			service := NewInviteService(invites, users, &repotest.RoleRepo{Roles: roles}, zap.NewNop())
+
+			_, err := service.CreateInvite(context.Background(), NewInviteInput(nil, tt.rolesIDs), inviterID)
+			if tt.wantPermissions == nil {
+				if err != nil {
+					t.Fatalf("CreateInvite: %v", err)
+				}
+				if len(invites.created) != 1 {
+					t.Errorf("created %d invites, want 1", len(invites.created))
+				}
+				return
+			}
+			var domainErr *domainerrors.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Type != domainerrors.ErrorTypeForbidden {
+				t.Fatalf("CreateInvite error = %v, want forbidden", err)
+			}
+			if got := domainErr.Meta["permissions"]; !reflect.DeepEqual(got, tt.wantPermissions) {
+				t.Errorf("permissions = %v, want %v", got, tt.wantPermissions)
+			}
+			if len(invites.created) != 0 {
+				t.Errorf("invite created despite escalation")
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package mention
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/valueobject"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// MentionService разбирает упоминания @handle в описаниях задач и комментариях.
+// Handle e-mail участника компании или его часть до @, если она у участников не повторяется
+type MentionService struct {
+	mentionRepo repository.MentionRepository
+	userRepo    repository.UserRepository
+	logger      *zap.Logger
+}
+
+func NewMentionService(mentionRepo repository.MentionRepository, userRepo repository.UserRepository, logger *zap.Logger) *MentionService {
+	return &MentionService{
+		mentionRepo: mentionRepo,
+		userRepo:    userRepo,
+		logger:      logger,
+	}
+}
+
+// Resolve упоминания в text среди участников компании. Упоминание не участника или части e-mail,
+// общей для нескольких участников, ошибка валидации со списком таких упоминаний
+func (s *MentionService) Resolve(ctx context.Context, companyID uint, text string) ([]*model.Mention, error) {
+	mentions, unknown, ambiguous, err := s.resolve(ctx, companyID, text)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		return nil, domainerrors.NewValidationError("Mentioned users are not company members").
+			WithMeta("mentions", unknown)
+	}
+	if len(ambiguous) > 0 {
+		return nil, domainerrors.NewValidationError("Mentions match several company members, use the full e-mail").
+			WithMeta("mentions", ambiguous)
+	}
+	return mentions, nil
+}
+
+// ResolveKnown как Resolve, но пропускает упоминания, которые не удалось разрешить. Для текстов,
+// проверенных раньше, из которых участники с тех пор могли уйти
+func (s *MentionService) ResolveKnown(ctx context.Context, companyID uint, text string) ([]*model.Mention, error) {
+	mentions, _, _, err := s.resolve(ctx, companyID, text)
+	return mentions, err
+}
+
+// Save заменяет упоминания источника их копиями и возвращает сохраненные копии, поэтому один
+// результат Resolve можно сохранить для нескольких задач. Вызывается внутри транзакции
+func (s *MentionService) Save(ctx context.Context, source model.MentionSource, sourceID uint, taskID uint, mentions []*model.Mention) ([]*model.Mention, error) {
+	saved := make([]*model.Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		saved = append(saved, &model.Mention{
+			TaskID: taskID,
+			UserID: mention.UserID,
+			User:   mention.User,
+			Offset: mention.Offset,
+			Length: mention.Length,
+		})
+	}
+	if err := s.mentionRepo.Replace(ctx, source, sourceID, saved); err != nil {
+		s.logger.Error("failed to save mentions", zap.String("source", string(source)), zap.Uint("sourceID", sourceID), zap.Error(err))
+		return nil, err
+	}
+	return saved, nil
+}
+
+// Delete убирает упоминания удаленного источника
+func (s *MentionService) Delete(ctx context.Context, source model.MentionSource, sourceID uint) error {
+	return s.mentionRepo.DeleteBySource(ctx, source, sourceID)
+}
+
+// FillTasks загружает упоминания в описаниях задач одним запросом
+func (s *MentionService) FillTasks(ctx context.Context, tasks ...*model.Task) error {
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	mentions, err := s.mentionRepo.ListBySources(ctx, model.MentionInTask, ids)
+	if err != nil {
+		s.logger.Error("failed to get task mentions", zap.Error(err))
+		return err
+	}
+	for _, task := range tasks {
+		task.Mentions = mentions[task.ID]
+	}
+	return nil
+}
+
+// FillComments загружает упоминания в комментариях одним запросом
+func (s *MentionService) FillComments(ctx context.Context, comments ...*model.Comment) error {
+	ids := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	mentions, err := s.mentionRepo.ListBySources(ctx, model.MentionInComment, ids)
+	if err != nil {
+		s.logger.Error("failed to get comment mentions", zap.Error(err))
+		return err
+	}
+	for _, comment := range comments {
+		comment.Mentions = mentions[comment.ID]
+	}
+	return nil
+}
+
+// resolve упоминания, которые удалось разрешить, и handles без участника или с несколькими участниками
+func (s *MentionService) resolve(ctx context.Context, companyID uint, text string) ([]*model.Mention, []string, []string, error) {
+	tokens := valueobject.ParseMentions(text)
+	if len(tokens) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	handles := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if !slices.Contains(handles, token.Handle) {
+			handles = append(handles, token.Handle)
+		}
+	}
+	members, err := s.userRepo.FindCompanyMembers(ctx, companyID, handles)
+	if err != nil {
+		s.logger.Error("failed to find mentioned members", zap.Error(err))
+		return nil, nil, nil, err
+	}
+
+	var unknown, ambiguous []string
+	resolved := make(map[string]*model.User, len(handles))
+	for _, handle := range handles {
+		matched := matchHandle(members, handle)
+		switch len(matched) {
+		case 0:
+			unknown = append(unknown, "@"+handle)
+		case 1:
+			resolved[handle] = matched[0]
+		default:
+			ambiguous = append(ambiguous, "@"+handle)
+		}
+	}
+
+	mentions := make([]*model.Mention, 0, len(tokens))
+	for _, token := range tokens {
+		user, ok := resolved[token.Handle]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, &model.Mention{
+			UserID: user.ID,
+			User:   user,
+			Offset: token.Offset,
+			Length: token.Length,
+		})
+	}
+	return mentions, unknown, ambiguous, nil
+}
+
+// matchHandle полный e-mail совпадает не больше чем с одним участником, часть до @ может с несколькими
+func matchHandle(members []*model.User, handle string) []*model.User {
+	var matched []*model.User
+	for _, member := range members {
+		email := strings.ToLower(member.Email)
+		if email == handle {
+			return []*model.User{member}
+		}
+		if local, _, _ := strings.Cut(email, "@"); local == handle {
+			matched = append(matched, member)
+		}
+	}
+	return matched
+}
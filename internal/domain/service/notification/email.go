@@ -0,0 +1,369 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/infrastructure/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EmailOptions AppURL адрес фронтенда для ссылок на задачи, Locale язык писем по умолчанию,
+// QueueSize сколько писем может ждать отправки
+type EmailOptions struct {
+	AppURL    string
+	Locale    string
+	QueueSize int
+}
+
+// EmailSettingsInput пустой Locale сбрасывает язык на язык по умолчанию, типы, которых нет в Modes, не меняются
+type EmailSettingsInput struct {
+	Locale string
+	Modes  map[model.NotificationType]model.EmailMode
+}
+
+// EmailService доставляет уведомления на почту: сразу, ежедневной сводкой или никак, по настройкам получателя.
+// Письма отправляются из своей очереди, чтобы не задерживать шину событий
+type EmailService struct {
+	emailRepo repository.EmailRepository
+	userRepo  repository.UserRepository
+	uow       repository.UnitOfWork
+	mailer    mail.Mailer
+	renderer  *mail.Renderer
+	opts      EmailOptions
+	queue     chan *model.Notification
+	logger    *zap.Logger
+}
+
+func NewEmailService(emailRepo repository.EmailRepository, userRepo repository.UserRepository, uow repository.UnitOfWork, mailer mail.Mailer, renderer *mail.Renderer, opts EmailOptions, logger *zap.Logger) *EmailService {
+	if !IsSupportedLocale(opts.Locale) {
+		opts.Locale = defaultLocale
+	}
+	return &EmailService{
+		emailRepo: emailRepo,
+		userRepo:  userRepo,
+		uow:       uow,
+		mailer:    mailer,
+		renderer:  renderer,
+		opts:      opts,
+		queue:     make(chan *model.Notification, max(opts.QueueSize, 1)),
+		logger:    logger,
+	}
+}
+
+// Subscribe письма строятся из уже сохраненных уведомлений
+func (s *EmailService) Subscribe(bus *event.Bus) {
+	bus.Subscribe(event.NotificationCreated, s.onNotificationCreated)
+}
+
+// Start отправляет письма из очереди до отмены контекста
+func (s *EmailService) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-s.queue:
+			s.deliver(ctx, notification)
+		}
+	}
+}
+
+// GetSettings настройки пользователя, для ненастроенных типов подставлены значения по умолчанию
+func (s *EmailService) GetSettings(ctx context.Context, userID uint) (*model.EmailSettings, error) {
+	settings, err := s.settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	modes := make(map[model.NotificationType]model.EmailMode, len(model.DefaultEmailModes))
+	for notificationType := range model.DefaultEmailModes {
+		modes[notificationType] = settings.Mode(notificationType)
+	}
+	settings.Modes = modes
+	return settings, nil
+}
+
+func (s *EmailService) UpdateSettings(ctx context.Context, userID uint, input EmailSettingsInput) (*model.EmailSettings, error) {
+	if input.Locale != "" && !IsSupportedLocale(input.Locale) {
+		return nil, domainerrors.NewValidationError("unsupported locale").WithMeta("locale", input.Locale)
+	}
+	for notificationType, mode := range input.Modes {
+		if _, ok := model.DefaultEmailModes[notificationType]; !ok {
+			return nil, domainerrors.NewValidationError("unknown notification type").WithMeta("type", notificationType)
+		}
+		if !slices.Contains(model.EmailModes, mode) {
+			return nil, domainerrors.NewValidationError("invalid email mode").WithMeta("mode", mode)
+		}
+	}
+
+	settings, err := s.emailRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &model.EmailSettings{UserID: userID}
+	}
+	if settings.Modes == nil {
+		settings.Modes = make(map[model.NotificationType]model.EmailMode, len(input.Modes))
+	}
+	for notificationType, mode := range input.Modes {
+		settings.Modes[notificationType] = mode
+	}
+	settings.Locale = input.Locale
+
+	if err := s.emailRepo.SaveSettings(ctx, settings); err != nil {
+		s.logger.Error("failed to save email settings", zap.Uint("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	return s.GetSettings(ctx, userID)
+}
+
+// RunDigest отправляет накопленные сводки. Сводка каждого пользователя собирается в своей транзакции
+// и удаляется только после отправки, поэтому при ошибке она уйдет в следующий раз
+func (s *EmailService) RunDigest(ctx context.Context) (int, error) {
+	userIDs, err := s.emailRepo.GetDigestUserIDs(ctx)
+	if err != nil {
+		s.logger.Error("failed to get digest users", zap.Error(err))
+		return 0, err
+	}
+
+	sent := 0
+	for _, userID := range userIDs {
+		ok, err := s.sendDigest(ctx, userID)
+		if err != nil {
+			s.logger.Error("failed to send digest", zap.Uint("userID", userID), zap.Error(err))
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// onNotificationCreated при переполненной очереди уведомление откладывается в сводку, а не теряется
+func (s *EmailService) onNotificationCreated(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.NotificationCreatedPayload)
+	if !ok {
+		return
+	}
+	select {
+	case s.queue <- payload.Notification:
+	default:
+		s.logger.Warn("email queue is full, postponing to digest", zap.Uint("notificationID", payload.Notification.ID))
+		s.addToDigest(context.WithoutCancel(ctx), payload.Notification)
+	}
+}
+
+func (s *EmailService) deliver(ctx context.Context, notification *model.Notification) {
+	settings, err := s.settings(ctx, notification.RecipientID)
+	if err != nil {
+		s.logger.Error("failed to get email settings", zap.Uint("userID", notification.RecipientID), zap.Error(err))
+		return
+	}
+
+	switch settings.Mode(notification.Type) {
+	case model.EmailInstant:
+		if err := s.sendInstant(ctx, notification, settings); err != nil {
+			s.logger.Error("failed to send email, postponing to digest",
+				zap.Uint("notificationID", notification.ID),
+				zap.Error(err),
+			)
+			s.addToDigest(ctx, notification)
+		}
+	case model.EmailDigest:
+		s.addToDigest(ctx, notification)
+	}
+}
+
+func (s *EmailService) sendInstant(ctx context.Context, notification *model.Notification, settings *model.EmailSettings) error {
+	recipient, err := s.userRepo.GetUserByID(ctx, notification.RecipientID)
+	if err != nil {
+		return err
+	}
+	locale := s.locale(settings)
+	subject, item := s.item(ctx, locale, notification, map[uint]string{})
+
+	text, html, err := s.renderer.Render("notification", s.templateData(localeFor(locale), recipient, subject, "", []mail.TemplateItem{item}))
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mail.Message{
+		To:      recipient.Email,
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+// sendDigest возвращает false, если сводку уже забрал другой экземпляр или в ней нечего отправлять
+func (s *EmailService) sendDigest(ctx context.Context, userID uint) (bool, error) {
+	sent := false
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		items, err := s.emailRepo.LockDigest(ctx, userID)
+		if err != nil || len(items) == 0 {
+			return err
+		}
+		settings, err := s.settings(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// уведомления, удаленные или выключенные после постановки в сводку, просто выбрасываются
+		ids := make([]uint, 0, len(items))
+		lines := make([]mail.TemplateItem, 0, len(items))
+		locale := s.locale(settings)
+		texts := localeFor(locale)
+		actors := map[uint]string{}
+		for _, item := range items {
+			ids = append(ids, item.ID)
+			if item.Notification == nil || settings.Mode(item.Notification.Type) == model.EmailOff {
+				continue
+			}
+			_, line := s.item(ctx, locale, item.Notification, actors)
+			lines = append(lines, line)
+		}
+
+		if len(lines) > 0 {
+			recipient, err := s.userRepo.GetUserByID(ctx, userID)
+			if err != nil {
+				return err
+			}
+			subject := fmt.Sprintf(texts.DigestSubject, len(lines))
+			text, html, err := s.renderer.Render("digest", s.templateData(texts, recipient, subject, texts.DigestIntro, lines))
+			if err != nil {
+				return err
+			}
+			if err := s.mailer.Send(ctx, mail.Message{To: recipient.Email, Subject: subject, Text: text, HTML: html}); err != nil {
+				return err
+			}
+			sent = true
+		}
+		return s.emailRepo.DeleteDigestItems(ctx, ids)
+	})
+	return sent, err
+}
+
+func (s *EmailService) addToDigest(ctx context.Context, notification *model.Notification) {
+	err := s.emailRepo.AddDigestItem(ctx, &model.EmailDigestItem{
+		UserID:         notification.RecipientID,
+		NotificationID: notification.ID,
+	})
+	if err != nil {
+		s.logger.Error("failed to add digest item", zap.Uint("notificationID", notification.ID), zap.Error(err))
+	}
+}
+
+// settings сохраненные настройки или настройки по умолчанию
+func (s *EmailService) settings(ctx context.Context, userID uint) (*model.EmailSettings, error) {
+	settings, err := s.emailRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &model.EmailSettings{UserID: userID}
+	}
+	return settings, nil
+}
+
+func (s *EmailService) locale(settings *model.EmailSettings) string {
+	if settings.Locale != "" {
+		return settings.Locale
+	}
+	return s.opts.Locale
+}
+
+// item строка письма об уведомлении. actors кэш имен авторов в пределах одного письма
+func (s *EmailService) item(ctx context.Context, locale string, notification *model.Notification, actors map[uint]string) (string, mail.TemplateItem) {
+	actor := ""
+	if notification.ActorID != 0 {
+		name, ok := actors[notification.ActorID]
+		if !ok {
+			if user, err := s.userRepo.GetUserByID(ctx, notification.ActorID); err == nil {
+				name = user.FullName()
+			}
+			actors[notification.ActorID] = name
+		}
+		actor = name
+	}
+
+	subject, text := Describe(locale, notification, actor)
+	return subject, mail.TemplateItem{
+		Text:      text,
+		TaskTitle: payloadString(notification.Payload, "taskTitle"),
+		URL:       fmt.Sprintf("%s/tasks/%d", s.appURL(), notification.TaskID),
+	}
+}
+
+func (s *EmailService) templateData(texts emailLocale, recipient *model.User, title string, intro string, items []mail.TemplateItem) mail.TemplateData {
+	return mail.TemplateData{
+		Title:        title,
+		Greeting:     fmt.Sprintf(texts.Greeting, recipient.FirstName),
+		Intro:        intro,
+		Items:        items,
+		ActionText:   texts.OpenTask,
+		Footer:       texts.Footer,
+		SettingsText: texts.SettingsText,
+		SettingsURL:  s.appURL() + "/settings/notifications",
+	}
+}
+
+func (s *EmailService) appURL() string {
+	return strings.TrimRight(s.opts.AppURL, "/")
+}
+
+// DigestScheduler отправляет сводки раз в день в заданный час
+type DigestScheduler struct {
+	service  *EmailService
+	hour     int
+	location *time.Location
+	logger   *zap.Logger
+}
+
+func NewDigestScheduler(service *EmailService, hour int, location *time.Location, logger *zap.Logger) *DigestScheduler {
+	return &DigestScheduler{
+		service:  service,
+		hour:     hour,
+		location: location,
+		logger:   logger,
+	}
+}
+
+func (d *DigestScheduler) Run(ctx context.Context) {
+	sent, err := d.service.RunDigest(ctx)
+	if err != nil {
+		d.logger.Error("digest scheduler failed", zap.Error(err))
+		return
+	}
+	d.logger.Info("digest scheduler finished", zap.Int("sent", sent))
+}
+
+// Start ждет ближайшего часа отправки и повторяет каждый день до отмены контекста
+func (d *DigestScheduler) Start(ctx context.Context) {
+	for {
+		timer := time.NewTimer(time.Until(d.next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			d.Run(ctx)
+		}
+	}
+}
+
+func (d *DigestScheduler) next(now time.Time) time.Time {
+	now = now.In(d.location)
+	next := time.Date(now.Year(), now.Month(), now.Day(), d.hour, 0, 0, 0, d.location)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
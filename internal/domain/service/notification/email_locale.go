@@ -0,0 +1,220 @@
+package notification
+
+import (
+	"fmt"
+	"rttask/internal/domain/model"
+	"strconv"
+)
+
+const defaultLocale = "ru"
+
+// emailLocale тексты писем на одном языке. Строки Lines получают имя автора события и payload уведомления
+type emailLocale struct {
+	Subjects      map[model.NotificationType]string
+	Lines         map[model.NotificationType]func(actor string, payload map[string]any) string
+	DigestSubject string
+	DigestIntro   string
+	Greeting      string
+	OpenTask      string
+	System        string
+	Footer        string
+	SettingsText  string
+}
+
+var emailLocales = map[string]emailLocale{
+	"ru": {
+		Subjects: map[model.NotificationType]string{
+			model.TaskAssignedNotification:     "Вас назначили на задачу «%s»",
+			model.StatusChangedNotification:    "Изменен статус задачи «%s»",
+			model.CommentAddedNotification:     "Новый комментарий к задаче «%s»",
+			model.MentionedNotification:        "Вас упомянули в задаче «%s»",
+			model.DeadlineReminderNotification: "Приближается срок задачи «%s»",
+			model.TaskOverdueNotification:      "Просрочена задача «%s»",
+			model.BlockerCompletedNotification: "Разблокирована задача «%s»",
+		},
+		Lines: map[model.NotificationType]func(string, map[string]any) string{
+			model.TaskAssignedNotification: func(actor string, payload map[string]any) string {
+				if payloadString(payload, "role") == string(model.WatcherRole) {
+					return fmt.Sprintf("%s добавил(а) вас наблюдателем", actor)
+				}
+				return fmt.Sprintf("%s назначил(а) вас исполнителем", actor)
+			},
+			model.StatusChangedNotification: func(actor string, payload map[string]any) string {
+				return fmt.Sprintf("%s перевел(а) задачу из «%s» в «%s»", actor, payloadString(payload, "fromStatus"), payloadString(payload, "toStatus"))
+			},
+			model.CommentAddedNotification: func(actor string, payload map[string]any) string {
+				return fmt.Sprintf("%s оставил(а) комментарий: %s", actor, payloadString(payload, "preview"))
+			},
+			model.MentionedNotification: func(actor string, payload map[string]any) string {
+				if payloadString(payload, "source") == string(model.MentionInTask) {
+					return fmt.Sprintf("%s упомянул(а) вас в описании задачи: %s", actor, payloadString(payload, "preview"))
+				}
+				return fmt.Sprintf("%s упомянул(а) вас в комментарии: %s", actor, payloadString(payload, "preview"))
+			},
+			model.DeadlineReminderNotification: func(actor string, payload map[string]any) string {
+				return fmt.Sprintf("До срока выполнения осталось %s", ruDuration(payloadInt(payload, "aheadMinutes")))
+			},
+			model.TaskOverdueNotification: func(actor string, payload map[string]any) string {
+				if payloadBool(payload, "escalated") {
+					return fmt.Sprintf("Срок выполнения истек, задача переведена в «%s»", model.ImmediateStatus)
+				}
+				return "Срок выполнения истек"
+			},
+			model.BlockerCompletedNotification: func(actor string, payload map[string]any) string {
+				return fmt.Sprintf("Блокирующая задача «%s» выполнена, осталось блокеров: %d", payloadString(payload, "blockerTitle"), payloadInt(payload, "openBlockers"))
+			},
+		},
+		DigestSubject: "Сводка RTTask: %d событий",
+		DigestIntro:   "Что произошло в ваших задачах:",
+		Greeting:      "Здравствуйте, %s!",
+		OpenTask:      "Открыть задачу",
+		System:        "RTTask",
+		Footer:        "Это письмо отправлено автоматически, отвечать на него не нужно.",
+		SettingsText:  "Настроить уведомления",
+	},
+	"en": {
+		Subjects: map[model.NotificationType]string{
+			model.TaskAssignedNotification:     "You were assigned to \"%s\"",
+			model.StatusChangedNotification:    "Status of \"%s\" changed",
+			model.CommentAddedNotification:     "New comment on \"%s\"",
+			model.MentionedNotification:        "You were mentioned in \"%s\"",
+			model.DeadlineReminderNotification: "\"%s\" is due soon",
+			model.TaskOverdueNotification:      "\"%s\" is overdue",
+			model.BlockerCompletedNotification: "\"%s\" was unblocked",
+		},
+		Lines: map[model.NotificationType]func(string, map[string]any) string{
+			model.TaskAssignedNotification: func(actor string, payload map[string]any) string {
+				if payloadString(payload, "role") == string(model.WatcherRole) {
+					return fmt.Sprintf("%s added you as a watcher", actor)
+				}
+				return fmt.Sprintf("%s assigned the task to you", actor)
+			},
+			model.StatusChangedNotification: func(actor string, payload map[string]any) string {
+				return fmt.Sprintf("%s moved the task from \"%s\" to \"%s\"", actor,
+					enStatus(payloadString(payload, "fromStatus")), enStatus(payloadString(payload, "toStatus")))
+			},
+			model.CommentAddedNotification: func(actor string, payload map[string]any) string {
+				return fmt.Sprintf("%s commented: %s", actor, payloadString(payload, "preview"))
+			},
+			model.MentionedNotification: func(actor string, payload map[string]any) string {
+				if payloadString(payload, "source") == string(model.MentionInTask) {
+					return fmt.Sprintf("%s mentioned you in the task description: %s", actor, payloadString(payload, "preview"))
+				}
+				return fmt.Sprintf("%s mentioned you in a comment: %s", actor, payloadString(payload, "preview"))
+			},
+			model.DeadlineReminderNotification: func(actor string, payload map[string]any) string {
+				return fmt.Sprintf("The task is due in %s", enDuration(payloadInt(payload, "aheadMinutes")))
+			},
+			model.TaskOverdueNotification: func(actor string, payload map[string]any) string {
+				if payloadBool(payload, "escalated") {
+					return fmt.Sprintf("The task is overdue and was moved to \"%s\"", enStatus(string(model.ImmediateStatus)))
+				}
+				return "The task is overdue"
+			},
+			model.BlockerCompletedNotification: func(actor string, payload map[string]any) string {
+				return fmt.Sprintf("Blocking task \"%s\" was completed, blockers left: %d", payloadString(payload, "blockerTitle"), payloadInt(payload, "openBlockers"))
+			},
+		},
+		DigestSubject: "RTTask digest: %d updates",
+		DigestIntro:   "Here is what happened in your tasks:",
+		Greeting:      "Hello, %s!",
+		OpenTask:      "Open task",
+		System:        "RTTask",
+		Footer:        "This email was sent automatically, please do not reply.",
+		SettingsText:  "Notification settings",
+	},
+}
+
+var enStatuses = map[model.Status]string{
+	model.CreatedStatus:    "New",
+	model.InWorkStatus:     "In progress",
+	model.InProgressStatus: "Rework",
+	model.CompletedStatus:  "Done",
+	model.ImmediateStatus:  "Urgent",
+}
+
+// IsSupportedLocale язык, на котором есть тексты писем
+func IsSupportedLocale(locale string) bool {
+	_, ok := emailLocales[locale]
+	return ok
+}
+
+// Describe заголовок и текст уведомления на языке locale, общие для всех каналов доставки.
+// Пустой actor у событий планировщиков
+func Describe(locale string, notification *model.Notification, actor string) (string, string) {
+	texts := localeFor(locale)
+	if actor == "" {
+		actor = texts.System
+	}
+
+	title, text := "", ""
+	if subject, ok := texts.Subjects[notification.Type]; ok {
+		title = fmt.Sprintf(subject, payloadString(notification.Payload, "taskTitle"))
+	}
+	if line, ok := texts.Lines[notification.Type]; ok {
+		text = line(actor, notification.Payload)
+	}
+	return title, text
+}
+
+func localeFor(locale string) emailLocale {
+	if texts, ok := emailLocales[locale]; ok {
+		return texts
+	}
+	return emailLocales[defaultLocale]
+}
+
+func enStatus(status string) string {
+	if name, ok := enStatuses[model.Status(status)]; ok {
+		return name
+	}
+	return status
+}
+
+func ruDuration(minutes int) string {
+	switch {
+	case minutes >= 1440 && minutes%1440 == 0:
+		return strconv.Itoa(minutes/1440) + " дн."
+	case minutes >= 60 && minutes%60 == 0:
+		return strconv.Itoa(minutes/60) + " ч"
+	default:
+		return strconv.Itoa(minutes) + " мин"
+	}
+}
+
+func enDuration(minutes int) string {
+	switch {
+	case minutes >= 1440 && minutes%1440 == 0:
+		return strconv.Itoa(minutes/1440) + " d"
+	case minutes >= 60 && minutes%60 == 0:
+		return strconv.Itoa(minutes/60) + " h"
+	default:
+		return strconv.Itoa(minutes) + " min"
+	}
+}
+
+// payload уведомления из базы приходит после JSON, поэтому числа там float64, а типизированные строки просто string
+func payloadString(payload map[string]any, key string) string {
+	value, ok := payload[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func payloadInt(payload map[string]any, key string) int {
+	switch value := payload[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+func payloadBool(payload map[string]any, key string) bool {
+	value, _ := payload[key].(bool)
+	return value
+}
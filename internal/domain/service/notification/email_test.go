@@ -0,0 +1,209 @@
+package notification
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/repository/repotest"
+	"rttask/internal/infrastructure/mail"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type fakeEmailRepo struct {
+	repository.EmailRepository
+	settings map[uint]*model.EmailSettings
+	digest   []*model.EmailDigestItem
+}
+
+func (r *fakeEmailRepo) GetSettings(ctx context.Context, userID uint) (*model.EmailSettings, error) {
+	return r.settings[userID], nil
+}
+
+func (r *fakeEmailRepo) AddDigestItem(ctx context.Context, item *model.EmailDigestItem) error {
+	item.ID = uint(len(r.digest) + 1)
+	r.digest = append(r.digest, item)
+	return nil
+}
+
+func (r *fakeEmailRepo) GetDigestUserIDs(ctx context.Context) ([]uint, error) {
+	var ids []uint
+	for _, item := range r.digest {
+		ids = append(ids, item.UserID)
+	}
+	return ids[:min(len(ids), 1)], nil
+}
+
+func (r *fakeEmailRepo) LockDigest(ctx context.Context, userID uint) ([]*model.EmailDigestItem, error) {
+	return r.digest, nil
+}
+
+func (r *fakeEmailRepo) DeleteDigestItems(ctx context.Context, ids []uint) error {
+	r.digest = nil
+	return nil
+}
+
+type sentMail struct {
+	to, subject, text, html string
+}
+
+// readMails письма, которые FileMailer сложил в dir
+func readMails(t *testing.T, dir string) []sentMail {
+	t.Helper()
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	var mails []sentMail
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := netmail.ReadMessage(strings.NewReader(string(data)))
+		if err != nil {
+			t.Fatalf("parse %s: %v", file, err)
+		}
+		subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent := sentMail{to: msg.Header.Get("To"), subject: subject}
+
+		_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts := multipart.NewReader(msg.Body, params["boundary"])
+		for {
+			part, err := parts.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(part)
+			if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+				sent.html = string(body)
+			} else {
+				sent.text = string(body)
+			}
+		}
+		mails = append(mails, sent)
+	}
+	return mails
+}
+
+func newEmailFixture(t *testing.T) (*EmailService, *event.Bus, *fakeEmailRepo, string) {
+	t.Helper()
+	dir := t.TempDir()
+	emailRepo := &fakeEmailRepo{settings: map[uint]*model.EmailSettings{
+		3: {UserID: 3, Locale: "en"},
+	}}
+	userRepo := &repotest.UserRepo{Users: map[uint]*model.User{
+		2: {Model: gorm.Model{ID: 2}, FirstName: "Bob", LastName: "Smith", Email: "bob@example.com"},
+		3: {Model: gorm.Model{ID: 3}, FirstName: "Carol", LastName: "Jones", Email: "carol@example.com"},
+	}}
+	service := NewEmailService(emailRepo, userRepo, repotest.UnitOfWork{}, mail.NewFileMailer(dir, "RTTask <noreply@rttask.dev>"), mail.MustNewRenderer(), EmailOptions{
+		AppURL: "https://app.rttask.dev/",
+		Locale: "ru",
+	}, zap.NewNop())
+	bus := event.NewBus(zap.NewNop())
+	service.Subscribe(bus)
+	return service, bus, emailRepo, dir
+}
+
+func publishNotification(bus *event.Bus, notification *model.Notification) {
+	bus.Publish(context.Background(), event.Event{
+		Type:    event.NotificationCreated,
+		ActorID: notification.ActorID,
+		Payload: event.NotificationCreatedPayload{Notification: notification, Unread: 1},
+	})
+}
+
+func TestEmailInstantMention(t *testing.T) {
+	service, bus, emailRepo, dir := newEmailFixture(t)
+	publishNotification(bus, &model.Notification{
+		ID:          10,
+		RecipientID: 3,
+		Type:        model.MentionedNotification,
+		TaskID:      7,
+		ActorID:     2,
+		Payload: map[string]any{
+			"taskTitle": "Release <v2>",
+			"source":    string(model.MentionInComment),
+			"preview":   "@carol take a look",
+		},
+	})
+	// Start разбирает очередь в фоне, здесь одно письмо отправляется синхронно
+	service.deliver(context.Background(), <-service.queue)
+
+	mails := readMails(t, dir)
+	if len(mails) != 1 {
+		t.Fatalf("sent %d mails, want 1", len(mails))
+	}
+	got := mails[0]
+	if got.to != "carol@example.com" || got.subject != `You were mentioned in "Release <v2>"` {
+		t.Errorf("mail to %q with subject %q", got.to, got.subject)
+	}
+	for _, want := range []string{"Hello, Carol!", "Bob Smith mentioned you in a comment: @carol take a look", "https://app.rttask.dev/tasks/7"} {
+		if !strings.Contains(got.text, want) {
+			t.Errorf("text part has no %q:\n%s", want, got.text)
+		}
+	}
+	if !strings.Contains(got.html, "Release &lt;v2&gt;") || strings.Contains(got.html, "Release <v2>") {
+		t.Errorf("html part does not escape the task title:\n%s", got.html)
+	}
+	if len(emailRepo.digest) != 0 {
+		t.Errorf("instant mail also added to digest")
+	}
+}
+
+func TestEmailDigest(t *testing.T) {
+	service, bus, emailRepo, dir := newEmailFixture(t)
+	notifications := []*model.Notification{
+		{ID: 11, RecipientID: 2, Type: model.CommentAddedNotification, TaskID: 7, ActorID: 3,
+			Payload: map[string]any{"taskTitle": "Release", "preview": "Готово"}},
+		{ID: 12, RecipientID: 2, Type: model.StatusChangedNotification, TaskID: 8, ActorID: 3,
+			Payload: map[string]any{"taskTitle": "Docs", "fromStatus": "todo", "toStatus": "done"}},
+	}
+	for _, notification := range notifications {
+		publishNotification(bus, notification)
+		service.deliver(context.Background(), <-service.queue)
+	}
+	if len(emailRepo.digest) != 2 || len(readMails(t, dir)) != 0 {
+		t.Fatalf("digest has %d items, want both notifications and no mail yet", len(emailRepo.digest))
+	}
+	for i, item := range emailRepo.digest {
+		item.Notification = notifications[i]
+	}
+
+	sent, err := service.RunDigest(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("RunDigest = %d, %v, want 1 digest", sent, err)
+	}
+	mails := readMails(t, dir)
+	if len(mails) != 1 {
+		t.Fatalf("sent %d mails, want 1", len(mails))
+	}
+	got := mails[0]
+	if got.to != "bob@example.com" || got.subject != "Сводка RTTask: 2 событий" {
+		t.Errorf("digest to %q with subject %q", got.to, got.subject)
+	}
+	for _, want := range []string{"Здравствуйте, Bob!", "Release", "Docs", "https://app.rttask.dev/tasks/7", "https://app.rttask.dev/tasks/8", "https://app.rttask.dev/settings/notifications"} {
+		if !strings.Contains(got.text, want) {
+			t.Errorf("digest text has no %q:\n%s", want, got.text)
+		}
+	}
+	if len(emailRepo.digest) != 0 {
+		t.Errorf("digest items were not deleted after sending")
+	}
+}
@@ -0,0 +1,224 @@
+package notification
+
+import (
+	"context"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/valueobject"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxCommentPreview длина текста комментария или описания в уведомлении
+const maxCommentPreview = 200
+
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+	events           event.Publisher
+	logger           *zap.Logger
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository, events event.Publisher, logger *zap.Logger) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		events:           events,
+		logger:           logger,
+	}
+}
+
+// Subscribe подписывает сервис на события, из которых получаются уведомления
+func (s *NotificationService) Subscribe(bus *event.Bus) {
+	bus.Subscribe(event.TaskAssigned, s.onTaskAssigned)
+	bus.Subscribe(event.TaskMoved, s.onTaskMoved)
+	bus.Subscribe(event.CommentCreated, s.onCommentCreated)
+	bus.Subscribe(event.UserMentioned, s.onUserMentioned)
+	bus.Subscribe(event.DeadlineReminder, s.onDeadline)
+	bus.Subscribe(event.TaskOverdue, s.onDeadline)
+	bus.Subscribe(event.BlockerCompleted, s.onBlockerCompleted)
+}
+
+// ListNotifications уведомления пользователя, новые первыми, и число непрочитанных
+func (s *NotificationService) ListNotifications(ctx context.Context, userID uint, unreadOnly bool, params valueobject.PaginationParams) ([]*model.Notification, int64, int64, error) {
+	notifications, total, err := s.notificationRepo.List(ctx, userID, unreadOnly, params)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+// MarkRead отмечает прочитанными уведомления пользователя, пустой ids отмечает все.
+// Чужие id пропускаются. Возвращает число оставшихся непрочитанных
+func (s *NotificationService) MarkRead(ctx context.Context, userID uint, ids []uint) (int64, error) {
+	if _, err := s.notificationRepo.MarkRead(ctx, userID, ids); err != nil {
+		s.logger.Error("failed to mark notifications read", zap.Uint("userID", userID), zap.Error(err))
+		return 0, err
+	}
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) onTaskAssigned(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.TaskAssignedPayload)
+	if !ok {
+		return
+	}
+	for _, role := range []struct {
+		role model.AssigneeRole
+		ids  []uint
+	}{{model.ExecutorRole, payload.ExecutorIDs}, {model.WatcherRole, payload.WatcherIDs}} {
+		s.notify(ctx, e, model.TaskAssignedNotification, payload.Task, role.ids, map[string]any{
+			"role": role.role,
+		})
+	}
+}
+
+// onTaskMoved уведомляет только о смене статуса, перестановка внутри колонки не интересна
+func (s *NotificationService) onTaskMoved(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.TaskMovedPayload)
+	if !ok || payload.Task.Status == payload.FromStatus {
+		return
+	}
+	s.notify(ctx, e, model.StatusChangedNotification, payload.Task, participants(payload.Task), map[string]any{
+		"fromStatus": payload.FromStatus,
+		"toStatus":   payload.Task.Status,
+	})
+}
+
+// onCommentCreated упомянутые в комментарии получают отдельное уведомление об упоминании, а не это
+func (s *NotificationService) onCommentCreated(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.CommentCreatedPayload)
+	if !ok {
+		return
+	}
+	mentioned := model.MentionedUserIDs(payload.Comment.Mentions)
+	recipients := slices.DeleteFunc(participants(payload.Task), func(id uint) bool {
+		return slices.Contains(mentioned, id)
+	})
+	s.notify(ctx, e, model.CommentAddedNotification, payload.Task, recipients, commentDetails(payload.Comment))
+}
+
+// onUserMentioned упоминание в комментарии или, если Comment nil, в описании задачи
+func (s *NotificationService) onUserMentioned(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.UserMentionedPayload)
+	if !ok {
+		return
+	}
+	details := map[string]any{
+		"source":  model.MentionInTask,
+		"preview": preview(payload.Task.Description),
+	}
+	if payload.Comment != nil {
+		details = commentDetails(payload.Comment)
+		details["source"] = model.MentionInComment
+	}
+	s.notify(ctx, e, model.MentionedNotification, payload.Task, payload.UserIDs, details)
+}
+
+func (s *NotificationService) onDeadline(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.DeadlinePayload)
+	if !ok {
+		return
+	}
+	notificationType := model.DeadlineReminderNotification
+	if e.Type == event.TaskOverdue {
+		notificationType = model.TaskOverdueNotification
+	}
+	s.notify(ctx, e, notificationType, payload.Task, payload.Recipients, map[string]any{
+		"deadlineAt":   payload.Task.DeadlineAt,
+		"aheadMinutes": int(payload.Ahead / time.Minute),
+		"escalated":    payload.Escalated,
+	})
+}
+
+func (s *NotificationService) onBlockerCompleted(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.BlockerCompletedPayload)
+	if !ok {
+		return
+	}
+	s.notify(ctx, e, model.BlockerCompletedNotification, payload.Task, payload.Task.ExecutorIDs(), map[string]any{
+		"blockerId":    payload.Blocker.ID,
+		"blockerTitle": payload.Blocker.Title,
+		"openBlockers": payload.OpenBlockers,
+	})
+}
+
+// notify сохраняет уведомления получателям, кроме автора события, и сообщает о каждом.
+// Ошибка сохранения не должна ломать операцию, которая породила событие, поэтому только логируется
+func (s *NotificationService) notify(ctx context.Context, e event.Event, notificationType model.NotificationType, task *model.Task, recipients []uint, details map[string]any) {
+	ctx = context.WithoutCancel(ctx)
+
+	notifications := make([]*model.Notification, 0, len(recipients))
+	seen := make([]uint, 0, len(recipients))
+	for _, recipientID := range recipients {
+		if recipientID == 0 || recipientID == e.ActorID || slices.Contains(seen, recipientID) {
+			continue
+		}
+		seen = append(seen, recipientID)
+
+		payload := map[string]any{"taskTitle": task.Title, "taskStatus": task.Status}
+		for key, value := range details {
+			payload[key] = value
+		}
+		notifications = append(notifications, &model.Notification{
+			CreatedAt:   e.OccurredAt,
+			RecipientID: recipientID,
+			Type:        notificationType,
+			CompanyID:   task.CompanyID,
+			TaskID:      task.ID,
+			ActorID:     e.ActorID,
+			Payload:     payload,
+		})
+	}
+	if len(notifications) == 0 {
+		return
+	}
+
+	if err := s.notificationRepo.Create(ctx, notifications); err != nil {
+		s.logger.Error("failed to save notifications",
+			zap.String("type", string(notificationType)),
+			zap.Uint("taskID", task.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, notification := range notifications {
+		unread, err := s.notificationRepo.CountUnread(ctx, notification.RecipientID)
+		if err != nil {
+			s.logger.Warn("failed to count unread notifications", zap.Uint("userID", notification.RecipientID), zap.Error(err))
+		}
+		s.events.Publish(ctx, event.Event{
+			Type:      event.NotificationCreated,
+			CompanyID: notification.CompanyID,
+			ActorID:   notification.ActorID,
+			Payload:   event.NotificationCreatedPayload{Notification: notification, Unread: unread},
+		})
+	}
+}
+
+// participants автор, исполнители и наблюдатели задачи
+func participants(task *model.Task) []uint {
+	ids := append([]uint{task.CreatorID}, task.ExecutorIDs()...)
+	return append(ids, task.WatcherIDs()...)
+}
+
+func commentDetails(comment *model.Comment) map[string]any {
+	return map[string]any{
+		"commentId": comment.ID,
+		"preview":   preview(comment.Content),
+	}
+}
+
+func preview(text string) string {
+	runes := []rune(text)
+	if len(runes) > maxCommentPreview {
+		runes = append(runes[:maxCommentPreview], '…')
+	}
+	return string(runes)
+}
@@ -0,0 +1,163 @@
+package notification
+
+import (
+	"context"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type fakeNotificationRepo struct {
+	repository.NotificationRepository
+	created []*model.Notification
+}
+
+func (r *fakeNotificationRepo) Create(ctx context.Context, notifications []*model.Notification) error {
+	r.created = append(r.created, notifications...)
+	return nil
+}
+
+func (r *fakeNotificationRepo) CountUnread(ctx context.Context, recipientID uint) (int64, error) {
+	var unread int64
+	for _, notification := range r.created {
+		if notification.RecipientID == recipientID {
+			unread++
+		}
+	}
+	return unread, nil
+}
+
+func (r *fakeNotificationRepo) byType(notificationType model.NotificationType) map[uint]*model.Notification {
+	result := make(map[uint]*model.Notification)
+	for _, notification := range r.created {
+		if notification.Type == notificationType {
+			result[notification.RecipientID] = notification
+		}
+	}
+	return result
+}
+
+func newNotificationFixture(t *testing.T) (*event.Bus, *fakeNotificationRepo, *[]event.NotificationCreatedPayload) {
+	t.Helper()
+	bus := event.NewBus(zap.NewNop())
+	repo := &fakeNotificationRepo{}
+	NewNotificationService(repo, bus, zap.NewNop()).Subscribe(bus)
+
+	var published []event.NotificationCreatedPayload
+	bus.Subscribe(event.NotificationCreated, func(ctx context.Context, e event.Event) {
+		published = append(published, e.Payload.(event.NotificationCreatedPayload))
+	})
+	return bus, repo, &published
+}
+
+// testTask автор 1, исполнитель 2, наблюдатель 3
+func testTask() *model.Task {
+	return &model.Task{
+		Model:       gorm.Model{ID: 7},
+		Title:       "Release",
+		Description: "Prepare the release notes, @dave please review",
+		CompanyID:   1,
+		CreatorID:   1,
+		ExecutorID:  2,
+		Status:      model.Statuses[0],
+		Assignees:   []*model.TaskAssignee{{UserID: 3, Role: model.WatcherRole}},
+	}
+}
+
+func TestCommentWithMentionNotifications(t *testing.T) {
+	bus, repo, published := newNotificationFixture(t)
+	task := testTask()
+	comment := &model.Comment{
+		Model:    gorm.Model{ID: 42},
+		Content:  "@carol take a look",
+		TaskID:   task.ID,
+		UserID:   2,
+		Mentions: []*model.Mention{{UserID: 3, Offset: 0, Length: 6}},
+	}
+
+	// так CommentService.CreateComment публикует события
+	bus.Publish(context.Background(), event.Event{
+		Type:      event.CommentCreated,
+		CompanyID: task.CompanyID,
+		ActorID:   2,
+		Payload:   event.CommentCreatedPayload{Task: task, Comment: comment},
+	})
+	bus.Publish(context.Background(), event.Event{
+		Type:      event.UserMentioned,
+		CompanyID: task.CompanyID,
+		ActorID:   2,
+		Payload:   event.UserMentionedPayload{Task: task, Comment: comment, UserIDs: []uint{3}},
+	})
+
+	// автор комментария не уведомляется, упомянутый получает только уведомление об упоминании
+	added := repo.byType(model.CommentAddedNotification)
+	if len(added) != 1 || added[1] == nil {
+		t.Fatalf("comment notifications for %v, want only the task creator", keys(added))
+	}
+	if got := added[1].Payload["commentId"]; got != uint(42) {
+		t.Errorf("commentId = %v, want 42", got)
+	}
+
+	mentioned := repo.byType(model.MentionedNotification)
+	if len(mentioned) != 1 || mentioned[3] == nil {
+		t.Fatalf("mention notifications for %v, want only user 3", keys(mentioned))
+	}
+	payload := mentioned[3].Payload
+	if payload["source"] != model.MentionInComment || payload["commentId"] != uint(42) || payload["preview"] != comment.Content {
+		t.Errorf("mention payload = %v", payload)
+	}
+	if mentioned[3].TaskID != task.ID || mentioned[3].ActorID != 2 || payload["taskTitle"] != task.Title {
+		t.Errorf("mention notification = %+v", mentioned[3])
+	}
+
+	if len(*published) != 2 {
+		t.Fatalf("published %d NotificationCreated events, want 2", len(*published))
+	}
+	for _, p := range *published {
+		if p.Unread != 1 {
+			t.Errorf("unread for user %d = %d, want 1", p.Notification.RecipientID, p.Unread)
+		}
+	}
+}
+
+func TestDescriptionMentionNotification(t *testing.T) {
+	bus, repo, _ := newNotificationFixture(t)
+	task := testTask()
+	task.Description = strings.Repeat("д", maxCommentPreview+50) + " @dave"
+
+	// упоминание в описании приходит без комментария, у задач из повторения нет автора события
+	bus.Publish(context.Background(), event.Event{
+		Type:      event.UserMentioned,
+		CompanyID: task.CompanyID,
+		Payload:   event.UserMentionedPayload{Task: task, UserIDs: []uint{4, 4}},
+	})
+
+	mentioned := repo.byType(model.MentionedNotification)
+	if len(repo.created) != 1 || mentioned[4] == nil {
+		t.Fatalf("notifications = %d for %v, want one for user 4", len(repo.created), keys(mentioned))
+	}
+	payload := mentioned[4].Payload
+	if payload["source"] != model.MentionInTask {
+		t.Errorf("source = %v, want task", payload["source"])
+	}
+	if _, ok := payload["commentId"]; ok {
+		t.Errorf("description mention has commentId: %v", payload)
+	}
+	want := strings.Repeat("д", maxCommentPreview) + "…"
+	if payload["preview"] != want {
+		t.Errorf("preview = %q, want description cut to %d runes", payload["preview"], maxCommentPreview)
+	}
+}
+
+func keys(notifications map[uint]*model.Notification) []uint {
+	ids := make([]uint, 0, len(notifications))
+	for id := range notifications {
+		ids = append(ids, id)
+	}
+	return ids
+}
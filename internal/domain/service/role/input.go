@@ -5,3 +5,9 @@ type RoleInput struct {
 	Permissions []string
 	UserID      uint
 }
+
+type AssignRolesInput struct {
+	TargetUserID uint
+	RolesIDs     []uint
+	UserID       uint
+}
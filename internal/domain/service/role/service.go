@@ -3,7 +3,9 @@ package role
 import (
 	"context"
 	"errors"
+	"fmt"
 	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
 	"rttask/internal/domain/model/rbac"
 	"rttask/internal/domain/repository"
 
@@ -39,13 +41,46 @@ func (s *RoleService) CreateRole(ctx context.Context, input RoleInput) (*rbac.Ro
 	return newRole, nil
 }
 
+// AssignRoles назначает роли пользователю. Нельзя выдать права, которых нет у назначающего
+func (s *RoleService) AssignRoles(ctx context.Context, input AssignRolesInput) (*model.User, error) {
+	assigner, err := s.checkUserPermission(ctx, input.UserID, rbac.RoleAssign)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.userRepo.GetUserByIDWithRoles(ctx, input.TargetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.validateAssignedRoles(ctx, input.RolesIDs, assigner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.AssignRoles(ctx, target, roles); err != nil {
+		s.logger.Error("failed to assign roles",
+			zap.Uint("userID", target.ID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("roles assigned",
+		zap.Uint("userID", target.ID),
+		zap.Uint("assignerID", assigner.ID),
+		zap.Uints("roles", input.RolesIDs),
+	)
+	return target, nil
+}
+
 // validate Объеденяет все валидацию в 1 функцию
 func (s *RoleService) validate(ctx context.Context, input RoleInput) ([]rbac.Permission, error) {
 	err := s.checkExistRole(ctx, input.Name)
 	if err != nil {
 		return nil, err
 	}
-	err = s.checkUserPermission(ctx, input.UserID, rbac.RoleCreate)
+	_, err = s.checkUserPermission(ctx, input.UserID, rbac.RoleCreate)
 	if err != nil {
 		return nil, err
 	}
@@ -85,16 +120,43 @@ func (s *RoleService) checkExistRole(ctx context.Context, name string) error {
 }
 
 // checkUserPermission Валидирует права доступа пользователя. может ли он сделать это дейтсвие
-func (s *RoleService) checkUserPermission(ctx context.Context, userID uint, permission rbac.Permission) error {
+func (s *RoleService) checkUserPermission(ctx context.Context, userID uint, permission rbac.Permission) (*model.User, error) {
 	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if user == nil {
-		return domainerrors.NewUnauthorizedError("Unauthorized")
+		return nil, domainerrors.NewUnauthorizedError("Unauthorized")
 	}
 	if !user.Can(permission) {
-		return domainerrors.NewForbiddenError("Dont have permissions to create a role")
+		return nil, domainerrors.NewForbiddenError(fmt.Sprintf("Dont have permission %s", permission))
 	}
-	return nil
+	return user, nil
+}
+
+// validateAssignedRoles проверяет что роли существуют, активны и входят в права назначающего
+func (s *RoleService) validateAssignedRoles(ctx context.Context, rolesIDs []uint, assigner *model.User) ([]rbac.Role, error) {
+	if len(rolesIDs) == 0 {
+		return nil, domainerrors.NewValidationError("Invalid role IDs")
+	}
+	roles, err := s.roleRepo.GetByIDs(ctx, rolesIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) != len(rolesIDs) {
+		return nil, domainerrors.NewValidationError("Invalid role IDs")
+	}
+	for _, role := range roles {
+		if !role.IsActive {
+			return nil, domainerrors.NewValidationError(fmt.Sprintf("Role %s is not active", role.Name))
+		}
+	}
+	if missing := assigner.MissingPermissions(roles...); len(missing) > 0 {
+		s.logger.Warn("role assignment grants permissions the assigner does not have",
+			zap.Uint("userID", assigner.ID),
+			zap.Any("permissions", missing),
+		)
+		return nil, domainerrors.NewPrivilegeEscalationError(rbac.PermissionNames(missing))
+	}
+	return roles, nil
 }
@@ -0,0 +1,75 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository/repotest"
+	"testing"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	assignerID = 1
+	targetID   = 2
+
+	viewerRoleID  = 10
+	creatorRoleID = 11
+	managerRoleID = 12
+)
+
+var roles = map[uint]rbac.Role{
+	viewerRoleID:  {Model: gorm.Model{ID: viewerRoleID}, Name: "viewer", Permissions: []rbac.Permission{rbac.TaskView}, IsActive: true},
+	creatorRoleID: {Model: gorm.Model{ID: creatorRoleID}, Name: "creator", Permissions: []rbac.Permission{rbac.TaskView, rbac.TaskCreate}, IsActive: true},
+	managerRoleID: {Model: gorm.Model{ID: managerRoleID}, Name: "manager", Permissions: []rbac.Permission{rbac.RoleCreate, rbac.TaskCreate, rbac.UserDelete}, IsActive: true},
+}
+
+func TestAssignRolesRejectsPrivilegeEscalation(t *testing.T) {
+	tests := []struct {
+		name            string
+		permissions     []rbac.Permission
+		rolesIDs        []uint
+		wantPermissions []string // права из Meta ошибки, nil если роли назначены
+	}{
+		{name: "own permissions", permissions: []rbac.Permission{rbac.RoleAssign, rbac.TaskView}, rolesIDs: []uint{viewerRoleID}},
+		{name: "several roles within own permissions", permissions: []rbac.Permission{rbac.RoleAssign, rbac.TaskView, rbac.TaskCreate}, rolesIDs: []uint{viewerRoleID, creatorRoleID}},
+		{name: "one extra permission", permissions: []rbac.Permission{rbac.RoleAssign, rbac.TaskView}, rolesIDs: []uint{creatorRoleID}, wantPermissions: []string{"task:create"}},
+		{name: "extra permissions across roles", permissions: []rbac.Permission{rbac.RoleAssign, rbac.TaskView}, rolesIDs: []uint{creatorRoleID, managerRoleID}, wantPermissions: []string{"task:create", "role:create", "user:delete"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := repotest.NewUser(targetID)
+			users := &repotest.UserRepo{Users: map[uint]*model.User{
+				assignerID: repotest.NewUser(assignerID, tt.permissions...),
+				targetID:   target,
+			}}
+			service := NewRoleService(&repotest.RoleRepo{Roles: roles}, users, zap.NewNop())
+
+			_, err := service.AssignRoles(context.Background(), AssignRolesInput{TargetUserID: targetID, RolesIDs: tt.rolesIDs, UserID: assignerID})
+			if tt.wantPermissions == nil {
+				if err != nil {
+					t.Fatalf("AssignRoles: %v", err)
+				}
+				if len(target.Roles) != 1+len(tt.rolesIDs) {
+					t.Errorf("target has %d roles, want %d", len(target.Roles), 1+len(tt.rolesIDs))
+				}
+				return
+			}
+			var domainErr *domainerrors.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Type != domainerrors.ErrorTypeForbidden {
+				t.Fatalf("AssignRoles error = %v, want forbidden", err)
+			}
+			if got := domainErr.Meta["permissions"]; !reflect.DeepEqual(got, tt.wantPermissions) {
+				t.Errorf("permissions = %v, want %v", got, tt.wantPermissions)
+			}
+			if len(target.Roles) != 1 {
+				t.Errorf("roles assigned despite escalation")
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package search
+
+import "rttask/internal/domain/model"
+
+type SearchInput struct {
+	Query       string
+	CompanyID   uint
+	EntityTypes []model.SearchEntityType // пусто во всех типах
+	Limit       int
+}
+
+// SearchResult найденное, сгруппированное по типам сущностей. Группа nil, если по ней не искали
+type SearchResult struct {
+	Tasks         []*model.SearchHit
+	TasksTotal    int64
+	Comments      []*model.SearchHit
+	CommentsTotal int64
+}
@@ -0,0 +1,117 @@
+package search
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/valueobject"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	minQueryLength = 2
+	maxQueryLength = 200
+	defaultLimit   = 10
+	maxLimit       = 50
+)
+
+var entityTypes = []model.SearchEntityType{model.SearchEntityTask, model.SearchEntityComment}
+
+type SearchService struct {
+	searchRepo repository.SearchRepository
+	userRepo   repository.UserRepository
+	logger     *zap.Logger
+}
+
+func NewSearchService(searchRepo repository.SearchRepository, userRepo repository.UserRepository, logger *zap.Logger) *SearchService {
+	return &SearchService{
+		searchRepo: searchRepo,
+		userRepo:   userRepo,
+		logger:     logger,
+	}
+}
+
+// Search полнотекстовый поиск по задачам и комментариям компаний пользователя.
+// Группы, на просмотр которых у пользователя нет прав, пропускаются
+func (s *SearchService) Search(ctx context.Context, input SearchInput, userID uint) (*SearchResult, error) {
+	s.logger.Info("start SearchService.Search")
+
+	params, err := s.validate(input)
+	if err != nil {
+		return nil, err
+	}
+	params.ViewerID = userID
+
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.CompanyID != 0 {
+		inCompany, err := s.userRepo.IsUserInCompany(ctx, userID, input.CompanyID)
+		if err != nil {
+			return nil, err
+		}
+		if !inCompany {
+			return nil, domainerrors.NewForbiddenError("user not in company").WithMeta("companyId", input.CompanyID)
+		}
+	}
+
+	types := input.EntityTypes
+	if len(types) == 0 {
+		types = entityTypes
+	}
+	searchTasks := slices.Contains(types, model.SearchEntityTask) && user.Can(rbac.TaskView)
+	searchComments := slices.Contains(types, model.SearchEntityComment) && user.Can(rbac.CommentView)
+	if !searchTasks && !searchComments {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+
+	result := &SearchResult{}
+	if searchTasks {
+		result.Tasks, result.TasksTotal, err = s.searchRepo.SearchTasks(ctx, params)
+		if err != nil {
+			s.logger.Error("failed to search tasks", zap.Error(err))
+			return nil, err
+		}
+	}
+	if searchComments {
+		result.Comments, result.CommentsTotal, err = s.searchRepo.SearchComments(ctx, params)
+		if err != nil {
+			s.logger.Error("failed to search comments", zap.Error(err))
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (s *SearchService) validate(input SearchInput) (valueobject.SearchParams, error) {
+	query := strings.TrimSpace(input.Query)
+	length := utf8.RuneCountInString(query)
+	if length < minQueryLength || length > maxQueryLength {
+		return valueobject.SearchParams{}, domainerrors.NewValidationError("Invalid search query length").
+			WithMeta("min", minQueryLength).
+			WithMeta("max", maxQueryLength)
+	}
+	for _, entityType := range input.EntityTypes {
+		if !slices.Contains(entityTypes, entityType) {
+			return valueobject.SearchParams{}, domainerrors.NewValidationError("Invalid entity type").
+				WithMeta("type", entityType).
+				WithMeta("allowed", entityTypes)
+		}
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit < 1 || limit > maxLimit {
+		return valueobject.SearchParams{}, domainerrors.NewValidationError("Invalid limit").WithMeta("max", maxLimit)
+	}
+	return valueobject.SearchParams{Query: query, CompanyID: input.CompanyID, Limit: limit}, nil
+}
@@ -0,0 +1,204 @@
+package task
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SetAssignees заменяет исполнителей и наблюдателей задачи. Первый исполнитель становится основным,
+// подтверждения оставшихся исполнителей сохраняются
+func (s *TaskService) SetAssignees(ctx context.Context, input AssigneesInput, userID uint) (*model.Task, error) {
+	task, user, err := s.taskForUser(ctx, input.TaskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Can(rbac.TaskAssign) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+
+	executors, watchers := compactIDs(input.ExecutorIDs), compactIDs(input.WatcherIDs)
+	if len(executors) == 0 {
+		return nil, domainerrors.NewValidationError("At least one executor is required")
+	}
+	if err := s.validateAssignees(ctx, executors, watchers, task.CompanyID); err != nil {
+		return nil, err
+	}
+
+	var addedExecutors, addedWatchers []uint
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		locked, err := s.taskRepo.GetByIDForUpdate(ctx, input.TaskID)
+		if err != nil {
+			return err
+		}
+		addedExecutors = subtractIDs(executors, locked.ExecutorIDs())
+		addedWatchers = subtractIDs(watchers, locked.WatcherIDs())
+		if err := s.assigneeRepo.Replace(ctx, locked.ID, buildAssignees(executors, watchers, locked)); err != nil {
+			return err
+		}
+		if executors[0] != locked.ExecutorID {
+			return s.taskRepo.UpdateExecutor(ctx, locked.ID, executors[0])
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("failed to set task assignees", zap.Uint("taskID", input.TaskID), zap.Error(err))
+		return nil, err
+	}
+
+	updated, err := s.GetTask(ctx, input.TaskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.publishAssigned(ctx, updated, addedExecutors, addedWatchers, userID)
+	return updated, nil
+}
+
+// Acknowledge исполнитель подтверждает (done false снимает подтверждение) выполнение своей части задачи
+func (s *TaskService) Acknowledge(ctx context.Context, taskID uint, done bool, userID uint) (*model.Task, error) {
+	task, _, err := s.taskForUser(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsExecutor(userID) {
+		return nil, domainerrors.NewForbiddenError("only executors can acknowledge task")
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		locked, err := s.taskRepo.GetByIDForUpdate(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		at := time.Time{}
+		if done {
+			at = time.Now()
+		}
+		// у задач, созданных до появления участников, строки основного исполнителя еще нет
+		if locked.Assignee(userID) == nil {
+			assignees := buildAssignees(locked.ExecutorIDs(), locked.WatcherIDs(), locked)
+			if err := s.assigneeRepo.Replace(ctx, taskID, assignees); err != nil {
+				return err
+			}
+		}
+		return s.assigneeRepo.SetAcknowledged(ctx, taskID, userID, at)
+	})
+	if err != nil {
+		s.logger.Error("failed to acknowledge task", zap.Uint("taskID", taskID), zap.Error(err))
+		return nil, err
+	}
+
+	return s.GetTask(ctx, taskID, userID)
+}
+
+// validateAssignees исполнители и наблюдатели из компании задачи и могут видеть задачи,
+// исполнители еще и менять статус. Один пользователь не может быть в обеих ролях
+func (s *TaskService) validateAssignees(ctx context.Context, executors []uint, watchers []uint, companyID uint) error {
+	for _, executorID := range executors {
+		if slices.Contains(watchers, executorID) {
+			return domainerrors.NewValidationError("User cannot be both executor and watcher").WithMeta("userId", executorID)
+		}
+		if err := s.validateExecutor(ctx, executorID, companyID); err != nil {
+			return err
+		}
+	}
+	for _, watcherID := range watchers {
+		if err := s.validateWatcher(ctx, watcherID, companyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TaskService) validateWatcher(ctx context.Context, watcherID uint, companyID uint) error {
+	watcher, err := s.userRepo.GetUserByIDWithRoles(ctx, watcherID)
+	if err != nil {
+		return err
+	}
+	if !watcher.Can(rbac.TaskView) {
+		return domainerrors.NewValidationError("Watcher not allowed to view tasks").WithMeta("userId", watcherID)
+	}
+	return s.checkUserInCompany(ctx, watcherID, companyID)
+}
+
+// buildAssignees строки участников с подтверждениями, которые уже были у task (nil для новой задачи)
+func buildAssignees(executors []uint, watchers []uint, task *model.Task) []*model.TaskAssignee {
+	assignees := make([]*model.TaskAssignee, 0, len(executors)+len(watchers))
+	for _, executorID := range executors {
+		assignee := &model.TaskAssignee{UserID: executorID, Role: model.ExecutorRole}
+		if task != nil {
+			if previous := task.Assignee(executorID); previous != nil && previous.Role == model.ExecutorRole {
+				assignee.AcknowledgedAt = previous.AcknowledgedAt
+			}
+		}
+		assignees = append(assignees, assignee)
+	}
+	for _, watcherID := range watchers {
+		assignees = append(assignees, &model.TaskAssignee{UserID: watcherID, Role: model.WatcherRole})
+	}
+	return assignees
+}
+
+// compactIDs убирает нули и повторы, сохраняя порядок
+func compactIDs(ids []uint) []uint {
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 && !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// subtractIDs ids, которых нет в existing
+func subtractIDs(ids []uint, existing []uint) []uint {
+	var result []uint
+	for _, id := range ids {
+		if !slices.Contains(existing, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// publishCreated сообщает о новой задаче и ее участниках, actorID 0 для планировщика
+func (s *TaskService) publishCreated(ctx context.Context, task *model.Task, executors []uint, watchers []uint, actorID uint) {
+	s.events.Publish(ctx, event.Event{
+		Type:      event.TaskCreated,
+		CompanyID: task.CompanyID,
+		ActorID:   actorID,
+		Payload:   event.TaskCreatedPayload{Task: task},
+	})
+	s.publishAssigned(ctx, task, executors, watchers, actorID)
+}
+
+// publishAssigned сообщает о новых исполнителях и наблюдателях задачи, actorID 0 для планировщика
+func (s *TaskService) publishAssigned(ctx context.Context, task *model.Task, executors []uint, watchers []uint, actorID uint) {
+	if len(executors) == 0 && len(watchers) == 0 {
+		return
+	}
+	s.events.Publish(ctx, event.Event{
+		Type:      event.TaskAssigned,
+		CompanyID: task.CompanyID,
+		ActorID:   actorID,
+		Payload:   event.TaskAssignedPayload{Task: task, ExecutorIDs: executors, WatcherIDs: watchers},
+	})
+}
+
+// publishMentioned сообщает упомянутым в описании задачи, actorID 0 для планировщика
+func (s *TaskService) publishMentioned(ctx context.Context, task *model.Task, userIDs []uint, actorID uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	s.events.Publish(ctx, event.Event{
+		Type:      event.UserMentioned,
+		CompanyID: task.CompanyID,
+		ActorID:   actorID,
+		Payload:   event.UserMentionedPayload{Task: task, UserIDs: userIDs},
+	})
+}
@@ -0,0 +1,294 @@
+package task
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/valueobject"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultBoardColumnLimit = 50
+	MaxBoardColumnLimit     = 200
+)
+
+type BoardColumn struct {
+	Status model.Status
+	Total  int64
+	Tasks  []*model.Task
+}
+
+// Board колонки идут в порядке model.Statuses, задачи внутри колонки в порядке рангов
+type Board struct {
+	CompanyID uint
+	Columns   []BoardColumn
+}
+
+// GetBoard доска компании. В каждой колонке до limit первых задач и общее количество
+func (s *TaskService) GetBoard(ctx context.Context, companyID uint, limit int, userID uint) (*Board, error) {
+	if err := s.validateBoardAccess(ctx, companyID, userID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > MaxBoardColumnLimit {
+		limit = DefaultBoardColumnLimit
+	}
+
+	board := &Board{CompanyID: companyID, Columns: make([]BoardColumn, 0, len(model.Statuses))}
+	for _, status := range model.Statuses {
+		tasks, total, err := s.taskRepo.GetBoardColumn(ctx, companyID, status, limit)
+		if err != nil {
+			s.logger.Error("failed to get board column", zap.String("status", string(status)), zap.Error(err))
+			return nil, err
+		}
+		if err := s.fillRelations(ctx, tasks...); err != nil {
+			return nil, err
+		}
+		board.Columns = append(board.Columns, BoardColumn{Status: status, Total: total, Tasks: tasks})
+	}
+	return board, nil
+}
+
+// AuthorizeBoard проверяет, что пользователь может смотреть доску компании. Используется и сокетами
+func (s *TaskService) AuthorizeBoard(ctx context.Context, companyID uint, userID uint) error {
+	return s.validateBoardAccess(ctx, companyID, userID)
+}
+
+// MoveTask меняет статус и позицию задачи в одной транзакции и сообщает о перемещении остальным
+func (s *TaskService) MoveTask(ctx context.Context, input MoveTaskInput, userID uint) (*model.Task, error) {
+	if !slices.Contains(model.Statuses, input.Status) {
+		return nil, domainerrors.NewValidationError("Invalid task status").
+			WithMeta("status", input.Status).
+			WithMeta("allowed", model.Statuses)
+	}
+	if input.TaskID == input.PrevTaskID || input.TaskID == input.NextTaskID {
+		return nil, domainerrors.NewValidationError("Task cannot be its own neighbour")
+	}
+
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		moved      *model.Task
+		fromStatus model.Status
+	)
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		task, err := s.taskRepo.GetByIDForUpdate(ctx, input.TaskID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkMember(ctx, userID, task.CompanyID); err != nil {
+			return err
+		}
+		if !canExecute(user, task) {
+			return domainerrors.NewForbiddenError("only executor or creator can move task")
+		}
+
+		fromStatus = task.Status
+		if task.Status != input.Status {
+			if !user.Can(rbac.TaskChangeStatus) {
+				return domainerrors.NewForbiddenError("dont have permission")
+			}
+			if err := s.changeStatus(ctx, task, input.Status); err != nil {
+				return err
+			}
+		}
+
+		rank, err := s.boardRank(ctx, task, input)
+		if err != nil {
+			return err
+		}
+		task.BoardRank = rank
+
+		return s.taskRepo.UpdateBoardPosition(ctx, task)
+	})
+	if err != nil {
+		s.logger.Error("failed to move task", zap.Uint("taskID", input.TaskID), zap.Error(err))
+		return nil, err
+	}
+
+	// перечитывается после фиксации, чтобы ответ и событие несли задачу целиком
+	moved, err = s.taskRepo.GetByID(ctx, input.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillRelations(ctx, moved); err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(ctx, event.Event{
+		Type:      event.TaskMoved,
+		CompanyID: moved.CompanyID,
+		ActorID:   userID,
+		Payload: event.TaskMovedPayload{
+			Task:       moved,
+			FromStatus: fromStatus,
+			PrevTaskID: input.PrevTaskID,
+			NextTaskID: input.NextTaskID,
+		},
+	})
+	if moved.Status == model.CompletedStatus && fromStatus != model.CompletedStatus {
+		s.notifyDependents(ctx, moved, userID)
+	}
+	return moved, nil
+}
+
+// changeStatus единственное место смены статуса задачи: проверка перехода, блокирующих задач и время завершения
+func (s *TaskService) changeStatus(ctx context.Context, task *model.Task, status model.Status) error {
+	if !task.Status.CanTransitionTo(status) {
+		return domainerrors.NewValidationError("Status transition is not allowed").
+			WithMeta("from", task.Status).
+			WithMeta("to", status).
+			WithMeta("allowed", task.Status.AllowedTransitions())
+	}
+
+	if status == model.InWorkStatus {
+		blockers, err := s.dependencyRepo.GetOpenBlockers(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		if len(blockers) > 0 {
+			ids := make([]uint, 0, len(blockers))
+			for _, blocker := range blockers {
+				ids = append(ids, blocker.ID)
+			}
+			return domainerrors.NewConflictError("task is blocked by unfinished tasks").WithMeta("blockedBy", ids)
+		}
+	}
+
+	task.Status = status
+	if status == model.CompletedStatus {
+		task.CompletedAt = time.Now()
+	} else {
+		task.CompletedAt = time.Time{}
+	}
+	return nil
+}
+
+// boardRank ранг между соседями из запроса. Соседи должны стоять рядом в целевой колонке,
+// иначе клиент видел устаревшую доску и получает конфликт
+func (s *TaskService) boardRank(ctx context.Context, task *model.Task, input MoveTaskInput) (string, error) {
+	prev, next, err := s.boardNeighbours(ctx, task, input)
+	if err != nil {
+		return "", err
+	}
+
+	if prev == nil && next == nil {
+		last, err := s.taskRepo.GetLastRank(ctx, task.CompanyID, input.Status, task.ID)
+		if err != nil {
+			return "", err
+		}
+		return s.rankBetween(ctx, task, input, last, "")
+	}
+
+	between, err := s.taskRepo.CountBetween(ctx, task.CompanyID, input.Status, task.ID, prev, next)
+	if err != nil {
+		return "", err
+	}
+	if between > 0 {
+		return "", domainerrors.NewConflictError("board has changed, reload it")
+	}
+
+	// задачи без ранга (созданные до появления доски) или с равными рангами не дают места для вставки
+	if (prev != nil && prev.BoardRank == "") || (next != nil && next.BoardRank == "") ||
+		(prev != nil && next != nil && prev.BoardRank >= next.BoardRank) {
+		if err := s.taskRepo.RebalanceColumn(ctx, task.CompanyID, input.Status); err != nil {
+			return "", err
+		}
+		if prev, next, err = s.boardNeighbours(ctx, task, input); err != nil {
+			return "", err
+		}
+	}
+	return s.rankBetween(ctx, task, input, rankOf(prev), rankOf(next))
+}
+
+// rankBetween при слишком длинном ранге колонка перенумеровывается и ранг считается заново
+func (s *TaskService) rankBetween(ctx context.Context, task *model.Task, input MoveTaskInput, prev, next string) (string, error) {
+	rank, err := valueobject.RankBetween(prev, next)
+	if err == nil && len(rank) <= valueobject.MaxRankLength {
+		return rank, nil
+	}
+
+	s.logger.Info("rebalancing board column",
+		zap.Uint("companyID", task.CompanyID),
+		zap.String("status", string(input.Status)),
+	)
+	if err := s.taskRepo.RebalanceColumn(ctx, task.CompanyID, input.Status); err != nil {
+		return "", err
+	}
+
+	if input.PrevTaskID == 0 && input.NextTaskID == 0 {
+		last, err := s.taskRepo.GetLastRank(ctx, task.CompanyID, input.Status, task.ID)
+		if err != nil {
+			return "", err
+		}
+		return valueobject.RankBetween(last, "")
+	}
+	prevTask, nextTask, err := s.boardNeighbours(ctx, task, input)
+	if err != nil {
+		return "", err
+	}
+	return valueobject.RankBetween(rankOf(prevTask), rankOf(nextTask))
+}
+
+func (s *TaskService) boardNeighbours(ctx context.Context, task *model.Task, input MoveTaskInput) (*model.Task, *model.Task, error) {
+	prev, err := s.boardNeighbour(ctx, task, input.Status, input.PrevTaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	next, err := s.boardNeighbour(ctx, task, input.Status, input.NextTaskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prev, next, nil
+}
+
+func (s *TaskService) boardNeighbour(ctx context.Context, task *model.Task, status model.Status, id uint) (*model.Task, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	neighbour, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if neighbour.CompanyID != task.CompanyID {
+		return nil, domainerrors.NewValidationError("Neighbour task belongs to another company").WithMeta("taskId", id)
+	}
+	if neighbour.Status != status {
+		return nil, domainerrors.NewConflictError("board has changed, reload it").WithMeta("taskId", id)
+	}
+	return neighbour, nil
+}
+
+func (s *TaskService) validateBoardAccess(ctx context.Context, companyID uint, userID uint) error {
+	if err := s.validateCreator(ctx, userID, rbac.TaskList); err != nil {
+		return err
+	}
+	return s.checkMember(ctx, userID, companyID)
+}
+
+// checkMember в отличие от checkUserInCompany отвечает 403: речь о доступе самого пользователя
+func (s *TaskService) checkMember(ctx context.Context, userID uint, companyID uint) error {
+	inCompany, err := s.userRepo.IsUserInCompany(ctx, userID, companyID)
+	if err != nil {
+		return err
+	}
+	if !inCompany {
+		return domainerrors.NewForbiddenError("user not in company").WithMeta("companyId", companyID)
+	}
+	return nil
+}
+
+func rankOf(task *model.Task) string {
+	if task == nil {
+		return ""
+	}
+	return task.BoardRank
+}
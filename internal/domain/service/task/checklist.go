@@ -0,0 +1,126 @@
+package task
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const maxChecklistTitleLength = 500
+
+// AddChecklistItem добавляет пункт в конец чек-листа
+func (s *TaskService) AddChecklistItem(ctx context.Context, taskID uint, title string, userID uint) (*model.ChecklistItem, error) {
+	task, user, err := s.taskForUser(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(user, task) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+	title, err = validateChecklistTitle(title)
+	if err != nil {
+		return nil, err
+	}
+
+	var item *model.ChecklistItem
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.taskRepo.GetByIDForUpdate(ctx, taskID); err != nil {
+			return err
+		}
+		position, err := s.checklistRepo.NextPosition(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		item, err = s.checklistRepo.Create(ctx, &model.ChecklistItem{
+			TaskID:   taskID,
+			Title:    title,
+			Position: position,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to add checklist item", zap.Uint("taskID", taskID), zap.Error(err))
+		return nil, err
+	}
+	return item, nil
+}
+
+// UpdateChecklistItem переименование требует прав на редактирование задачи,
+// отметка о выполнении доступна и исполнителю
+func (s *TaskService) UpdateChecklistItem(ctx context.Context, input ChecklistItemInput, userID uint) (*model.ChecklistItem, error) {
+	task, user, err := s.taskForUser(ctx, input.TaskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.checklistRepo.GetByID(ctx, input.TaskID, input.ItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Title != nil {
+		if !canEdit(user, task) {
+			return nil, domainerrors.NewForbiddenError("dont have permission")
+		}
+		if item.Title, err = validateChecklistTitle(*input.Title); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Done != nil && *input.Done != item.Done {
+		if !canExecute(user, task) {
+			return nil, domainerrors.NewForbiddenError("only executor or creator can check items")
+		}
+		item.Done = *input.Done
+		if item.Done {
+			item.DoneByID = userID
+			item.DoneAt = time.Now()
+		} else {
+			item.DoneByID = 0
+			item.DoneAt = time.Time{}
+		}
+	}
+
+	if err := s.checklistRepo.Update(ctx, item); err != nil {
+		s.logger.Error("failed to update checklist item", zap.Uint("itemID", input.ItemID), zap.Error(err))
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *TaskService) DeleteChecklistItem(ctx context.Context, taskID uint, itemID uint, userID uint) error {
+	task, user, err := s.taskForUser(ctx, taskID, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit(user, task) {
+		return domainerrors.NewForbiddenError("dont have permission")
+	}
+
+	item, err := s.checklistRepo.GetByID(ctx, taskID, itemID)
+	if err != nil {
+		return err
+	}
+	if err := s.checklistRepo.Delete(ctx, item); err != nil {
+		s.logger.Error("failed to delete checklist item", zap.Uint("itemID", itemID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func validateChecklistTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", domainerrors.NewValidationError("Checklist item title is required")
+	}
+	if utf8.RuneCountInString(title) > maxChecklistTitleLength {
+		return "", domainerrors.NewValidationError("Checklist item title is too long").
+			WithMeta("maxLength", maxChecklistTitleLength)
+	}
+	return title, nil
+}
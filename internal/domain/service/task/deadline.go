@@ -0,0 +1,197 @@
+package task
+
+import (
+	"context"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// deadlineBatch сколько задач обрабатывается по одному напоминанию за проход, остальные в следующий
+	deadlineBatch = 500
+	// overdueLookback задачи, просроченные раньше, не считаются: иначе первый запуск разошлет
+	// напоминания по всем старым задачам
+	overdueLookback = 7 * 24 * time.Hour
+)
+
+type DeadlineOptions struct {
+	// Offsets за сколько до срока напоминать
+	Offsets []time.Duration
+	// Escalate переводить просроченные задачи в статус "Срочная"
+	Escalate bool
+}
+
+// ProcessDeadlines рассылает напоминания о приближающихся сроках и просрочке исполнителям и автору.
+// Каждое напоминание отмечается в БД до публикации, поэтому при нескольких экземплярах приложения
+// и перезапусках оно отправляется один раз
+func (s *TaskService) ProcessDeadlines(ctx context.Context, now time.Time, opts DeadlineOptions) (int, error) {
+	offsets := slices.Clone(opts.Offsets)
+	slices.Sort(offsets)
+
+	sent := 0
+	// окно каждого напоминания до следующего меньшего: задача, созданная за 30 минут до срока,
+	// получает только часовое напоминание, а не сразу все
+	lower := time.Duration(0)
+	for _, ahead := range offsets {
+		if ahead <= 0 {
+			continue
+		}
+		count, err := s.remind(ctx, ahead, now.Add(lower), now.Add(ahead), opts)
+		sent += count
+		if err != nil {
+			return sent, err
+		}
+		lower = ahead
+	}
+
+	count, err := s.remind(ctx, 0, now.Add(-overdueLookback), now, opts)
+	return sent + count, err
+}
+
+func (s *TaskService) remind(ctx context.Context, ahead time.Duration, from time.Time, to time.Time, opts DeadlineOptions) (int, error) {
+	tasks, err := s.reminderRepo.GetDueTasks(ctx, ahead, from, to, deadlineBatch)
+	if err != nil {
+		s.logger.Error("failed to get tasks with due deadline", zap.Duration("ahead", ahead), zap.Error(err))
+		return 0, err
+	}
+
+	sent := 0
+	for _, task := range tasks {
+		ok, err := s.sendReminder(ctx, task, ahead, opts.Escalate)
+		if err != nil {
+			s.logger.Error("failed to send deadline reminder", zap.Uint("taskID", task.ID), zap.Error(err))
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendReminder отмечает напоминание и при просрочке переводит задачу в "Срочная" в одной транзакции,
+// события публикуются после фиксации. false, если напоминание уже отправил другой экземпляр
+// или после выборки задачу выполнили либо перенесли срок
+func (s *TaskService) sendReminder(ctx context.Context, task *model.Task, ahead time.Duration, escalate bool) (bool, error) {
+	var (
+		created    bool
+		fromStatus = task.Status
+	)
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		locked, err := s.taskRepo.GetByIDForUpdate(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status == model.CompletedStatus || !locked.DeadlineAt.Equal(task.DeadlineAt) {
+			return nil
+		}
+		task.Status, fromStatus = locked.Status, locked.Status
+
+		created, err = s.reminderRepo.Create(ctx, &model.TaskReminder{
+			TaskID:     task.ID,
+			Ahead:      ahead,
+			DeadlineAt: task.DeadlineAt,
+			SentAt:     time.Now(),
+		})
+		if err != nil || !created || ahead != 0 || !escalate {
+			return err
+		}
+		return s.escalate(ctx, task, locked)
+	})
+	if err != nil || !created {
+		return false, err
+	}
+
+	escalated := task.Status != fromStatus
+	if escalated {
+		if moved, err := s.taskRepo.GetByID(ctx, task.ID); err == nil && s.fillRelations(ctx, moved) == nil {
+			task = moved
+		}
+		s.events.Publish(ctx, event.Event{
+			Type:      event.TaskMoved,
+			CompanyID: task.CompanyID,
+			Payload:   event.TaskMovedPayload{Task: task, FromStatus: fromStatus},
+		})
+	}
+
+	eventType := event.DeadlineReminder
+	if ahead == 0 {
+		eventType = event.TaskOverdue
+	}
+	s.events.Publish(ctx, event.Event{
+		Type:      eventType,
+		CompanyID: task.CompanyID,
+		Payload: event.DeadlinePayload{
+			Task:       task,
+			Ahead:      ahead,
+			Recipients: compactIDs(append(task.ExecutorIDs(), task.CreatorID)),
+			Escalated:  escalated,
+		},
+	})
+	return true, nil
+}
+
+// escalate переводит просроченную задачу в конец колонки "Срочная", если переход допустим.
+// locked задача, перечитанная под блокировкой: ее могли перевести после выборки
+func (s *TaskService) escalate(ctx context.Context, task *model.Task, locked *model.Task) error {
+	if locked.Status == model.ImmediateStatus || !locked.Status.CanTransitionTo(model.ImmediateStatus) {
+		return nil
+	}
+	if err := s.changeStatus(ctx, locked, model.ImmediateStatus); err != nil {
+		return err
+	}
+
+	rank, err := s.boardRank(ctx, locked, MoveTaskInput{TaskID: locked.ID, Status: locked.Status})
+	if err != nil {
+		return err
+	}
+	locked.BoardRank = rank
+	if err := s.taskRepo.UpdateBoardPosition(ctx, locked); err != nil {
+		return err
+	}
+	task.Status = locked.Status
+	return nil
+}
+
+// DeadlineScheduler периодически рассылает напоминания о сроках
+type DeadlineScheduler struct {
+	service *TaskService
+	opts    DeadlineOptions
+	logger  *zap.Logger
+}
+
+func NewDeadlineScheduler(service *TaskService, opts DeadlineOptions, logger *zap.Logger) *DeadlineScheduler {
+	return &DeadlineScheduler{
+		service: service,
+		opts:    opts,
+		logger:  logger,
+	}
+}
+
+func (d *DeadlineScheduler) Run(ctx context.Context) {
+	sent, err := d.service.ProcessDeadlines(ctx, time.Now(), d.opts)
+	if err != nil {
+		d.logger.Error("deadline scheduler failed", zap.Int("sent", sent), zap.Error(err))
+		return
+	}
+	d.logger.Info("deadline scheduler finished", zap.Int("sent", sent))
+}
+
+// Start проверяет сроки по расписанию до отмены контекста
+func (d *DeadlineScheduler) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Run(ctx)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package task
+
+import (
+	"context"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/repository/repotest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fakeReminderRepo отдает задачи в том виде, в каком они были на момент выборки
+type fakeReminderRepo struct {
+	repository.ReminderRepository
+	due       []*model.Task
+	reminders []*model.TaskReminder
+}
+
+func (r *fakeReminderRepo) GetDueTasks(ctx context.Context, ahead time.Duration, from time.Time, to time.Time, limit int) ([]*model.Task, error) {
+	if ahead == 0 {
+		return nil, nil
+	}
+	return r.due, nil
+}
+
+func (r *fakeReminderRepo) Create(ctx context.Context, reminder *model.TaskReminder) (bool, error) {
+	r.reminders = append(r.reminders, reminder)
+	return true, nil
+}
+
+func TestProcessDeadlinesRechecksTask(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	deadline := now.Add(30 * time.Minute)
+
+	tests := []struct {
+		name       string
+		change     func(task *model.Task) // что случилось с задачей между выборкой и отправкой
+		wantRemind bool
+	}{
+		{name: "unchanged", change: func(task *model.Task) {}, wantRemind: true},
+		{name: "moved to another column", change: func(task *model.Task) { task.Status = model.InWorkStatus }, wantRemind: true},
+		{name: "completed", change: func(task *model.Task) { task.Status = model.CompletedStatus }},
+		{name: "deadline postponed", change: func(task *model.Task) { task.DeadlineAt = deadline.Add(24 * time.Hour) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newTaskTree()
+			stored := tree.tasks[6]
+			stored.ExecutorID = editorID
+			stored.DeadlineAt = deadline
+			selected := *stored
+			tt.change(stored)
+
+			reminders := &fakeReminderRepo{due: []*model.Task{&selected}}
+			logger := zap.NewNop()
+			bus := event.NewBus(logger)
+			var events []event.Event
+			bus.Subscribe(event.DeadlineReminder, func(ctx context.Context, e event.Event) {
+				events = append(events, e)
+			})
+			service := NewTaskService(tree, nil, &repotest.DependencyRepo{}, nil, nil, reminders, nil, nil, repotest.UnitOfWork{},
+				nil, nil, nil, bus, logger)
+
+			sent, err := service.ProcessDeadlines(context.Background(), now, DeadlineOptions{Offsets: []time.Duration{time.Hour}})
+			if err != nil {
+				t.Fatalf("ProcessDeadlines: %v", err)
+			}
+			if !tt.wantRemind {
+				if sent != 0 || len(reminders.reminders) != 0 || len(events) != 0 {
+					t.Fatalf("sent %d, %d reminders, %d events, want none", sent, len(reminders.reminders), len(events))
+				}
+				return
+			}
+			if sent != 1 || len(reminders.reminders) != 1 || len(events) != 1 {
+				t.Fatalf("sent %d, %d reminders, %d events, want one", sent, len(reminders.reminders), len(events))
+			}
+			if task := events[0].Payload.(event.DeadlinePayload).Task; task.Status != stored.Status {
+				t.Errorf("reminder status = %q, want %q", task.Status, stored.Status)
+			}
+		})
+	}
+}
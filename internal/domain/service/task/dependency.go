@@ -0,0 +1,107 @@
+package task
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+
+	"go.uber.org/zap"
+)
+
+// AddDependency задача taskID не сможет перейти в работу, пока blockerID не выполнена
+func (s *TaskService) AddDependency(ctx context.Context, taskID uint, blockerID uint, userID uint) (*model.Task, error) {
+	if taskID == blockerID {
+		return nil, domainerrors.NewValidationError("Task cannot block itself")
+	}
+
+	task, user, err := s.taskForUser(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(user, task) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+
+	blocker, err := s.taskRepo.GetByID(ctx, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	if blocker.CompanyID != task.CompanyID {
+		return nil, domainerrors.NewValidationError("Blocking task belongs to another company").WithMeta("blockerId", blockerID)
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		// без блокировки два встречных добавления могли бы вместе замкнуть цикл
+		if err := s.dependencyRepo.LockGraph(ctx, task.CompanyID); err != nil {
+			return err
+		}
+		cycle, err := s.dependencyRepo.IsBlockedBy(ctx, blockerID, taskID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return domainerrors.NewValidationError("Dependency creates a cycle").
+				WithMeta("taskId", taskID).
+				WithMeta("blockerId", blockerID)
+		}
+		return s.dependencyRepo.Create(ctx, &model.TaskDependency{TaskID: taskID, BlockerID: blockerID})
+	})
+	if err != nil {
+		s.logger.Error("failed to add task dependency", zap.Uint("taskID", taskID), zap.Uint("blockerID", blockerID), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.fillRelations(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *TaskService) RemoveDependency(ctx context.Context, taskID uint, blockerID uint, userID uint) (*model.Task, error) {
+	task, user, err := s.taskForUser(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(user, task) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+
+	if err := s.dependencyRepo.Delete(ctx, taskID, blockerID); err != nil {
+		s.logger.Error("failed to remove task dependency", zap.Uint("taskID", taskID), zap.Uint("blockerID", blockerID), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.fillRelations(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// notifyDependents сообщает исполнителям ожидающих задач, что блокер выполнен.
+// Ошибки только логируются: перемещение уже зафиксировано
+func (s *TaskService) notifyDependents(ctx context.Context, blocker *model.Task, actorID uint) {
+	dependents, err := s.dependencyRepo.GetDependents(ctx, blocker.ID)
+	if err != nil {
+		s.logger.Error("failed to get dependent tasks", zap.Uint("taskID", blocker.ID), zap.Error(err))
+		return
+	}
+
+	for _, dependent := range dependents {
+		open, err := s.dependencyRepo.GetOpenBlockers(ctx, dependent.ID)
+		if err != nil {
+			s.logger.Error("failed to get open blockers", zap.Uint("taskID", dependent.ID), zap.Error(err))
+			continue
+		}
+		s.events.Publish(ctx, event.Event{
+			Type:      event.BlockerCompleted,
+			CompanyID: dependent.CompanyID,
+			ActorID:   actorID,
+			Payload: event.BlockerCompletedPayload{
+				Task:         dependent,
+				Blocker:      blocker,
+				OpenBlockers: len(open),
+			},
+		})
+	}
+}
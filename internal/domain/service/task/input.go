@@ -1,6 +1,7 @@
 package task
 
 import (
+	"rttask/internal/domain/model"
 	"time"
 )
 
@@ -11,5 +12,56 @@ type TaskInput struct {
 	Description string
 	Priority    uint
 	ExecutorID  uint
+	// ExecutorIDs дополнительные исполнители, WatcherIDs наблюдатели
+	ExecutorIDs []uint
+	WatcherIDs  []uint
 	CompanyID   uint
+	// ParentID 0 для корневой задачи
+	ParentID uint
+	FileIDs  []string
+	// Checklist пункты чек-листа новой задачи
+	Checklist []string
+	// CopyFiles уже сохраненные файлы, например шаблона: в задачу попадают их копии
+	CopyFiles []*model.File
+}
+
+// MoveTaskInput новое место задачи на доске. Соседи задают позицию в целевой колонке:
+// PrevTaskID задача сразу над перемещаемой, NextTaskID сразу под ней. Без соседей задача встает в конец колонки
+type MoveTaskInput struct {
+	TaskID     uint
+	Status     model.Status
+	PrevTaskID uint
+	NextTaskID uint
+}
+
+// ChecklistItemInput nil поля не меняются
+type ChecklistItemInput struct {
+	TaskID uint
+	ItemID uint
+	Title  *string
+	Done   *bool
+}
+
+// AssigneesInput первый из ExecutorIDs становится основным исполнителем
+type AssigneesInput struct {
+	TaskID      uint
+	ExecutorIDs []uint
+	WatcherIDs  []uint
+}
+
+// RecurrenceInput шаблон повторяющейся задачи. StartAt начало первого повторения,
+// Duration срок каждой задачи от ее начала
+type RecurrenceInput struct {
+	Title       string
+	Description string
+	Priority    uint
+	ExecutorID  uint
+	ExecutorIDs []uint
+	WatcherIDs  []uint
+	CompanyID   uint
+	RRule       string
+	// Timezone имя из базы IANA, пусто для UTC
+	Timezone string
+	StartAt  time.Time
+	Duration time.Duration
 }
@@ -0,0 +1,267 @@
+package task
+
+import (
+	"context"
+	"errors"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/valueobject"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxRecurrenceBatch сколько задач создается по одному шаблону за проход, остальные в следующий
+const maxRecurrenceBatch = 100
+
+func (s *TaskService) CreateRecurrence(ctx context.Context, input RecurrenceInput, userID uint) (*model.Recurrence, error) {
+	if err := s.validateCreator(ctx, userID, rbac.TaskCreate, rbac.TaskAssign); err != nil {
+		return nil, err
+	}
+	if err := s.checkMember(ctx, userID, input.CompanyID); err != nil {
+		return nil, err
+	}
+
+	rule, err := valueobject.ParseRRule(input.RRule)
+	if err != nil {
+		return nil, err
+	}
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(input.Timezone); err != nil {
+		return nil, domainerrors.NewValidationError("Unknown timezone").WithMeta("timezone", input.Timezone)
+	}
+	if input.Duration <= 0 {
+		return nil, domainerrors.NewValidationError("Duration must be positive")
+	}
+	if err := s.validateTimeRange(input.StartAt, input.StartAt.Add(input.Duration)); err != nil {
+		return nil, err
+	}
+
+	executors := compactIDs(append([]uint{input.ExecutorID}, input.ExecutorIDs...))
+	watchers := compactIDs(input.WatcherIDs)
+	if len(executors) == 0 {
+		return nil, domainerrors.NewValidationError("Executor is required")
+	}
+	if err := s.validateAssignees(ctx, executors, watchers, input.CompanyID); err != nil {
+		s.logger.Error("failed to validate assignees", zap.Error(err))
+		return nil, err
+	}
+	if _, err := s.mentionService.Resolve(ctx, input.CompanyID, input.Description); err != nil {
+		return nil, err
+	}
+
+	recurrence := &model.Recurrence{
+		CompanyID:   input.CompanyID,
+		CreatorID:   userID,
+		Title:       input.Title,
+		Description: input.Description,
+		Priority:    input.Priority,
+		ExecutorID:  executors[0],
+		ExecutorIDs: executors[1:],
+		WatcherIDs:  watchers,
+		RRule:       rule.String(),
+		Timezone:    input.Timezone,
+		StartAt:     input.StartAt,
+		Duration:    input.Duration,
+		Active:      true,
+	}
+	return s.recurrenceRepo.Create(ctx, recurrence)
+}
+
+func (s *TaskService) ListRecurrences(ctx context.Context, companyID uint, userID uint) ([]*model.Recurrence, error) {
+	if err := s.validateBoardAccess(ctx, companyID, userID); err != nil {
+		return nil, err
+	}
+	return s.recurrenceRepo.ListByCompany(ctx, companyID)
+}
+
+// DeleteRecurrence останавливает повторения. Уже созданные задачи остаются
+func (s *TaskService) DeleteRecurrence(ctx context.Context, id uint, userID uint) error {
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	recurrence, err := s.recurrenceRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkMember(ctx, userID, recurrence.CompanyID); err != nil {
+		return err
+	}
+	if recurrence.CreatorID != userID && !user.CanAll(rbac.TaskDelete) {
+		return domainerrors.NewForbiddenError("dont have permission")
+	}
+	return s.recurrenceRepo.Delete(ctx, recurrence)
+}
+
+// MaterializeRecurrences создает задачи по всем шаблонам на horizon вперед от now.
+// Каждый шаблон обрабатывается в своей транзакции под блокировкой строки, поэтому несколько
+// экземпляров приложения не создают одно повторение дважды, а после перезапуска работа продолжается с GeneratedUntil
+func (s *TaskService) MaterializeRecurrences(ctx context.Context, now time.Time, horizon time.Duration) (int, error) {
+	until := now.Add(horizon)
+	ids, err := s.recurrenceRepo.GetDueIDs(ctx, until)
+	if err != nil {
+		s.logger.Error("failed to get due recurrences", zap.Error(err))
+		return 0, err
+	}
+
+	created := 0
+	for _, id := range ids {
+		count, err := s.materializeRecurrence(ctx, id, until)
+		if err != nil {
+			s.logger.Error("failed to materialize recurrence", zap.Uint("recurrenceID", id), zap.Error(err))
+			continue
+		}
+		created += count
+	}
+	return created, nil
+}
+
+func (s *TaskService) materializeRecurrence(ctx context.Context, id uint, until time.Time) (int, error) {
+	var created []*model.Task
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		recurrence, err := s.recurrenceRepo.LockDue(ctx, id, until)
+		if err != nil || recurrence == nil {
+			return err
+		}
+
+		tasks, err := s.recurrenceTasks(ctx, recurrence, until)
+		if err != nil {
+			// шаблон больше нельзя выполнить, например исполнитель ушел из компании: он останавливается
+			var domainErr *domainerrors.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Type != domainerrors.ErrorTypeValidation && domainErr.Type != domainerrors.ErrorTypeNotFound {
+				return err
+			}
+			s.logger.Warn("recurrence stopped", zap.Uint("recurrenceID", id), zap.Error(err))
+			recurrence.Active = false
+			recurrence.LastError = domainErr.Message
+			return s.recurrenceRepo.Update(ctx, recurrence)
+		}
+
+		// упомянутые участники могли уйти из компании после создания шаблона, такие упоминания пропускаются
+		mentions, err := s.mentionService.ResolveKnown(ctx, recurrence.CompanyID, recurrence.Description)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if _, err := s.insertTask(ctx, task); err != nil {
+				return err
+			}
+			if task.Mentions, err = s.mentionService.Save(ctx, model.MentionInTask, task.ID, task.ID, mentions); err != nil {
+				return err
+			}
+		}
+		created = tasks
+		return s.recurrenceRepo.Update(ctx, recurrence)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, task := range created {
+		s.publishCreated(ctx, task, task.ExecutorIDs(), task.WatcherIDs(), 0)
+		s.publishMentioned(ctx, task, model.MentionedUserIDs(task.Mentions), 0)
+	}
+	return len(created), nil
+}
+
+// recurrenceTasks задачи для повторений шаблона до until. Сдвигает GeneratedUntil и снимает
+// Active, когда повторений больше не будет
+func (s *TaskService) recurrenceTasks(ctx context.Context, recurrence *model.Recurrence, until time.Time) ([]*model.Task, error) {
+	rule, err := valueobject.ParseRRule(recurrence.RRule)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(recurrence.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	dtstart := recurrence.StartAt.In(location)
+	after := recurrence.GeneratedUntil
+	if after.IsZero() {
+		after = dtstart.Add(-time.Nanosecond)
+	}
+	occurrences := rule.Between(dtstart, after, until)
+	if len(occurrences) > maxRecurrenceBatch {
+		occurrences = occurrences[:maxRecurrenceBatch]
+		until = occurrences[len(occurrences)-1]
+	}
+
+	executors := compactIDs(append([]uint{recurrence.ExecutorID}, recurrence.ExecutorIDs...))
+	if len(occurrences) > 0 {
+		if err := s.validateAssignees(ctx, executors, recurrence.WatcherIDs, recurrence.CompanyID); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks := make([]*model.Task, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		task := &model.Task{
+			Title:        recurrence.Title,
+			Description:  recurrence.Description,
+			StartAt:      occurrence,
+			DeadlineAt:   occurrence.Add(recurrence.Duration),
+			Priority:     recurrence.Priority,
+			ExecutorID:   recurrence.ExecutorID,
+			CompanyID:    recurrence.CompanyID,
+			CreatorID:    recurrence.CreatorID,
+			Status:       model.CreatedStatus,
+			Assignees:    buildAssignees(executors, recurrence.WatcherIDs, nil),
+			RecurrenceID: &recurrence.ID,
+			OccurrenceAt: &occurrence,
+		}
+		tasks = append(tasks, task)
+	}
+
+	recurrence.GeneratedUntil = until
+	recurrence.Generated += len(tasks)
+	if rule.Finished(dtstart, until) {
+		recurrence.Active = false
+	}
+	return tasks, nil
+}
+
+// RecurrenceScheduler периодически создает задачи по шаблонам повторения
+type RecurrenceScheduler struct {
+	service *TaskService
+	horizon time.Duration
+	logger  *zap.Logger
+}
+
+func NewRecurrenceScheduler(service *TaskService, horizon time.Duration, logger *zap.Logger) *RecurrenceScheduler {
+	return &RecurrenceScheduler{
+		service: service,
+		horizon: horizon,
+		logger:  logger,
+	}
+}
+
+func (r *RecurrenceScheduler) Run(ctx context.Context) {
+	created, err := r.service.MaterializeRecurrences(ctx, time.Now(), r.horizon)
+	if err != nil {
+		r.logger.Error("recurrence scheduler failed", zap.Error(err))
+		return
+	}
+	r.logger.Info("recurrence scheduler finished", zap.Int("created", created))
+}
+
+// Start первый проход сразу при запуске, затем по расписанию до отмены контекста
+func (r *RecurrenceScheduler) Start(ctx context.Context, interval time.Duration) {
+	r.Run(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Run(ctx)
+		}
+	}
+}
@@ -5,30 +5,52 @@ import (
 	"errors"
 	"fmt"
 	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
 	"rttask/internal/domain/model"
 	"rttask/internal/domain/model/rbac"
 	"rttask/internal/domain/repository"
 	"rttask/internal/domain/service/file"
+	"rttask/internal/domain/service/mention"
+	"rttask/internal/domain/valueobject"
+	"slices"
 	"time"
 
 	"go.uber.org/zap"
 )
 
 type TaskService struct {
-	taskRepo    repository.TaskRepository
-	userRepo    repository.UserRepository
-	companyRepo repository.CompanyRepository
-	fileService *file.FileService
-	logger      *zap.Logger
+	taskRepo       repository.TaskRepository
+	checklistRepo  repository.ChecklistRepository
+	dependencyRepo repository.DependencyRepository
+	assigneeRepo   repository.AssigneeRepository
+	recurrenceRepo repository.RecurrenceRepository
+	reminderRepo   repository.ReminderRepository
+	userRepo       repository.UserRepository
+	companyRepo    repository.CompanyRepository
+	uow            repository.UnitOfWork
+	fileService    *file.FileService
+	uploadService  *file.UploadService
+	mentionService *mention.MentionService
+	events         event.Publisher
+	logger         *zap.Logger
 }
 
-func NewTaskService(taskRepo repository.TaskRepository, userRepo repository.UserRepository, companyRepo repository.CompanyRepository, fileService *file.FileService, logger *zap.Logger) *TaskService {
+func NewTaskService(taskRepo repository.TaskRepository, checklistRepo repository.ChecklistRepository, dependencyRepo repository.DependencyRepository, assigneeRepo repository.AssigneeRepository, recurrenceRepo repository.RecurrenceRepository, reminderRepo repository.ReminderRepository, userRepo repository.UserRepository, companyRepo repository.CompanyRepository, uow repository.UnitOfWork, fileService *file.FileService, uploadService *file.UploadService, mentionService *mention.MentionService, events event.Publisher, logger *zap.Logger) *TaskService {
 	return &TaskService{
-		taskRepo:    taskRepo,
-		userRepo:    userRepo,
-		companyRepo: companyRepo,
-		fileService: fileService,
-		logger:      logger,
+		taskRepo:       taskRepo,
+		checklistRepo:  checklistRepo,
+		dependencyRepo: dependencyRepo,
+		assigneeRepo:   assigneeRepo,
+		recurrenceRepo: recurrenceRepo,
+		reminderRepo:   reminderRepo,
+		userRepo:       userRepo,
+		companyRepo:    companyRepo,
+		uow:            uow,
+		fileService:    fileService,
+		uploadService:  uploadService,
+		mentionService: mentionService,
+		events:         events,
+		logger:         logger,
 	}
 }
 
@@ -45,9 +67,11 @@ func (s *TaskService) CreateTask(ctx context.Context, input TaskInput, filesInpu
 		return nil, err
 	}
 
-	// Валидация исполнителя, что он может менять статус задачи и принадлежит к той же компании
-	if err := s.validateExecutor(ctx, input.ExecutorID, input.CompanyID); err != nil {
-		s.logger.Error("failed to validate executor", zap.Error(err))
+	// Валидация исполнителей, что они могут менять статус задачи и принадлежат к той же компании, и наблюдателей
+	executors := compactIDs(append([]uint{input.ExecutorID}, input.ExecutorIDs...))
+	watchers := compactIDs(input.WatcherIDs)
+	if err := s.validateAssignees(ctx, executors, watchers, input.CompanyID); err != nil {
+		s.logger.Error("failed to validate assignees", zap.Error(err))
 		return nil, err
 	}
 
@@ -57,6 +81,30 @@ func (s *TaskService) CreateTask(ctx context.Context, input TaskInput, filesInpu
 		return nil, err
 	}
 
+	// Упоминать в описании можно только участников компании
+	mentions, err := s.mentionService.Resolve(ctx, input.CompanyID, input.Description)
+	if err != nil {
+		s.logger.Error("failed to resolve mentions", zap.Error(err))
+		return nil, err
+	}
+
+	// Подзадача создается в той же компании, что и родитель, с учетом глубины дерева
+	if input.ParentID != 0 {
+		if err := s.validateParent(ctx, 0, input.ParentID, input.CompanyID); err != nil {
+			s.logger.Error("failed to validate parent task", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	checklist := make([]*model.ChecklistItem, 0, len(input.Checklist))
+	for i, title := range input.Checklist {
+		title, err := validateChecklistTitle(title)
+		if err != nil {
+			return nil, err
+		}
+		checklist = append(checklist, &model.ChecklistItem{Title: title, Position: i + 1})
+	}
+
 	task := &model.Task{
 		Title:       input.Title,
 		Description: input.Description,
@@ -67,27 +115,161 @@ func (s *TaskService) CreateTask(ctx context.Context, input TaskInput, filesInpu
 		CompanyID:   input.CompanyID,
 		CreatorID:   userID,
 		Status:      model.CreatedStatus,
+		Assignees:   buildAssignees(executors, watchers, nil),
+	}
+	if input.ParentID != 0 {
+		task.ParentID = &input.ParentID
+	}
+	if len(checklist) > 0 {
+		task.Checklist = checklist
 	}
 
+	if len(input.CopyFiles) > 0 {
+		if err := s.fileService.CheckQuota(ctx, input.CompanyID, userID, filesSize(input.CopyFiles)); err != nil {
+			return nil, err
+		}
+		copied, err := s.fileService.CopyFiles(ctx, input.CopyFiles, "task", userID)
+		if err != nil {
+			return nil, err
+		}
+		task.Files = copied
+	}
+
+	for i := range filesInput {
+		filesInput[i].CompanyID = input.CompanyID
+	}
 	if len(filesInput) > 0 {
-		for _, fileInput := range filesInput {
-			uploadedFile, err := s.fileService.UploadFile(ctx, fileInput, file.TaskProfile) // нужно создать
-			if err != nil {
-				return nil, err
-			}
-			task.Files = append(task.Files, uploadedFile)
+		uploadedFiles, err := s.fileService.UploadFiles(ctx, filesInput, file.TaskProfile)
+		if err != nil {
+			s.fileService.DeleteFiles(ctx, task.Files...)
+			return nil, err
 		}
+		task.Files = append(task.Files, uploadedFiles...)
 	}
 
-	newTask, err := s.taskRepo.Create(ctx, task)
+	uploadedFiles := task.Files
+
+	var newTask *model.Task
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		// родитель проверен до загрузки файлов, под блокировкой дерева проверка повторяется,
+		// чтобы параллельный перенос родителя не превысил глубину
+		if input.ParentID != 0 {
+			if err := s.taskRepo.LockTree(ctx, input.CompanyID); err != nil {
+				return err
+			}
+			if err := s.validateParent(ctx, 0, input.ParentID, input.CompanyID); err != nil {
+				return err
+			}
+		}
+
+		// квоты проверены до загрузки, здесь проверка повторяется под блокировкой, которую параллельные
+		// загрузки ждут до фиксации транзакции
+		if len(input.FileIDs) > 0 || len(uploadedFiles) > 0 {
+			if err := s.fileService.LockQuota(ctx, input.CompanyID, userID); err != nil {
+				return err
+			}
+		}
+		if len(uploadedFiles) > 0 {
+			if err := s.fileService.CheckQuota(ctx, 0, userID, filesSize(uploadedFiles)); err != nil {
+				return err
+			}
+		}
+
+		// Файлы, загруженные заранее через /uploads, прикрепляются в той же транзакции
+		attached, err := s.uploadService.AttachUploads(ctx, input.FileIDs, userID)
+		if err != nil {
+			return err
+		}
+		if len(attached) > 0 || len(uploadedFiles) > 0 {
+			// квота пользователя для загрузок учтена при их создании, компании становится известна только сейчас
+			if err := s.fileService.CheckQuota(ctx, input.CompanyID, 0, filesSize(attached)+filesSize(uploadedFiles)); err != nil {
+				return err
+			}
+		}
+		task.Files = append(task.Files, attached...)
+
+		if newTask, err = s.insertTask(ctx, task); err != nil {
+			return err
+		}
+		newTask.Mentions, err = s.mentionService.Save(ctx, model.MentionInTask, newTask.ID, newTask.ID, mentions)
+		return err
+	})
 	if err != nil {
 		s.logger.Error("failed to create task", zap.Error(err))
+		s.fileService.DeleteFiles(ctx, uploadedFiles...)
 		return nil, err
 	}
 
+	s.publishCreated(ctx, newTask, executors, watchers, userID)
+	s.publishMentioned(ctx, newTask, model.MentionedUserIDs(newTask.Mentions), userID)
 	return newTask, nil
 }
 
+// insertTask сохраняет задачу в конец ее колонки на доске, вызывается внутри транзакции.
+// Слишком длинный ранг перенумеровывает колонку так же, как перемещение на доске
+func (s *TaskService) insertTask(ctx context.Context, task *model.Task) (*model.Task, error) {
+	last, err := s.taskRepo.GetLastRank(ctx, task.CompanyID, task.Status, 0)
+	if err != nil {
+		return nil, err
+	}
+	if task.BoardRank, err = s.rankBetween(ctx, task, MoveTaskInput{Status: task.Status}, last, ""); err != nil {
+		return nil, err
+	}
+	return s.taskRepo.Create(ctx, task)
+}
+
+// ListTasks задачи компаний пользователя по фильтру. Фильтр по чужой компании запрещен
+func (s *TaskService) ListTasks(ctx context.Context, filter valueobject.TaskFilter, params valueobject.PaginationParams, userID uint) (*valueobject.Page[*model.Task], error) {
+	if err := s.validateCreator(ctx, userID, rbac.TaskList); err != nil {
+		return nil, err
+	}
+	if err := s.validateFilter(filter, params); err != nil {
+		return nil, err
+	}
+	for _, companyID := range filter.CompanyIDs {
+		if err := s.checkMember(ctx, userID, companyID); err != nil {
+			return nil, err
+		}
+	}
+
+	filter.ViewerID = userID
+	tasks, count, err := s.taskRepo.List(ctx, filter, params)
+	if err != nil {
+		s.logger.Error("failed to list tasks", zap.Error(err))
+		return nil, err
+	}
+	if err := s.fillRelations(ctx, tasks...); err != nil {
+		return nil, err
+	}
+
+	if params.CursorMode {
+		return valueobject.NewCursorPage(tasks, count, params, func(task *model.Task) (time.Time, uint) {
+			return task.CreatedAt, task.ID
+		}), nil
+	}
+	return &valueobject.Page[*model.Task]{Items: tasks, Total: count}, nil
+}
+
+func (s *TaskService) validateFilter(filter valueobject.TaskFilter, params valueobject.PaginationParams) error {
+	if params.CursorMode && (len(filter.Sort) > 1 || len(filter.Sort) == 1 && filter.Sort[0].Field != "createdAt") {
+		return domainerrors.NewValidationError("Cursor pagination supports only sorting by createdAt")
+	}
+	for _, status := range filter.Statuses {
+		if !slices.Contains(model.Statuses, status) {
+			return domainerrors.NewValidationError("Invalid task status").
+				WithMeta("status", status).
+				WithMeta("allowed", model.Statuses)
+		}
+	}
+	if filter.StartFrom != nil && filter.StartTo != nil && filter.StartTo.Before(*filter.StartFrom) {
+		return domainerrors.NewValidationError("startTo is before startFrom")
+	}
+	if filter.DeadlineFrom != nil && filter.DeadlineTo != nil && filter.DeadlineTo.Before(*filter.DeadlineFrom) {
+		return domainerrors.NewValidationError("deadlineTo is before deadlineFrom")
+	}
+	return nil
+}
+
 func (s *TaskService) validateCreator(ctx context.Context, userID uint, permissions ...rbac.Permission) error {
 	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
 	if err != nil {
@@ -164,3 +346,11 @@ func (s *TaskService) validateTimeRange(startAt time.Time, deadlineAt time.Time)
 	return nil
 
 }
+
+func filesSize(files []*model.File) int64 {
+	var size int64
+	for _, f := range files {
+		size += f.Size
+	}
+	return size
+}
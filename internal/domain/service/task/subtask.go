@@ -0,0 +1,157 @@
+package task
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// GetTask задача с чек-листом и прогрессом подзадач
+func (s *TaskService) GetTask(ctx context.Context, taskID uint, userID uint) (*model.Task, error) {
+	task, _, err := s.taskForUser(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillRelations(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// SetParent переносит задачу под другую задачу той же компании. parentID 0 делает задачу корневой
+func (s *TaskService) SetParent(ctx context.Context, taskID uint, parentID uint, userID uint) (*model.Task, error) {
+	task, user, err := s.taskForUser(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(user, task) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		// без блокировки дерева встречные переносы A под B и B под A видят старых предков,
+		// оба проходят проверку и вместе собирают цикл или превышают глубину
+		if err := s.taskRepo.LockTree(ctx, task.CompanyID); err != nil {
+			return err
+		}
+
+		var parent *uint
+		if parentID != 0 {
+			if err := s.validateParent(ctx, taskID, parentID, task.CompanyID); err != nil {
+				return err
+			}
+			parent = &parentID
+		}
+		task.ParentID = parent
+		return s.taskRepo.UpdateParent(ctx, taskID, parent)
+	})
+	if err != nil {
+		s.logger.Error("failed to set task parent", zap.Uint("taskID", taskID), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.fillRelations(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// validateParent родитель из той же компании, перенос не создает цикл и не превышает model.MaxTaskDepth.
+// taskID 0 для еще не созданной задачи
+func (s *TaskService) validateParent(ctx context.Context, taskID uint, parentID uint, companyID uint) error {
+	if taskID != 0 && taskID == parentID {
+		return domainerrors.NewValidationError("Task cannot be its own parent")
+	}
+
+	parent, err := s.taskRepo.GetByID(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if parent.CompanyID != companyID {
+		return domainerrors.NewValidationError("Parent task belongs to another company").WithMeta("parentId", parentID)
+	}
+
+	ancestors, err := s.taskRepo.GetAncestorIDs(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if taskID != 0 && slices.Contains(ancestors, taskID) {
+		return domainerrors.NewValidationError("Parent task is a subtask of this task").WithMeta("parentId", parentID)
+	}
+
+	height := 0
+	if taskID != 0 {
+		if height, err = s.taskRepo.GetSubtreeHeight(ctx, taskID); err != nil {
+			return err
+		}
+	}
+	// предки родителя, сам родитель, задача и ее подзадачи
+	if depth := len(ancestors) + 2 + height; depth > model.MaxTaskDepth {
+		return domainerrors.NewValidationError("Subtask depth limit exceeded").
+			WithMeta("depth", depth).
+			WithMeta("maxDepth", model.MaxTaskDepth)
+	}
+	return nil
+}
+
+// fillRelations считает прогресс прямых подзадач и собирает зависимости и упоминания одним запросом на все задачи
+func (s *TaskService) fillRelations(ctx context.Context, tasks ...*model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	progress, err := s.taskRepo.GetSubtaskProgress(ctx, ids)
+	if err != nil {
+		s.logger.Error("failed to get subtask progress", zap.Error(err))
+		return err
+	}
+	blockedBy, blocks, err := s.dependencyRepo.GetLinks(ctx, ids)
+	if err != nil {
+		s.logger.Error("failed to get task dependencies", zap.Error(err))
+		return err
+	}
+	for _, task := range tasks {
+		task.Subtasks = progress[task.ID]
+		task.BlockedBy = blockedBy[task.ID]
+		task.Blocks = blocks[task.ID]
+	}
+	return s.mentionService.FillTasks(ctx, tasks...)
+}
+
+// taskForUser задача, видимая пользователю: он состоит в ее компании и может смотреть задачи
+func (s *TaskService) taskForUser(ctx context.Context, taskID uint, userID uint) (*model.Task, *model.User, error) {
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.Can(rbac.TaskView) {
+		return nil, nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkMember(ctx, userID, task.CompanyID); err != nil {
+		return nil, nil, err
+	}
+	return task, user, nil
+}
+
+// canEdit менять содержимое задачи может ее автор или пользователь с task:update
+func canEdit(user *model.User, task *model.Task) bool {
+	return task.CreatorID == user.ID || user.Can(rbac.TaskUpdate)
+}
+
+// canExecute работать с задачей (двигать по доске, отмечать чек-лист) может еще и исполнитель
+func canExecute(user *model.User, task *model.Task) bool {
+	return task.IsExecutor(user.ID) || canEdit(user, task)
+}
@@ -0,0 +1,283 @@
+package task
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/repository/repotest"
+	"rttask/internal/domain/service/mention"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fakeTaskTree задачи с родителями, предки и высота поддерева считаются обходом как в рекурсивных запросах
+type fakeTaskTree struct {
+	repository.TaskRepository
+	mu      sync.Mutex
+	tasks   map[uint]*model.Task
+	updates int
+	// treeLock держится до конца транзакции, как pg_advisory_xact_lock
+	treeLock sync.Mutex
+	// readDelay окно между проверкой предков и записью, в которое попадает встречный перенос
+	readDelay time.Duration
+}
+
+func (r *fakeTaskTree) GetByID(ctx context.Context, id uint) (*model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.tasks[id]
+	if !ok {
+		return nil, domainerrors.NewNotFoundError("task", strconv.Itoa(int(id)))
+	}
+	copied := *stored
+	return &copied, nil
+}
+
+func (r *fakeTaskTree) GetByIDForUpdate(ctx context.Context, id uint) (*model.Task, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeTaskTree) GetAncestorIDs(ctx context.Context, id uint) ([]uint, error) {
+	defer time.Sleep(r.readDelay)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ancestors(id), nil
+}
+
+func (r *fakeTaskTree) ancestors(id uint) []uint {
+	var ancestors []uint
+	for task := r.tasks[id]; task != nil && task.ParentID != nil; task = r.tasks[*task.ParentID] {
+		ancestors = append(ancestors, *task.ParentID)
+		if len(ancestors) > len(r.tasks) {
+			// цикл в дереве, дальше обход не закончится
+			break
+		}
+	}
+	return ancestors
+}
+
+func (r *fakeTaskTree) GetSubtreeHeight(ctx context.Context, id uint) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.height(id, 0), nil
+}
+
+func (r *fakeTaskTree) height(id uint, depth int) int {
+	height := 0
+	if depth > len(r.tasks) {
+		return height
+	}
+	for _, task := range r.tasks {
+		if task.ParentID != nil && *task.ParentID == id {
+			height = max(height, r.height(task.ID, depth+1)+1)
+		}
+	}
+	return height
+}
+
+func (r *fakeTaskTree) UpdateParent(ctx context.Context, id uint, parentID *uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates++
+	r.tasks[id].ParentID = parentID
+	return nil
+}
+
+func (r *fakeTaskTree) LockTree(ctx context.Context, companyID uint) error {
+	repotest.LockUntilEnd(ctx, &r.treeLock)
+	return nil
+}
+
+func (r *fakeTaskTree) GetSubtaskProgress(ctx context.Context, taskIDs []uint) (map[uint]model.SubtaskProgress, error) {
+	return nil, nil
+}
+
+const (
+	treeCompanyID  = 1
+	otherCompanyID = 2
+	editorID       = 1
+)
+
+// newTaskTree дерево 1 → 2 → 3 максимальной глубины, 4 → 5, листья 6 и 7 и задача 8 другой компании
+func newTaskTree() *fakeTaskTree {
+	parents := map[uint]uint{2: 1, 3: 2, 5: 4}
+	tree := &fakeTaskTree{tasks: map[uint]*model.Task{}}
+	for id := uint(1); id <= 8; id++ {
+		task := &model.Task{Model: gorm.Model{ID: id}, CompanyID: treeCompanyID, CreatorID: editorID}
+		if parent, ok := parents[id]; ok {
+			task.ParentID = &parent
+		}
+		tree.tasks[id] = task
+	}
+	tree.tasks[8].CompanyID = otherCompanyID
+	return tree
+}
+
+func newSubtaskService(tree *fakeTaskTree) *TaskService {
+	logger := zap.NewNop()
+	users := &repotest.UserRepo{
+		Users:   map[uint]*model.User{editorID: repotest.NewUser(editorID, rbac.TaskView)},
+		Members: map[uint][]uint{treeCompanyID: {editorID}},
+	}
+	mentionService := mention.NewMentionService(&repotest.MentionRepo{}, users, logger)
+	return NewTaskService(tree, nil, &repotest.DependencyRepo{}, nil, nil, nil, users, nil, repotest.UnitOfWork{},
+		nil, nil, mentionService, event.NewBus(logger), logger)
+}
+
+func TestSetParent(t *testing.T) {
+	tests := []struct {
+		name     string
+		taskID   uint
+		parentID uint
+		wantErr  domainerrors.ErrorType
+		wantMsg  string
+	}{
+		{name: "leaf under root", taskID: 6, parentID: 1},
+		{name: "leaf under middle level", taskID: 6, parentID: 2},
+		{name: "leaf under deepest level", taskID: 6, parentID: 3, wantErr: domainerrors.ErrorTypeValidation, wantMsg: "Subtask depth limit exceeded"},
+		{name: "subtree under root", taskID: 4, parentID: 6},
+		{name: "subtree under middle level", taskID: 4, parentID: 2, wantErr: domainerrors.ErrorTypeValidation, wantMsg: "Subtask depth limit exceeded"},
+		{name: "full subtree under another root", taskID: 1, parentID: 6, wantErr: domainerrors.ErrorTypeValidation, wantMsg: "Subtask depth limit exceeded"},
+		{name: "move within own subtree to sibling", taskID: 3, parentID: 1},
+		{name: "own parent", taskID: 1, parentID: 1, wantErr: domainerrors.ErrorTypeValidation, wantMsg: "Task cannot be its own parent"},
+		{name: "under own child", taskID: 2, parentID: 3, wantErr: domainerrors.ErrorTypeValidation, wantMsg: "Parent task is a subtask of this task"},
+		{name: "under own grandchild", taskID: 1, parentID: 3, wantErr: domainerrors.ErrorTypeValidation, wantMsg: "Parent task is a subtask of this task"},
+		{name: "parent in another company", taskID: 6, parentID: 8, wantErr: domainerrors.ErrorTypeValidation, wantMsg: "Parent task belongs to another company"},
+		{name: "missing parent", taskID: 6, parentID: 99, wantErr: domainerrors.ErrorTypeNotFound},
+		{name: "detach to root", taskID: 3, parentID: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newTaskTree()
+			service := newSubtaskService(tree)
+			before := tree.tasks[tt.taskID].ParentID
+
+			task, err := service.SetParent(context.Background(), tt.taskID, tt.parentID, editorID)
+			if tt.wantErr != "" {
+				domainErr := domainerrors.GetDomainError(err)
+				if domainErr == nil || domainErr.Type != tt.wantErr || (tt.wantMsg != "" && domainErr.Message != tt.wantMsg) {
+					t.Fatalf("SetParent error = %v, want %s %q", err, tt.wantErr, tt.wantMsg)
+				}
+				if tree.updates != 0 || tree.tasks[tt.taskID].ParentID != before {
+					t.Errorf("parent changed on rejected move")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetParent: %v", err)
+			}
+
+			stored := tree.tasks[tt.taskID].ParentID
+			if tt.parentID == 0 {
+				if stored != nil || task.ParentID != nil {
+					t.Errorf("task is not a root: stored %v, returned %v", stored, task.ParentID)
+				}
+				return
+			}
+			if stored == nil || *stored != tt.parentID || task.ParentID == nil || *task.ParentID != tt.parentID {
+				t.Errorf("parent = %v, want %d", stored, tt.parentID)
+			}
+			// после переноса ни одна ветка не глубже model.MaxTaskDepth
+			for id := range tree.tasks {
+				ancestors := tree.ancestors(id)
+				if len(ancestors)+1 > model.MaxTaskDepth {
+					t.Errorf("task %d is at depth %d", id, len(ancestors)+1)
+				}
+			}
+		})
+	}
+}
+
+func TestSetParentRequiresEditPermission(t *testing.T) {
+	tree := newTaskTree()
+	tree.tasks[6].CreatorID = editorID + 1
+	service := newSubtaskService(tree)
+
+	_, err := service.SetParent(context.Background(), 6, 1, editorID)
+	if domainErr := domainerrors.GetDomainError(err); domainErr == nil || domainErr.Type != domainerrors.ErrorTypeForbidden {
+		t.Fatalf("SetParent error = %v, want forbidden", err)
+	}
+	if tree.updates != 0 {
+		t.Errorf("parent changed without permission")
+	}
+}
+
+// новая задача (taskID 0) встает листом: проверяется только глубина родителя
+func TestValidateParentForNewTask(t *testing.T) {
+	tests := []struct {
+		parentID uint
+		wantErr  bool
+	}{
+		{parentID: 1},
+		{parentID: 2},
+		{parentID: 3, wantErr: true},
+		{parentID: 8, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(int(tt.parentID)), func(t *testing.T) {
+			service := newSubtaskService(newTaskTree())
+			err := service.validateParent(context.Background(), 0, tt.parentID, treeCompanyID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateParent(0, %d) error = %v, want error %v", tt.parentID, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetParentConcurrentMoves(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves [2][2]uint // задача, новый родитель
+	}{
+		// 6 под 7 и 7 под 6 вместе замкнули бы цикл
+		{name: "opposite moves", moves: [2][2]uint{{6, 7}, {7, 6}}},
+		// 6 под 2 и 7 под 6: каждый перенос укладывается в глубину, вместе получается 1 → 2 → 6 → 7
+		{name: "depth through both moves", moves: [2][2]uint{{6, 2}, {7, 6}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 20 {
+				tree := newTaskTree()
+				tree.readDelay = time.Millisecond
+				service := newSubtaskService(tree)
+
+				var wg sync.WaitGroup
+				errs := make([]error, len(tt.moves))
+				for i, move := range tt.moves {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, errs[i] = service.SetParent(context.Background(), move[0], move[1], editorID)
+					}()
+				}
+				wg.Wait()
+
+				succeeded := 0
+				for _, err := range errs {
+					if err == nil {
+						succeeded++
+					} else if domainErr := domainerrors.GetDomainError(err); domainErr == nil || domainErr.Type != domainerrors.ErrorTypeValidation {
+						t.Fatalf("SetParent error = %v, want validation error", err)
+					}
+				}
+				if succeeded != 1 {
+					t.Fatalf("%d moves succeeded, want exactly one", succeeded)
+				}
+				for id := range tree.tasks {
+					if ancestors := tree.ancestors(id); slices.Contains(ancestors, id) || len(ancestors)+1 > model.MaxTaskDepth {
+						t.Fatalf("task %d has ancestors %v after concurrent moves", id, ancestors)
+					}
+				}
+			}
+		})
+	}
+}
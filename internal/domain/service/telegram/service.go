@@ -0,0 +1,395 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/service/notification"
+	"rttask/internal/domain/service/task"
+	telegramclient "rttask/internal/infrastructure/telegram"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	codeLength = 8
+	// codeAlphabet без похожих друг на друга символов, код набирают руками
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// moveCallback данные кнопки: move:<taskID>:<action>
+	moveCallback = "move"
+)
+
+// notifiedTypes уведомления, которые дублируются в Telegram
+var notifiedTypes = []model.NotificationType{
+	model.TaskAssignedNotification,
+	model.DeadlineReminderNotification,
+	model.TaskOverdueNotification,
+}
+
+// moveActions статусы, в которые задачу можно перевести кнопкой. Код действия короткий,
+// потому что данные кнопки ограничены 64 байтами
+var moveActions = []struct {
+	code   string
+	status model.Status
+}{
+	{"work", model.InWorkStatus},
+	{"done", model.CompletedStatus},
+}
+
+// Options BotUsername для ссылки t.me на привязку, WebhookSecret сверяется с заголовком запросов Telegram
+// в режиме webhook, QueueSize сколько сообщений может ждать отправки
+type Options struct {
+	BotUsername   string
+	WebhookSecret string
+	CodeTTL       time.Duration
+	QueueSize     int
+}
+
+// TelegramService привязка аккаунтов, уведомления в Telegram и быстрые действия с задачами из бота.
+// Действия выполняются через TaskService от имени привязанного пользователя, с теми же проверками прав, что в HTTP API
+type TelegramService struct {
+	telegramRepo repository.TelegramRepository
+	userRepo     repository.UserRepository
+	uow          repository.UnitOfWork
+	taskService  *task.TaskService
+	client       telegramclient.Client
+	opts         Options
+	queue        chan *model.Notification
+	logger       *zap.Logger
+}
+
+func NewTelegramService(telegramRepo repository.TelegramRepository, userRepo repository.UserRepository, uow repository.UnitOfWork, taskService *task.TaskService, client telegramclient.Client, opts Options, logger *zap.Logger) *TelegramService {
+	return &TelegramService{
+		telegramRepo: telegramRepo,
+		userRepo:     userRepo,
+		uow:          uow,
+		taskService:  taskService,
+		client:       client,
+		opts:         opts,
+		queue:        make(chan *model.Notification, max(opts.QueueSize, 1)),
+		logger:       logger,
+	}
+}
+
+// Subscribe сообщения строятся из уже сохраненных уведомлений
+func (s *TelegramService) Subscribe(bus *event.Bus) {
+	bus.Subscribe(event.NotificationCreated, s.onNotificationCreated)
+}
+
+// Start отправляет сообщения из очереди до отмены контекста
+func (s *TelegramService) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-s.queue:
+			s.notify(ctx, item)
+		}
+	}
+}
+
+// CreateLinkCode новый код привязки, прежний код пользователя перестает действовать
+func (s *TelegramService) CreateLinkCode(ctx context.Context, userID uint) (*model.TelegramLinkCode, error) {
+	code := &model.TelegramLinkCode{
+		Code:      newCode(),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.opts.CodeTTL),
+	}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.telegramRepo.ReplaceCode(ctx, code)
+	})
+	if err != nil {
+		s.logger.Error("failed to create telegram link code", zap.Uint("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	return code, nil
+}
+
+// DeepLink ссылка, открывающая бота с кодом привязки, пустая если имя бота не настроено
+func (s *TelegramService) DeepLink(code string) string {
+	if s.opts.BotUsername == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://t.me/%s?start=%s", s.opts.BotUsername, code)
+}
+
+// GetLink возвращает nil, если пользователь не привязан
+func (s *TelegramService) GetLink(ctx context.Context, userID uint) (*model.TelegramLink, error) {
+	return s.telegramRepo.GetLinkByUser(ctx, userID)
+}
+
+func (s *TelegramService) Unlink(ctx context.Context, userID uint) error {
+	deleted, err := s.telegramRepo.DeleteLink(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domainerrors.NewNotFoundError("telegram link", strconv.Itoa(int(userID)))
+	}
+	return nil
+}
+
+// VerifyWebhookSecret без настроенного секрета запросы в режиме webhook не принимаются
+func (s *TelegramService) VerifyWebhookSecret(secret string) bool {
+	return s.opts.WebhookSecret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.opts.WebhookSecret)) == 1
+}
+
+// HandleUpdate обрабатывает команды и нажатия кнопок. Ответ пользователю уходит через бота,
+// ошибка возвращается только когда ответить не удалось
+func (s *TelegramService) HandleUpdate(ctx context.Context, update telegramclient.Update) error {
+	switch {
+	case update.CallbackQuery != nil:
+		return s.handleCallback(ctx, update.CallbackQuery)
+	case update.Message != nil && update.Message.From != nil:
+		return s.handleMessage(ctx, update.Message)
+	}
+	return nil
+}
+
+func (s *TelegramService) handleMessage(ctx context.Context, message *telegramclient.Message) error {
+	if message.Chat.Type != "private" {
+		return s.client.SendMessage(ctx, message.Chat.ID, "Бот работает только в личных сообщениях.", nil)
+	}
+
+	command, argument, _ := strings.Cut(strings.TrimSpace(message.Text), " ")
+	command, _, _ = strings.Cut(command, "@")
+	argument = strings.ToUpper(strings.TrimSpace(argument))
+
+	switch {
+	case (command == "/start" || command == "/link") && argument != "":
+		return s.link(ctx, message, argument)
+	case command == "/unlink":
+		return s.unlinkChat(ctx, message)
+	}
+	return s.client.SendMessage(ctx, message.Chat.ID,
+		"Чтобы получать уведомления о задачах, получите код привязки в профиле RTTask и отправьте его боту командой /link КОД.", nil)
+}
+
+func (s *TelegramService) link(ctx context.Context, message *telegramclient.Message, code string) error {
+	var user *model.User
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		linkCode, err := s.telegramRepo.ConsumeCode(ctx, code, time.Now())
+		if err != nil || linkCode == nil {
+			return err
+		}
+		if user, err = s.userRepo.GetUserByID(ctx, linkCode.UserID); err != nil {
+			return err
+		}
+		return s.telegramRepo.SaveLink(ctx, &model.TelegramLink{
+			UserID:         linkCode.UserID,
+			ChatID:         message.Chat.ID,
+			TelegramUserID: message.From.ID,
+			Username:       message.From.Username,
+		})
+	})
+	if err != nil {
+		s.logger.Error("failed to link telegram account", zap.Int64("chatID", message.Chat.ID), zap.Error(err))
+		return s.client.SendMessage(ctx, message.Chat.ID, "Не удалось привязать аккаунт, попробуйте позже.", nil)
+	}
+	if user == nil {
+		return s.client.SendMessage(ctx, message.Chat.ID, "Код не найден или истек. Получите новый код в профиле RTTask.", nil)
+	}
+	return s.client.SendMessage(ctx, message.Chat.ID,
+		fmt.Sprintf("Аккаунт %s привязан. Сюда будут приходить назначения и напоминания о сроках, отвязать: /unlink.", user.FullName()), nil)
+}
+
+func (s *TelegramService) unlinkChat(ctx context.Context, message *telegramclient.Message) error {
+	link, err := s.telegramRepo.GetLinkByTelegramUser(ctx, message.From.ID)
+	if err != nil {
+		return err
+	}
+	if link == nil {
+		return s.client.SendMessage(ctx, message.Chat.ID, "Аккаунт не привязан.", nil)
+	}
+	if _, err := s.telegramRepo.DeleteLink(ctx, link.UserID); err != nil {
+		return err
+	}
+	return s.client.SendMessage(ctx, message.Chat.ID, "Аккаунт отвязан, уведомления больше не придут.", nil)
+}
+
+// handleCallback кнопка перевода задачи. Пользователь определяется по Telegram аккаунту нажавшего,
+// права проверяет TaskService.MoveTask
+func (s *TelegramService) handleCallback(ctx context.Context, callback *telegramclient.CallbackQuery) error {
+	taskID, status, ok := parseMoveCallback(callback.Data)
+	if !ok {
+		return s.client.AnswerCallbackQuery(ctx, callback.ID, "Неизвестное действие")
+	}
+	link, err := s.telegramRepo.GetLinkByTelegramUser(ctx, callback.From.ID)
+	if err != nil {
+		return err
+	}
+	if link == nil {
+		return s.client.AnswerCallbackQuery(ctx, callback.ID, "Аккаунт не привязан к RTTask")
+	}
+
+	moved, err := s.taskService.MoveTask(ctx, task.MoveTaskInput{TaskID: taskID, Status: status}, link.UserID)
+	if err != nil {
+		return s.client.AnswerCallbackQuery(ctx, callback.ID, moveErrorText(err, status))
+	}
+
+	if callback.Message != nil {
+		if err := s.client.EditReplyMarkup(ctx, callback.Message.Chat.ID, callback.Message.MessageID, moveMarkup(moved.ID, moved.Status)); err != nil {
+			s.logger.Warn("failed to update telegram buttons", zap.Uint("taskID", moved.ID), zap.Error(err))
+		}
+	}
+	return s.client.AnswerCallbackQuery(ctx, callback.ID, fmt.Sprintf("Задача переведена в «%s»", moved.Status))
+}
+
+// onNotificationCreated при переполненной очереди сообщение пропускается: уведомление все равно есть в приложении
+func (s *TelegramService) onNotificationCreated(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.NotificationCreatedPayload)
+	if !ok || !slices.Contains(notifiedTypes, payload.Notification.Type) {
+		return
+	}
+	select {
+	case s.queue <- payload.Notification:
+	default:
+		s.logger.Warn("telegram queue is full, message skipped", zap.Uint("notificationID", payload.Notification.ID))
+	}
+}
+
+func (s *TelegramService) notify(ctx context.Context, item *model.Notification) {
+	link, err := s.telegramRepo.GetLinkByUser(ctx, item.RecipientID)
+	if err != nil {
+		s.logger.Error("failed to get telegram link", zap.Uint("userID", item.RecipientID), zap.Error(err))
+		return
+	}
+	if link == nil {
+		return
+	}
+
+	actor := ""
+	if item.ActorID != 0 {
+		if user, err := s.userRepo.GetUserByID(ctx, item.ActorID); err == nil {
+			actor = user.FullName()
+		}
+	}
+	title, text := notification.Describe("", item, actor)
+	status, _ := item.Payload["taskStatus"].(model.Status)
+
+	err = s.client.SendMessage(ctx, link.ChatID, title+"\n"+text, moveMarkup(item.TaskID, status))
+	var apiErr *telegramclient.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == 403 {
+		// пользователь заблокировал бота, писать ему больше некуда
+		s.logger.Info("telegram bot blocked, unlinking", zap.Uint("userID", link.UserID))
+		if _, err := s.telegramRepo.DeleteLink(ctx, link.UserID); err != nil {
+			s.logger.Error("failed to delete telegram link", zap.Uint("userID", link.UserID), zap.Error(err))
+		}
+		return
+	}
+	if err != nil {
+		s.logger.Error("failed to send telegram message", zap.Uint("notificationID", item.ID), zap.Error(err))
+	}
+}
+
+// moveMarkup кнопки статусов, в которые задачу можно перевести из status, nil если таких нет
+func moveMarkup(taskID uint, status model.Status) *telegramclient.InlineKeyboardMarkup {
+	var buttons []telegramclient.InlineKeyboardButton
+	for _, action := range moveActions {
+		if !status.CanTransitionTo(action.status) {
+			continue
+		}
+		buttons = append(buttons, telegramclient.InlineKeyboardButton{
+			Text:         string(action.status),
+			CallbackData: fmt.Sprintf("%s:%d:%s", moveCallback, taskID, action.code),
+		})
+	}
+	if len(buttons) == 0 {
+		return nil
+	}
+	return &telegramclient.InlineKeyboardMarkup{InlineKeyboard: [][]telegramclient.InlineKeyboardButton{buttons}}
+}
+
+func parseMoveCallback(data string) (uint, model.Status, bool) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 3 || parts[0] != moveCallback {
+		return 0, "", false
+	}
+	taskID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || taskID == 0 {
+		return 0, "", false
+	}
+	for _, action := range moveActions {
+		if action.code == parts[2] {
+			return uint(taskID), action.status, true
+		}
+	}
+	return 0, "", false
+}
+
+func moveErrorText(err error, status model.Status) string {
+	var domainErr *domainerrors.DomainError
+	if !errors.As(err, &domainErr) {
+		return "Не удалось выполнить действие, попробуйте позже"
+	}
+	switch domainErr.Type {
+	case domainerrors.ErrorTypeForbidden:
+		return "Нет прав на это действие"
+	case domainerrors.ErrorTypeNotFound:
+		return "Задача не найдена"
+	case domainerrors.ErrorTypeValidation, domainerrors.ErrorTypeConflict:
+		return fmt.Sprintf("Задачу нельзя перевести в «%s»", status)
+	}
+	return "Не удалось выполнить действие, попробуйте позже"
+}
+
+func newCode() string {
+	random := make([]byte, codeLength)
+	rand.Read(random)
+	code := make([]byte, codeLength)
+	for i, b := range random {
+		code[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+	return string(code)
+}
+
+// TelegramPoller забирает обновления бота long polling'ом, когда бот работает без webhook
+type TelegramPoller struct {
+	service *TelegramService
+	client  telegramclient.Client
+	timeout int
+	logger  *zap.Logger
+}
+
+func NewTelegramPoller(service *TelegramService, client telegramclient.Client, timeout int, logger *zap.Logger) *TelegramPoller {
+	return &TelegramPoller{
+		service: service,
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Start опрашивает Bot API до отмены контекста. После ошибки пауза, чтобы не долбить API
+func (p *TelegramPoller) Start(ctx context.Context) {
+	var offset int64
+	for ctx.Err() == nil {
+		updates, err := p.client.GetUpdates(ctx, offset, p.timeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				p.logger.Error("failed to get telegram updates", zap.Error(err))
+				select {
+				case <-ctx.Done():
+				case <-time.After(5 * time.Second):
+				}
+			}
+			continue
+		}
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if err := p.service.HandleUpdate(ctx, update); err != nil {
+				p.logger.Error("failed to handle telegram update", zap.Int64("updateID", update.UpdateID), zap.Error(err))
+			}
+		}
+	}
+}
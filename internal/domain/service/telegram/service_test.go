@@ -0,0 +1,359 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/repository/repotest"
+	"rttask/internal/domain/service/mention"
+	"rttask/internal/domain/service/task"
+	telegramclient "rttask/internal/infrastructure/telegram"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// botAPI поддельный Bot API: запоминает вызовы методов с параметрами и отвечает ok
+type botAPI struct {
+	mu    sync.Mutex
+	calls []botCall
+}
+
+type botCall struct {
+	method string
+	params map[string]any
+}
+
+func (b *botAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/bottoken/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 404, "description": "Not Found"})
+		return
+	}
+	var params map[string]any
+	json.NewDecoder(r.Body).Decode(&params)
+
+	b.mu.Lock()
+	b.calls = append(b.calls, botCall{method: method, params: params})
+	b.mu.Unlock()
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": true})
+}
+
+func (b *botAPI) called(method string) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var params []map[string]any
+	for _, call := range b.calls {
+		if call.method == method {
+			params = append(params, call.params)
+		}
+	}
+	return params
+}
+
+type fakeTelegramRepo struct {
+	repository.TelegramRepository
+	codes map[string]*model.TelegramLinkCode
+	links map[uint]*model.TelegramLink
+}
+
+func (r *fakeTelegramRepo) ReplaceCode(ctx context.Context, code *model.TelegramLinkCode) error {
+	for key, existing := range r.codes {
+		if existing.UserID == code.UserID {
+			delete(r.codes, key)
+		}
+	}
+	r.codes[code.Code] = code
+	return nil
+}
+
+func (r *fakeTelegramRepo) ConsumeCode(ctx context.Context, code string, now time.Time) (*model.TelegramLinkCode, error) {
+	linkCode, ok := r.codes[code]
+	if !ok || !linkCode.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	delete(r.codes, code)
+	return linkCode, nil
+}
+
+func (r *fakeTelegramRepo) SaveLink(ctx context.Context, link *model.TelegramLink) error {
+	r.links[link.UserID] = link
+	return nil
+}
+
+func (r *fakeTelegramRepo) GetLinkByTelegramUser(ctx context.Context, telegramUserID int64) (*model.TelegramLink, error) {
+	for _, link := range r.links {
+		if link.TelegramUserID == telegramUserID {
+			return link, nil
+		}
+	}
+	return nil, nil
+}
+
+type fakeTaskRepo struct {
+	repository.TaskRepository
+	tasks map[uint]*model.Task
+}
+
+func (r *fakeTaskRepo) GetByID(ctx context.Context, id uint) (*model.Task, error) {
+	stored, ok := r.tasks[id]
+	if !ok {
+		return nil, domainerrors.NewNotFoundError("task", strconv.Itoa(int(id)))
+	}
+	copied := *stored
+	return &copied, nil
+}
+
+func (r *fakeTaskRepo) GetByIDForUpdate(ctx context.Context, id uint) (*model.Task, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeTaskRepo) GetLastRank(ctx context.Context, companyID uint, status model.Status, excludeID uint) (string, error) {
+	return "", nil
+}
+
+func (r *fakeTaskRepo) UpdateBoardPosition(ctx context.Context, task *model.Task) error {
+	copied := *task
+	r.tasks[task.ID] = &copied
+	return nil
+}
+
+func (r *fakeTaskRepo) GetSubtaskProgress(ctx context.Context, taskIDs []uint) (map[uint]model.SubtaskProgress, error) {
+	return nil, nil
+}
+
+type telegramFixture struct {
+	service  *TelegramService
+	api      *botAPI
+	telegram *fakeTelegramRepo
+	tasks    *fakeTaskRepo
+}
+
+const (
+	fixtureCompanyID = 10
+	executorID       = 1
+	readerID         = 2
+	taskID           = 7
+)
+
+func newTelegramFixture(t *testing.T) *telegramFixture {
+	t.Helper()
+	api := &botAPI{}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	users := &repotest.UserRepo{
+		Users: map[uint]*model.User{
+			executorID: newUser(executorID, "Иван", "Петров", rbac.TaskChangeStatus),
+			readerID:   newUser(readerID, "Анна", "Смирнова"),
+		},
+		Members: map[uint][]uint{fixtureCompanyID: {executorID, readerID}},
+	}
+	tasks := &fakeTaskRepo{tasks: map[uint]*model.Task{
+		taskID: {
+			Model:      gorm.Model{ID: taskID},
+			CreatorID:  readerID,
+			ExecutorID: executorID,
+			CompanyID:  fixtureCompanyID,
+			Status:     model.CreatedStatus,
+		},
+	}}
+	telegram := &fakeTelegramRepo{
+		codes: map[string]*model.TelegramLinkCode{},
+		links: map[uint]*model.TelegramLink{},
+	}
+	uow := repotest.UnitOfWork{}
+	logger := zap.NewNop()
+
+	mentionService := mention.NewMentionService(&repotest.MentionRepo{}, users, logger)
+	taskService := task.NewTaskService(tasks, nil, &repotest.DependencyRepo{}, nil, nil, nil, users, nil, uow,
+		nil, nil, mentionService, event.NewBus(logger), logger)
+	client := telegramclient.NewHTTPClient(server.URL, "token", 5*time.Second)
+	service := NewTelegramService(telegram, users, uow, taskService, client, Options{CodeTTL: time.Hour}, logger)
+
+	return &telegramFixture{service: service, api: api, telegram: telegram, tasks: tasks}
+}
+
+func newUser(id uint, firstName, lastName string, permissions ...rbac.Permission) *model.User {
+	user := repotest.NewUser(id, permissions...)
+	user.FirstName, user.LastName = firstName, lastName
+	return user
+}
+
+func privateMessage(telegramUserID int64, text string) telegramclient.Update {
+	return telegramclient.Update{Message: &telegramclient.Message{
+		MessageID: 1,
+		From:      &telegramclient.User{ID: telegramUserID, Username: "user" + strconv.FormatInt(telegramUserID, 10)},
+		Chat:      telegramclient.Chat{ID: telegramUserID * 100, Type: "private"},
+		Text:      text,
+	}}
+}
+
+func moveUpdate(telegramUserID int64, data string) telegramclient.Update {
+	return telegramclient.Update{CallbackQuery: &telegramclient.CallbackQuery{
+		ID:      "callback",
+		From:    telegramclient.User{ID: telegramUserID},
+		Message: &telegramclient.Message{MessageID: 42, Chat: telegramclient.Chat{ID: telegramUserID * 100, Type: "private"}},
+		Data:    data,
+	}}
+}
+
+func TestLinkAccountByCode(t *testing.T) {
+	ctx := context.Background()
+	fixture := newTelegramFixture(t)
+
+	code, err := fixture.service.CreateLinkCode(ctx, executorID)
+	if err != nil {
+		t.Fatalf("CreateLinkCode: %v", err)
+	}
+	// код набирают руками, регистр не важен
+	if err := fixture.service.HandleUpdate(ctx, privateMessage(555, "/start "+strings.ToLower(code.Code))); err != nil {
+		t.Fatalf("HandleUpdate: %v", err)
+	}
+
+	link := fixture.telegram.links[executorID]
+	if link == nil {
+		t.Fatal("link was not saved")
+	}
+	if link.ChatID != 55500 || link.TelegramUserID != 555 || link.Username != "user555" {
+		t.Errorf("link = %+v", link)
+	}
+	if len(fixture.telegram.codes) != 0 {
+		t.Errorf("code was not consumed: %v", fixture.telegram.codes)
+	}
+
+	sent := fixture.api.called("sendMessage")
+	if len(sent) != 1 {
+		t.Fatalf("sendMessage called %d times, want 1", len(sent))
+	}
+	if sent[0]["chat_id"] != float64(55500) {
+		t.Errorf("chat_id = %v, want 55500", sent[0]["chat_id"])
+	}
+	if text, _ := sent[0]["text"].(string); !strings.Contains(text, "Иван Петров привязан") {
+		t.Errorf("text = %q", text)
+	}
+
+	// повторно код не действует
+	if err := fixture.service.HandleUpdate(ctx, privateMessage(777, "/link "+code.Code)); err != nil {
+		t.Fatalf("HandleUpdate: %v", err)
+	}
+	sent = fixture.api.called("sendMessage")
+	if text, _ := sent[len(sent)-1]["text"].(string); !strings.HasPrefix(text, "Код не найден") {
+		t.Errorf("reused code reply = %q", text)
+	}
+	if len(fixture.telegram.links) != 1 {
+		t.Errorf("links = %d, want 1", len(fixture.telegram.links))
+	}
+}
+
+func TestMoveCallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     uint
+		data       string
+		wantStatus model.Status
+		wantAnswer string
+		wantEdit   bool
+	}{
+		{
+			name:       "executor moves to work",
+			userID:     executorID,
+			data:       "move:7:work",
+			wantStatus: model.InWorkStatus,
+			wantAnswer: "Задача переведена в «В работе»",
+			wantEdit:   true,
+		},
+		{
+			name:       "transition not allowed",
+			userID:     executorID,
+			data:       "move:7:done",
+			wantStatus: model.CreatedStatus,
+			wantAnswer: "Задачу нельзя перевести в «Выполнена»",
+		},
+		{
+			name:       "no permission to change status",
+			userID:     readerID,
+			data:       "move:7:work",
+			wantStatus: model.CreatedStatus,
+			wantAnswer: "Нет прав на это действие",
+		},
+		{
+			name:       "unknown task",
+			userID:     executorID,
+			data:       "move:8:work",
+			wantStatus: model.CreatedStatus,
+			wantAnswer: "Задача не найдена",
+		},
+		{
+			name:       "malformed data",
+			userID:     executorID,
+			data:       "move:7:archive",
+			wantStatus: model.CreatedStatus,
+			wantAnswer: "Неизвестное действие",
+		},
+		{
+			name:       "account not linked",
+			data:       "move:7:work",
+			wantStatus: model.CreatedStatus,
+			wantAnswer: "Аккаунт не привязан к RTTask",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fixture := newTelegramFixture(t)
+			telegramUserID := int64(900)
+			if tt.userID != 0 {
+				fixture.telegram.links[tt.userID] = &model.TelegramLink{UserID: tt.userID, ChatID: telegramUserID * 100, TelegramUserID: telegramUserID}
+			}
+
+			if err := fixture.service.HandleUpdate(ctx, moveUpdate(telegramUserID, tt.data)); err != nil {
+				t.Fatalf("HandleUpdate: %v", err)
+			}
+
+			if status := fixture.tasks.tasks[taskID].Status; status != tt.wantStatus {
+				t.Errorf("task status = %q, want %q", status, tt.wantStatus)
+			}
+
+			answers := fixture.api.called("answerCallbackQuery")
+			if len(answers) != 1 {
+				t.Fatalf("answerCallbackQuery called %d times, want 1", len(answers))
+			}
+			if answers[0]["callback_query_id"] != "callback" || answers[0]["text"] != tt.wantAnswer {
+				t.Errorf("answer = %v, want %q", answers[0], tt.wantAnswer)
+			}
+
+			edits := fixture.api.called("editMessageReplyMarkup")
+			if !tt.wantEdit {
+				if len(edits) != 0 {
+					t.Errorf("buttons edited on failed move: %v", edits)
+				}
+				return
+			}
+			if len(edits) != 1 {
+				t.Fatalf("editMessageReplyMarkup called %d times, want 1", len(edits))
+			}
+			if edits[0]["chat_id"] != float64(90000) || edits[0]["message_id"] != float64(42) {
+				t.Errorf("edit target = %v", edits[0])
+			}
+			// кнопки строятся из нового статуса: из работы задачу можно завершить
+			markup, _ := json.Marshal(edits[0]["reply_markup"])
+			if !strings.Contains(string(markup), `"move:7:done"`) {
+				t.Errorf("reply_markup = %s, want a done button", markup)
+			}
+		})
+	}
+}
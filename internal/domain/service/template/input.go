@@ -0,0 +1,33 @@
+package template
+
+import (
+	"time"
+)
+
+type TemplateInput struct {
+	CompanyID   uint
+	Name        string
+	Title       string
+	Description string
+	Priority    uint
+	ExecutorID  uint
+	Checklist   []string
+	// DeadlineAfter срок задачи от ее начала
+	DeadlineAfter time.Duration
+	// FileIDs файлы, загруженные через /uploads, добавляются к файлам шаблона
+	FileIDs []string
+}
+
+// FromTemplateInput поля, которые задаются при создании задачи по шаблону.
+// Values значения плейсхолдеров, они заменяют встроенные
+type FromTemplateInput struct {
+	TemplateID uint
+	StartAt    time.Time
+	// ExecutorID 0 для исполнителя из шаблона
+	ExecutorID  uint
+	ExecutorIDs []uint
+	WatcherIDs  []uint
+	ParentID    uint
+	Priority    uint
+	Values      map[string]string
+}
@@ -0,0 +1,319 @@
+package template
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/service/file"
+	"rttask/internal/domain/service/task"
+	"rttask/internal/domain/valueobject"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxNameLength          = 255
+	maxChecklistLength     = 100
+	maxChecklistItemLength = 500
+	maxDeadlineAfter       = 365 * 24 * time.Hour
+)
+
+// Встроенные плейсхолдеры, их значения известны при создании задачи
+const (
+	DatePlaceholder     = "date"
+	DeadlinePlaceholder = "deadline"
+	CompanyPlaceholder  = "company"
+	ExecutorPlaceholder = "executor"
+)
+
+const placeholderDateLayout = "02.01.2006"
+
+type TemplateService struct {
+	templateRepo  repository.TemplateRepository
+	userRepo      repository.UserRepository
+	companyRepo   repository.CompanyRepository
+	uow           repository.UnitOfWork
+	taskService   *task.TaskService
+	fileService   *file.FileService
+	uploadService *file.UploadService
+	logger        *zap.Logger
+}
+
+func NewTemplateService(templateRepo repository.TemplateRepository, userRepo repository.UserRepository, companyRepo repository.CompanyRepository, uow repository.UnitOfWork, taskService *task.TaskService, fileService *file.FileService, uploadService *file.UploadService, logger *zap.Logger) *TemplateService {
+	return &TemplateService{
+		templateRepo:  templateRepo,
+		userRepo:      userRepo,
+		companyRepo:   companyRepo,
+		uow:           uow,
+		taskService:   taskService,
+		fileService:   fileService,
+		uploadService: uploadService,
+		logger:        logger,
+	}
+}
+
+func (s *TemplateService) CreateTemplate(ctx context.Context, input TemplateInput, userID uint) (*model.TaskTemplate, error) {
+	if _, err := s.authorize(ctx, userID, input.CompanyID, rbac.TaskCreate); err != nil {
+		return nil, err
+	}
+	if err := s.validate(ctx, &input); err != nil {
+		return nil, err
+	}
+
+	template := &model.TaskTemplate{
+		CompanyID: input.CompanyID,
+		CreatorID: userID,
+	}
+	applyInput(template, input)
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.attachFiles(ctx, template, input.FileIDs, userID); err != nil {
+			return err
+		}
+		_, err := s.templateRepo.Create(ctx, template)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("failed to create template", zap.Error(err))
+		return nil, err
+	}
+	return template, nil
+}
+
+// UpdateTemplate заменяет поля шаблона. Файлы из FileIDs добавляются, удаляются они через /files/:id
+func (s *TemplateService) UpdateTemplate(ctx context.Context, id uint, input TemplateInput, userID uint) (*model.TaskTemplate, error) {
+	template, err := s.templateForChange(ctx, id, userID, rbac.TaskUpdate)
+	if err != nil {
+		return nil, err
+	}
+	input.CompanyID = template.CompanyID
+	if err := s.validate(ctx, &input); err != nil {
+		return nil, err
+	}
+	applyInput(template, input)
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.attachFiles(ctx, template, input.FileIDs, userID); err != nil {
+			return err
+		}
+		return s.templateRepo.Update(ctx, template)
+	})
+	if err != nil {
+		s.logger.Error("failed to update template", zap.Error(err))
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *TemplateService) GetTemplate(ctx context.Context, id uint, userID uint) (*model.TaskTemplate, error) {
+	template, err := s.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authorize(ctx, userID, template.CompanyID, rbac.TaskView); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *TemplateService) ListTemplates(ctx context.Context, companyID uint, userID uint) ([]*model.TaskTemplate, error) {
+	if _, err := s.authorize(ctx, userID, companyID, rbac.TaskList); err != nil {
+		return nil, err
+	}
+	return s.templateRepo.ListByCompany(ctx, companyID)
+}
+
+// DeleteTemplate задачи, созданные по шаблону, остаются: у них свои копии файлов
+func (s *TemplateService) DeleteTemplate(ctx context.Context, id uint, userID uint) error {
+	template, err := s.templateForChange(ctx, id, userID, rbac.TaskDelete)
+	if err != nil {
+		return err
+	}
+	if err := s.templateRepo.Delete(ctx, template); err != nil {
+		return err
+	}
+	s.fileService.DeleteFiles(ctx, template.Files...)
+	return nil
+}
+
+// CreateTask создает задачу по шаблону через TaskService.CreateTask со всеми его проверками.
+// Плейсхолдеры без значения отклоняют запрос
+func (s *TemplateService) CreateTask(ctx context.Context, input FromTemplateInput, userID uint) (*model.Task, error) {
+	template, err := s.GetTemplate(ctx, input.TemplateID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskInput := task.TaskInput{
+		StartAt:     input.StartAt,
+		DeadlineAt:  input.StartAt.Add(template.DeadlineAfter),
+		Priority:    template.Priority,
+		ExecutorID:  template.ExecutorID,
+		ExecutorIDs: input.ExecutorIDs,
+		WatcherIDs:  input.WatcherIDs,
+		CompanyID:   template.CompanyID,
+		ParentID:    input.ParentID,
+		Checklist:   template.Checklist,
+		CopyFiles:   template.Files,
+	}
+	if input.ExecutorID != 0 {
+		taskInput.ExecutorID = input.ExecutorID
+	}
+	if input.Priority != 0 {
+		taskInput.Priority = input.Priority
+	}
+	if taskInput.ExecutorID == 0 {
+		return nil, domainerrors.NewValidationError("Executor is required, template has no default executor")
+	}
+
+	values, err := s.placeholderValues(ctx, template, taskInput)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range input.Values {
+		values[name] = value
+	}
+
+	var missing, missingInDescription []string
+	taskInput.Title, missing = valueobject.RenderPlaceholders(template.Title, values)
+	taskInput.Description, missingInDescription = valueobject.RenderPlaceholders(template.Description, values)
+	for _, name := range missingInDescription {
+		if !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, domainerrors.NewValidationError("Placeholder values are missing").WithMeta("missing", missing)
+	}
+
+	return s.taskService.CreateTask(ctx, taskInput, nil, userID)
+}
+
+// placeholderValues значения встроенных плейсхолдеров
+func (s *TemplateService) placeholderValues(ctx context.Context, template *model.TaskTemplate, input task.TaskInput) (map[string]string, error) {
+	values := map[string]string{
+		DatePlaceholder:     input.StartAt.Format(placeholderDateLayout),
+		DeadlinePlaceholder: input.DeadlineAt.Format(placeholderDateLayout),
+	}
+	company, err := s.companyRepo.GetByID(ctx, template.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	values[CompanyPlaceholder] = company.Name
+
+	executor, err := s.userRepo.GetUserByID(ctx, input.ExecutorID)
+	if err != nil {
+		return nil, err
+	}
+	values[ExecutorPlaceholder] = executor.FullName()
+	return values, nil
+}
+
+// authorize пользователь с правом permission из компании шаблона
+func (s *TemplateService) authorize(ctx context.Context, userID uint, companyID uint, permission rbac.Permission) (*model.User, error) {
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Can(permission) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+	inCompany, err := s.userRepo.IsUserInCompany(ctx, userID, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if !inCompany {
+		return nil, domainerrors.NewForbiddenError("user not in company")
+	}
+	return user, nil
+}
+
+// templateForChange менять шаблон может его автор или пользователь с правом permission
+func (s *TemplateService) templateForChange(ctx context.Context, id uint, userID uint, permission rbac.Permission) (*model.TaskTemplate, error) {
+	template, err := s.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authorize(ctx, userID, template.CompanyID, rbac.TaskView)
+	if err != nil {
+		return nil, err
+	}
+	if template.CreatorID != userID && !user.Can(permission) {
+		return nil, domainerrors.NewForbiddenError("dont have permission")
+	}
+	return template, nil
+}
+
+// validate проверяет поля и приводит пункты чек-листа к виду, в котором они сохраняются
+func (s *TemplateService) validate(ctx context.Context, input *TemplateInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" || utf8.RuneCountInString(input.Name) > maxNameLength {
+		return domainerrors.NewValidationError("Template name is required and must be shorter than 255 characters")
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		return domainerrors.NewValidationError("Template title is required")
+	}
+	if input.DeadlineAfter <= 0 || input.DeadlineAfter > maxDeadlineAfter {
+		return domainerrors.NewValidationError("Deadline must be between 1 minute and 365 days after start")
+	}
+	if len(input.Checklist) > maxChecklistLength {
+		return domainerrors.NewValidationError("Too many checklist items").WithMeta("max", maxChecklistLength)
+	}
+	for i, title := range input.Checklist {
+		title = strings.TrimSpace(title)
+		if title == "" || utf8.RuneCountInString(title) > maxChecklistItemLength {
+			return domainerrors.NewValidationError("Checklist item title is required and must be shorter than 500 characters").
+				WithMeta("index", i)
+		}
+		input.Checklist[i] = title
+	}
+	if input.ExecutorID != 0 {
+		inCompany, err := s.userRepo.IsUserInCompany(ctx, input.ExecutorID, input.CompanyID)
+		if err != nil {
+			return err
+		}
+		if !inCompany {
+			return domainerrors.NewValidationError("Executor not in company").WithMeta("userId", input.ExecutorID)
+		}
+	}
+	return nil
+}
+
+// attachFiles прикрепляет загрузки к шаблону внутри транзакции с учетом квоты компании под ее блокировкой
+func (s *TemplateService) attachFiles(ctx context.Context, template *model.TaskTemplate, fileIDs []string, userID uint) error {
+	attached, err := s.uploadService.AttachUploads(ctx, fileIDs, userID)
+	if err != nil {
+		return err
+	}
+	if len(attached) == 0 {
+		return nil
+	}
+	var size int64
+	for _, f := range attached {
+		size += f.Size
+	}
+	if err := s.fileService.LockQuota(ctx, template.CompanyID, 0); err != nil {
+		return err
+	}
+	if err := s.fileService.CheckQuota(ctx, template.CompanyID, 0, size); err != nil {
+		return err
+	}
+	template.Files = append(template.Files, attached...)
+	return nil
+}
+
+func applyInput(template *model.TaskTemplate, input TemplateInput) {
+	template.Name = input.Name
+	template.Title = input.Title
+	template.Description = input.Description
+	template.Priority = max(input.Priority, 1)
+	template.ExecutorID = input.ExecutorID
+	template.Checklist = input.Checklist
+	template.DeadlineAfter = input.DeadlineAfter
+}
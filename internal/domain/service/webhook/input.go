@@ -0,0 +1,10 @@
+package webhook
+
+type WebhookInput struct {
+	CompanyID uint
+	URL       string
+	Events    []string
+	// Secret пустой при создании генерируется, при изменении оставляет прежний
+	Secret string
+	Active bool
+}
@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"encoding/json"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"time"
+)
+
+// TestEvent событие кнопки "отправить тестовое событие", на него нельзя подписаться
+const TestEvent = "webhook.test"
+
+// Events события, на которые подписываются вебхуки
+var Events = []event.Type{
+	event.TaskCreated,
+	event.TaskMoved,
+	event.TaskAssigned,
+	event.BlockerCompleted,
+	event.DeadlineReminder,
+	event.TaskOverdue,
+	event.CommentCreated,
+}
+
+// envelope тело запроса вебхука
+type envelope struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	CompanyID  uint      `json:"companyId"`
+	ActorID    uint      `json:"actorId,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       any       `json:"data"`
+}
+
+type taskData struct {
+	ID          uint         `json:"id"`
+	Title       string       `json:"title"`
+	Status      model.Status `json:"status"`
+	Priority    uint         `json:"priority"`
+	CompanyID   uint         `json:"companyId"`
+	CreatorID   uint         `json:"creatorId"`
+	ExecutorIDs []uint       `json:"executorIds"`
+	WatcherIDs  []uint       `json:"watcherIds"`
+	ParentID    *uint        `json:"parentId,omitempty"`
+	StartAt     time.Time    `json:"startAt"`
+	DeadlineAt  time.Time    `json:"deadlineAt"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty"`
+}
+
+type commentData struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"userId"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newTaskData(task *model.Task) taskData {
+	data := taskData{
+		ID:          task.ID,
+		Title:       task.Title,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		CompanyID:   task.CompanyID,
+		CreatorID:   task.CreatorID,
+		ExecutorIDs: task.ExecutorIDs(),
+		WatcherIDs:  task.WatcherIDs(),
+		ParentID:    task.ParentID,
+		StartAt:     task.StartAt,
+		DeadlineAt:  task.DeadlineAt,
+	}
+	if data.WatcherIDs == nil {
+		data.WatcherIDs = []uint{}
+	}
+	if !task.CompletedAt.IsZero() {
+		data.CompletedAt = &task.CompletedAt
+	}
+	return data
+}
+
+// eventData данные события для тела запроса, false для событий, которые вебхукам не отправляются
+func eventData(e event.Event) (any, bool) {
+	switch payload := e.Payload.(type) {
+	case event.TaskCreatedPayload:
+		return map[string]any{"task": newTaskData(payload.Task)}, true
+	case event.TaskMovedPayload:
+		return map[string]any{
+			"task":       newTaskData(payload.Task),
+			"fromStatus": payload.FromStatus,
+			"prevTaskId": payload.PrevTaskID,
+			"nextTaskId": payload.NextTaskID,
+		}, true
+	case event.TaskAssignedPayload:
+		return map[string]any{
+			"task":        newTaskData(payload.Task),
+			"executorIds": nonNil(payload.ExecutorIDs),
+			"watcherIds":  nonNil(payload.WatcherIDs),
+		}, true
+	case event.BlockerCompletedPayload:
+		return map[string]any{
+			"task":         newTaskData(payload.Task),
+			"blocker":      newTaskData(payload.Blocker),
+			"openBlockers": payload.OpenBlockers,
+		}, true
+	case event.DeadlinePayload:
+		return map[string]any{
+			"task":         newTaskData(payload.Task),
+			"aheadMinutes": int(payload.Ahead / time.Minute),
+			"escalated":    payload.Escalated,
+		}, true
+	case event.CommentCreatedPayload:
+		return map[string]any{
+			"task": newTaskData(payload.Task),
+			"comment": commentData{
+				ID:        payload.Comment.ID,
+				UserID:    payload.Comment.UserID,
+				Content:   payload.Comment.Content,
+				CreatedAt: payload.Comment.CreatedAt,
+			},
+		}, true
+	}
+	return nil, false
+}
+
+func marshalEnvelope(id string, eventType string, companyID uint, actorID uint, occurredAt time.Time, data any) (json.RawMessage, error) {
+	return json.Marshal(envelope{
+		ID:         id,
+		Event:      eventType,
+		CompanyID:  companyID,
+		ActorID:    actorID,
+		OccurredAt: occurredAt.UTC(),
+		Data:       data,
+	})
+}
+
+func nonNil(ids []uint) []uint {
+	if ids == nil {
+		return []uint{}
+	}
+	return ids
+}
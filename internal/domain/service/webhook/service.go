@@ -0,0 +1,472 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/valueobject"
+	webhookclient "rttask/internal/infrastructure/webhook"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	maxURLLength    = 2048
+	minSecretLength = 16
+	maxSecretLength = 255
+	// maxAttemptLog сколько последних попыток хранится в журнале доставки
+	maxAttemptLog = 20
+	// dispatchBatch сколько доставок отправляется за один проход
+	dispatchBatch = 100
+)
+
+// Options MaxAttempts попыток до перевода доставки в dead, Backoff пауза после первой неудачи,
+// дальше она удваивается до MaxBackoff. Lease на сколько доставка откладывается на время отправки,
+// если экземпляр упадет посреди отправки, ее подхватит другой после Lease
+type Options struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Lease       time.Duration
+}
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	userRepo    repository.UserRepository
+	uow         repository.UnitOfWork
+	sender      webhookclient.Sender
+	opts        Options
+	wake        chan struct{}
+	logger      *zap.Logger
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, userRepo repository.UserRepository, uow repository.UnitOfWork, sender webhookclient.Sender, opts Options, logger *zap.Logger) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		userRepo:    userRepo,
+		uow:         uow,
+		sender:      sender,
+		opts:        opts,
+		wake:        make(chan struct{}, 1),
+		logger:      logger,
+	}
+}
+
+// Subscribe на каждое событие создаются доставки подписанным вебхукам, отправляет их WebhookDispatcher
+func (s *WebhookService) Subscribe(bus *event.Bus) {
+	for _, eventType := range Events {
+		bus.Subscribe(eventType, s.onEvent)
+	}
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, input WebhookInput, userID uint) (*model.Webhook, error) {
+	if err := s.authorize(ctx, userID, input.CompanyID); err != nil {
+		return nil, err
+	}
+	if err := s.validate(&input); err != nil {
+		return nil, err
+	}
+	if input.Secret == "" {
+		input.Secret = newSecret()
+	}
+
+	webhook, err := s.webhookRepo.Create(ctx, &model.Webhook{
+		CompanyID: input.CompanyID,
+		CreatorID: userID,
+		URL:       input.URL,
+		Events:    input.Events,
+		Secret:    input.Secret,
+		Active:    true,
+	})
+	if err != nil {
+		s.logger.Error("failed to create webhook", zap.Error(err))
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, companyID uint, userID uint) ([]*model.Webhook, error) {
+	if err := s.authorize(ctx, userID, companyID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.ListByCompany(ctx, companyID)
+}
+
+func (s *WebhookService) UpdateWebhook(ctx context.Context, id uint, input WebhookInput, userID uint) (*model.Webhook, error) {
+	webhook, err := s.webhookForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	input.CompanyID = webhook.CompanyID
+	if err := s.validate(&input); err != nil {
+		return nil, err
+	}
+
+	webhook.URL = input.URL
+	webhook.Events = input.Events
+	webhook.Active = input.Active
+	if input.Secret != "" {
+		webhook.Secret = input.Secret
+	}
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		s.logger.Error("failed to update webhook", zap.Uint("webhookID", id), zap.Error(err))
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook неотправленные доставки удаленного вебхука уходят в dead при следующей попытке
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id uint, userID uint) error {
+	webhook, err := s.webhookForUser(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	return s.webhookRepo.Delete(ctx, webhook)
+}
+
+// ListDeliveries журнал доставок вебхука, новые первыми
+func (s *WebhookService) ListDeliveries(ctx context.Context, id uint, params valueobject.PaginationParams, userID uint) ([]*model.WebhookDelivery, int64, error) {
+	if _, err := s.webhookForUser(ctx, id, userID); err != nil {
+		return nil, 0, err
+	}
+	return s.webhookRepo.ListDeliveries(ctx, id, params)
+}
+
+// SendTest отправляет тестовое событие сразу и возвращает доставку с результатом.
+// Тестовое событие не повторяется автоматически: при неудаче оно сразу в dead
+func (s *WebhookService) SendTest(ctx context.Context, id uint, userID uint) (*model.WebhookDelivery, error) {
+	webhook, err := s.webhookForUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	eventID := uuid.NewString()
+	body, err := marshalEnvelope(eventID, TestEvent, webhook.CompanyID, userID, now, map[string]any{
+		"webhookId": webhook.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery := &model.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   eventID,
+		Event:     TestEvent,
+		Payload:   body,
+		Status:    model.DeliveryPending,
+		// диспетчер не должен подхватить доставку, пока она отправляется здесь
+		NextAttemptAt: now.Add(s.opts.Lease),
+	}
+	if err := s.webhookRepo.CreateDeliveries(ctx, []*model.WebhookDelivery{delivery}); err != nil {
+		return nil, err
+	}
+
+	s.attempt(ctx, webhook, delivery, 1)
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		s.logger.Error("failed to update webhook delivery", zap.Uint("deliveryID", delivery.ID), zap.Error(err))
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// RetryDelivery возвращает доставку в очередь с новым счетчиком попыток
+func (s *WebhookService) RetryDelivery(ctx context.Context, id uint, deliveryID uint, userID uint) (*model.WebhookDelivery, error) {
+	if _, err := s.webhookForUser(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	delivery, err := s.webhookRepo.GetDelivery(ctx, id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == model.DeliveryPending {
+		return nil, domainerrors.NewConflictError("delivery is already pending")
+	}
+
+	delivery.Status = model.DeliveryPending
+	delivery.AttemptCount = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	s.signal()
+	return delivery, nil
+}
+
+// Dispatch отправляет доставки, время которых наступило. Возвращает число успешных
+func (s *WebhookService) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	ids, err := s.webhookRepo.GetDueDeliveryIDs(ctx, now, dispatchBatch)
+	if err != nil {
+		s.logger.Error("failed to get due webhook deliveries", zap.Error(err))
+		return 0, err
+	}
+
+	delivered := 0
+	for _, id := range ids {
+		ok, err := s.dispatch(ctx, id, now)
+		if err != nil {
+			s.logger.Error("failed to dispatch webhook delivery", zap.Uint("deliveryID", id), zap.Error(err))
+			continue
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// dispatch забирает доставку коротким захватом, а HTTP запрос выполняет уже вне транзакции
+func (s *WebhookService) dispatch(ctx context.Context, id uint, now time.Time) (bool, error) {
+	var delivery *model.WebhookDelivery
+	var webhook *model.Webhook
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		delivery, err = s.webhookRepo.LockDueDelivery(ctx, id, now)
+		if err != nil || delivery == nil {
+			return err
+		}
+
+		webhook, err = s.webhookRepo.GetByID(ctx, delivery.WebhookID)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if webhook == nil || !webhook.Active {
+			delivery.Status = model.DeliveryDead
+			delivery.Attempts = appendAttempt(delivery.Attempts, model.DeliveryAttempt{At: now, Error: "webhook is deleted or disabled"})
+			return s.webhookRepo.UpdateDelivery(ctx, delivery)
+		}
+
+		delivery.NextAttemptAt = now.Add(s.opts.Lease)
+		return s.webhookRepo.UpdateDelivery(ctx, delivery)
+	})
+	if err != nil || delivery == nil || webhook == nil || !webhook.Active {
+		return false, err
+	}
+
+	s.attempt(ctx, webhook, delivery, s.opts.MaxAttempts)
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return false, err
+	}
+	return delivery.Status == model.DeliveryDelivered, nil
+}
+
+// attempt одна попытка отправки. Переводит доставку в delivered, в dead после maxAttempts неудач
+// или назначает следующую попытку с экспоненциальной паузой
+func (s *WebhookService) attempt(ctx context.Context, webhook *model.Webhook, delivery *model.WebhookDelivery, maxAttempts int) {
+	start := time.Now()
+	statusCode, err := s.sender.Send(ctx, webhookclient.Request{
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		Event:      delivery.Event,
+		DeliveryID: delivery.EventID,
+		Body:       delivery.Payload,
+	})
+	finished := time.Now()
+
+	attempt := model.DeliveryAttempt{At: start, StatusCode: statusCode, Duration: finished.Sub(start)}
+	delivery.AttemptCount++
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = &finished
+	case delivery.AttemptCount >= maxAttempts:
+		attempt.Error = err.Error()
+		delivery.Status = model.DeliveryDead
+		s.logger.Warn("webhook delivery is dead",
+			zap.Uint("deliveryID", delivery.ID),
+			zap.Uint("webhookID", webhook.ID),
+			zap.Error(err),
+		)
+	default:
+		attempt.Error = err.Error()
+		delivery.NextAttemptAt = finished.Add(s.backoff(delivery.AttemptCount))
+	}
+	delivery.Attempts = appendAttempt(delivery.Attempts, attempt)
+}
+
+func (s *WebhookService) backoff(attempt int) time.Duration {
+	backoff := s.opts.Backoff
+	for i := 1; i < attempt && backoff < s.opts.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, s.opts.MaxBackoff)
+}
+
+// onEvent ошибки только логируются: событие уже произошло, и операцию, которая его породила, не откатить
+func (s *WebhookService) onEvent(ctx context.Context, e event.Event) {
+	data, ok := eventData(e)
+	if !ok {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	webhooks, err := s.webhookRepo.ListSubscribed(ctx, e.CompanyID, string(e.Type))
+	if err != nil {
+		s.logger.Error("failed to get subscribed webhooks", zap.String("event", string(e.Type)), zap.Error(err))
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	eventID := uuid.NewString()
+	body, err := marshalEnvelope(eventID, string(e.Type), e.CompanyID, e.ActorID, e.OccurredAt, data)
+	if err != nil {
+		s.logger.Error("failed to marshal webhook payload", zap.String("event", string(e.Type)), zap.Error(err))
+		return
+	}
+
+	deliveries := make([]*model.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			Event:         string(e.Type),
+			Payload:       body,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: e.OccurredAt,
+		})
+	}
+	if err := s.webhookRepo.CreateDeliveries(ctx, deliveries); err != nil {
+		s.logger.Error("failed to create webhook deliveries", zap.String("event", string(e.Type)), zap.Error(err))
+		return
+	}
+	s.signal()
+}
+
+// signal будит диспетчер, не дожидаясь тикера
+func (s *WebhookService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *WebhookService) validate(input *WebhookInput) error {
+	if utf8.RuneCountInString(input.URL) > maxURLLength {
+		return domainerrors.NewValidationError("Webhook URL is too long").WithMeta("max", maxURLLength)
+	}
+	parsed, err := url.Parse(input.URL)
+	if err != nil || parsed.Host == "" || parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return domainerrors.NewValidationError("Webhook URL must be an absolute http or https URL")
+	}
+	if len(input.Events) == 0 {
+		return domainerrors.NewValidationError("At least one event is required")
+	}
+	events := make([]string, 0, len(input.Events))
+	for _, eventType := range input.Events {
+		if !slices.Contains(Events, event.Type(eventType)) {
+			return domainerrors.NewValidationError("Unknown event").WithMeta("event", eventType)
+		}
+		if !slices.Contains(events, eventType) {
+			events = append(events, eventType)
+		}
+	}
+	input.Events = events
+	if input.Secret != "" && (len(input.Secret) < minSecretLength || len(input.Secret) > maxSecretLength) {
+		return domainerrors.NewValidationError("Secret must be between 16 and 255 characters")
+	}
+	return nil
+}
+
+func (s *WebhookService) webhookForUser(ctx context.Context, id uint, userID uint) (*model.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, userID, webhook.CompanyID); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// authorize вебхуками компании управляют ее участники с правом company:update
+func (s *WebhookService) authorize(ctx context.Context, userID uint, companyID uint) error {
+	user, err := s.userRepo.GetUserByIDWithRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.Can(rbac.CompanyUpdate) {
+		return domainerrors.NewForbiddenError("dont have permission")
+	}
+	inCompany, err := s.userRepo.IsUserInCompany(ctx, userID, companyID)
+	if err != nil {
+		return err
+	}
+	if !inCompany {
+		return domainerrors.NewForbiddenError("user not in company").WithMeta("companyId", companyID)
+	}
+	return nil
+}
+
+func appendAttempt(attempts []model.DeliveryAttempt, attempt model.DeliveryAttempt) []model.DeliveryAttempt {
+	attempts = append(attempts, attempt)
+	if len(attempts) > maxAttemptLog {
+		attempts = attempts[len(attempts)-maxAttemptLog:]
+	}
+	return attempts
+}
+
+func isNotFound(err error) bool {
+	var domainErr *domainerrors.DomainError
+	return errors.As(err, &domainErr) && domainErr.Type == domainerrors.ErrorTypeNotFound
+}
+
+func newSecret() string {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return hex.EncodeToString(secret)
+}
+
+// WebhookDispatcher отправляет доставки по тикеру и сразу после появления новых
+type WebhookDispatcher struct {
+	service *WebhookService
+	logger  *zap.Logger
+}
+
+func NewWebhookDispatcher(service *WebhookService, logger *zap.Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	delivered, err := d.service.Dispatch(ctx, time.Now())
+	if err != nil {
+		d.logger.Error("webhook dispatcher failed", zap.Error(err))
+		return
+	}
+	if delivered > 0 {
+		d.logger.Info("webhook dispatcher finished", zap.Int("delivered", delivered))
+	}
+}
+
+// Start первый проход сразу при запуске, затем по расписанию и по сигналу до отмены контекста
+func (d *WebhookDispatcher) Start(ctx context.Context, interval time.Duration) {
+	d.Run(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Run(ctx)
+		case <-d.service.wake:
+			d.Run(ctx)
+		}
+	}
+}
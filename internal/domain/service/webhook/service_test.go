@@ -0,0 +1,246 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/repository/repotest"
+	webhookclient "rttask/internal/infrastructure/webhook"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+	webhooks   map[uint]*model.Webhook
+	deliveries []*model.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) GetByID(ctx context.Context, id uint) (*model.Webhook, error) {
+	return r.webhooks[id], nil
+}
+
+func (r *fakeWebhookRepo) ListSubscribed(ctx context.Context, companyID uint, eventType string) ([]*model.Webhook, error) {
+	var result []*model.Webhook
+	for _, webhook := range r.webhooks {
+		for _, subscribed := range webhook.Events {
+			if webhook.CompanyID == companyID && webhook.Active && subscribed == eventType {
+				result = append(result, webhook)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeWebhookRepo) CreateDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error {
+	for _, delivery := range deliveries {
+		delivery.ID = uint(len(r.deliveries) + 1)
+		r.deliveries = append(r.deliveries, delivery)
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return nil
+}
+
+func (r *fakeWebhookRepo) GetDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	for _, delivery := range r.deliveries {
+		if delivery.Status == model.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			ids = append(ids, delivery.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeWebhookRepo) LockDueDelivery(ctx context.Context, id uint, now time.Time) (*model.WebhookDelivery, error) {
+	delivery := r.deliveries[id-1]
+	if delivery.Status != model.DeliveryPending || delivery.NextAttemptAt.After(now) {
+		return nil, nil
+	}
+	return delivery, nil
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver получатель вебхуков, который отвечает statuses по очереди, а после них 200
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newWebhookFixture(t *testing.T, statuses []int, maxAttempts int) (*WebhookService, *fakeWebhookRepo, *receiver) {
+	t.Helper()
+	recv := &receiver{statuses: statuses}
+	server := httptest.NewServer(recv)
+	t.Cleanup(server.Close)
+
+	repo := &fakeWebhookRepo{webhooks: map[uint]*model.Webhook{
+		1: {Model: gorm.Model{ID: 1}, CompanyID: 5, URL: server.URL + "/hooks", Events: []string{string(event.TaskCreated)}, Secret: testSecret, Active: true},
+	}}
+	// httptest слушает 127.0.0.1, внутренние адреса разрешаются явно, как в конфиге для разработки
+	sender := webhookclient.MustNewHTTPSender(5*time.Second, []string{"127.0.0.0/8"})
+	service := NewWebhookService(repo, nil, repotest.UnitOfWork{}, sender, Options{
+		MaxAttempts: maxAttempts,
+		Backoff:     30 * time.Second,
+		MaxBackoff:  10 * time.Minute,
+		Lease:       time.Minute,
+	}, zap.NewNop())
+
+	bus := event.NewBus(zap.NewNop())
+	service.Subscribe(bus)
+	bus.Publish(context.Background(), event.Event{
+		Type:      event.TaskCreated,
+		CompanyID: 5,
+		ActorID:   2,
+		Payload:   event.TaskCreatedPayload{Task: &model.Task{Model: gorm.Model{ID: 7}, Title: "Release", CompanyID: 5}},
+	})
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("created %d deliveries, want 1", len(repo.deliveries))
+	}
+	return service, repo, recv
+}
+
+func TestWebhookDeliverySignedWithRetries(t *testing.T) {
+	service, repo, recv := newWebhookFixture(t, []int{http.StatusInternalServerError, http.StatusBadGateway}, 5)
+	delivery := repo.deliveries[0]
+	ctx := context.Background()
+
+	// первая попытка сразу, дальше пауза 30s и 60s
+	now := time.Now()
+	if n, err := service.Dispatch(ctx, now); err != nil || n != 0 {
+		t.Fatalf("first Dispatch = %d, %v, want failed attempt", n, err)
+	}
+	if n, _ := service.Dispatch(ctx, now.Add(20*time.Second)); n != 0 || len(recv.received()) != 1 {
+		t.Fatalf("retried before the backoff elapsed: %d requests", len(recv.received()))
+	}
+	assertBackoff(t, delivery, 30*time.Second)
+
+	if n, _ := service.Dispatch(ctx, delivery.NextAttemptAt); n != 0 {
+		t.Fatalf("second attempt delivered despite 502")
+	}
+	assertBackoff(t, delivery, time.Minute)
+
+	if n, err := service.Dispatch(ctx, delivery.NextAttemptAt); err != nil || n != 1 {
+		t.Fatalf("third Dispatch = %d, %v, want delivered", n, err)
+	}
+	if delivery.Status != model.DeliveryDelivered || delivery.AttemptCount != 3 || delivery.DeliveredAt == nil {
+		t.Fatalf("delivery = status %s after %d attempts, want delivered after 3", delivery.Status, delivery.AttemptCount)
+	}
+	codes := []int{500, 502, 200}
+	for i, attempt := range delivery.Attempts {
+		if attempt.StatusCode != codes[i] || (attempt.Error == "") != (codes[i] == 200) {
+			t.Errorf("attempt %d = %+v, want status %d", i, attempt, codes[i])
+		}
+	}
+
+	requests := recv.received()
+	if len(requests) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(requests))
+	}
+	for i, req := range requests {
+		if req.header.Get(webhookclient.EventHeader) != string(event.TaskCreated) {
+			t.Errorf("request %d event header = %q", i, req.header.Get(webhookclient.EventHeader))
+		}
+		// повтор отправляет то же событие байт в байт, получатель отсекает дубли по id доставки
+		if req.header.Get(webhookclient.DeliveryHeader) != delivery.EventID || string(req.body) != string(requests[0].body) {
+			t.Errorf("request %d differs from the first one", i)
+		}
+		timestamp, err := strconv.ParseInt(req.header.Get(webhookclient.TimestampHeader), 10, 64)
+		if err != nil {
+			t.Fatalf("request %d timestamp header: %v", i, err)
+		}
+		if !webhookclient.Verify(testSecret, timestamp, req.body, req.header.Get(webhookclient.SignatureHeader), time.Minute) {
+			t.Errorf("request %d has an invalid signature %q", i, req.header.Get(webhookclient.SignatureHeader))
+		}
+		if webhookclient.Verify("another-secret-of-enough-length", timestamp, req.body, req.header.Get(webhookclient.SignatureHeader), time.Minute) {
+			t.Errorf("request %d signature accepted with a wrong secret", i)
+		}
+	}
+
+	var body struct {
+		ID        string `json:"id"`
+		Event     string `json:"event"`
+		CompanyID uint   `json:"companyId"`
+		Data      struct {
+			Task struct {
+				ID    uint   `json:"id"`
+				Title string `json:"title"`
+			} `json:"task"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(requests[0].body, &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if body.ID != delivery.EventID || body.Event != string(event.TaskCreated) || body.CompanyID != 5 || body.Data.Task.ID != 7 || body.Data.Task.Title != "Release" {
+		t.Errorf("body = %s", requests[0].body)
+	}
+}
+
+func TestWebhookDeliveryDeadAfterMaxAttempts(t *testing.T) {
+	statuses := []int{503, 503, 503, 503, 503}
+	service, repo, recv := newWebhookFixture(t, statuses, 3)
+	delivery := repo.deliveries[0]
+	ctx := context.Background()
+
+	at := time.Now()
+	for i := 0; i < 5; i++ {
+		service.Dispatch(ctx, at)
+		at = at.Add(time.Hour)
+	}
+	if delivery.Status != model.DeliveryDead || delivery.AttemptCount != 3 {
+		t.Fatalf("delivery = status %s after %d attempts, want dead after 3", delivery.Status, delivery.AttemptCount)
+	}
+	if got := len(recv.received()); got != 3 {
+		t.Fatalf("receiver got %d requests, want 3", got)
+	}
+	last := delivery.Attempts[len(delivery.Attempts)-1]
+	if last.StatusCode != 503 || last.Error == "" {
+		t.Errorf("last attempt = %+v, want 503 with error", last)
+	}
+}
+
+// assertBackoff следующая попытка через want после последней
+func assertBackoff(t *testing.T, delivery *model.WebhookDelivery, want time.Duration) {
+	t.Helper()
+	last := delivery.Attempts[len(delivery.Attempts)-1]
+	got := delivery.NextAttemptAt.Sub(last.At)
+	if got < want || got > want+time.Second {
+		t.Fatalf("next attempt in %v after attempt %d, want %v", got, delivery.AttemptCount, want)
+	}
+}
@@ -0,0 +1,98 @@
+package valueobject
+
+import (
+	"encoding/base64"
+	"fmt"
+	domainerrors "rttask/internal/domain/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CursorDirection string
+
+const (
+	CursorNext CursorDirection = "n"
+	CursorPrev CursorDirection = "p"
+)
+
+// Cursor позиция в списке, отсортированном по (created_at, id). Клиенту отдается закодированной строкой,
+// направление зашито в курсор, поэтому nextCursor и prevCursor передаются в один и тот же параметр
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+	Direction CursorDirection
+}
+
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%s,%s,%d", c.Direction, c.CreatedAt.UTC().Format(time.RFC3339Nano), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	invalid := domainerrors.NewValidationError("Invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+	parts := strings.Split(string(raw), ",")
+	if len(parts) != 3 {
+		return nil, invalid
+	}
+
+	direction := CursorDirection(parts[0])
+	if direction != CursorNext && direction != CursorPrev {
+		return nil, invalid
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	return &Cursor{CreatedAt: createdAt, ID: uint(id), Direction: direction}, nil
+}
+
+// Page страница списка. NextCursor и PrevCursor заполняются только при курсорной пагинации
+type Page[T any] struct {
+	Items      []T
+	Total      int64
+	NextCursor string
+	PrevCursor string
+}
+
+// NewCursorPage строит страницу из выборки репозитория. Репозиторий выбирает на один элемент больше
+// Limit, лишний элемент означает, что в направлении курсора есть еще данные
+func NewCursorPage[T any](items []T, total int64, params PaginationParams, key func(T) (time.Time, uint)) *Page[T] {
+	page := &Page[T]{Total: total}
+	hasMore := len(items) > params.Limit
+	backward := params.Cursor != nil && params.Cursor.Direction == CursorPrev
+
+	if hasMore {
+		if backward {
+			// при движении назад лишний элемент самый дальний от курсора, то есть первый
+			items = items[1:]
+		} else {
+			items = items[:params.Limit]
+		}
+	}
+	page.Items = items
+	if len(items) == 0 {
+		return page
+	}
+
+	cursorAt := func(item T, direction CursorDirection) string {
+		createdAt, id := key(item)
+		return Cursor{CreatedAt: createdAt, ID: id, Direction: direction}.Encode()
+	}
+	if hasMore || backward {
+		page.NextCursor = cursorAt(items[len(items)-1], CursorNext)
+	}
+	if params.Cursor != nil && (hasMore || !backward) {
+		page.PrevCursor = cursorAt(items[0], CursorPrev)
+	}
+	return page
+}
@@ -0,0 +1,93 @@
+package valueobject
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// MentionToken упоминание @handle в тексте. Handle e-mail пользователя или его часть до @
+// в нижнем регистре. Offset и Length в UTF-16 единицах и включают @
+type MentionToken struct {
+	Handle string
+	Offset int
+	Length int
+}
+
+// ParseMentions упоминания в тексте по порядку, с повторами. @ начинает упоминание в начале текста
+// или после символа, который не может быть частью адреса, поэтому e-mail посреди текста не упоминание.
+// Точки и дефисы в конце, например в конце предложения, в упоминание не входят
+func ParseMentions(text string) []MentionToken {
+	runes := []rune(text)
+	var tokens []MentionToken
+	offset := 0
+	for i := 0; i < len(runes); {
+		if runes[i] == '@' && (i == 0 || !isAddressRune(runes[i-1])) {
+			if end := scanHandle(runes, i+1); end > i+1 {
+				length := utf16Len(runes[i:end])
+				tokens = append(tokens, MentionToken{
+					Handle: strings.ToLower(string(runes[i+1 : end])),
+					Offset: offset,
+					Length: length,
+				})
+				offset += length
+				i = end
+				continue
+			}
+		}
+		offset += utf16.RuneLen(runes[i])
+		i++
+	}
+	return tokens
+}
+
+// scanHandle конец handle, начинающегося с start: часть адреса до @ и необязательный домен с точкой
+func scanHandle(runes []rune, start int) int {
+	end := start
+	for end < len(runes) && isLocalRune(runes[end]) {
+		end++
+	}
+	end = trimHandle(runes, start, end)
+	if end == start {
+		return start
+	}
+
+	if end < len(runes) && runes[end] == '@' {
+		domainEnd := end + 1
+		for domainEnd < len(runes) && isDomainRune(runes[domainEnd]) {
+			domainEnd++
+		}
+		domainEnd = trimHandle(runes, end+1, domainEnd)
+		if strings.ContainsRune(string(runes[end+1:domainEnd]), '.') {
+			return domainEnd
+		}
+	}
+	return end
+}
+
+func trimHandle(runes []rune, start, end int) int {
+	for end > start && strings.ContainsRune(".-_", runes[end-1]) {
+		end--
+	}
+	return end
+}
+
+func isLocalRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("._%+-", r))
+}
+
+func isDomainRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".-", r))
+}
+
+func isAddressRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("._%+-@", r)
+}
+
+func utf16Len(runes []rune) int {
+	length := 0
+	for _, r := range runes {
+		length += utf16.RuneLen(r)
+	}
+	return length
+}
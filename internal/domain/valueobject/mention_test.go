@@ -0,0 +1,69 @@
+package valueobject
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []MentionToken
+	}{
+		{name: "only mention", text: "@ivan", want: []MentionToken{{Handle: "ivan", Offset: 0, Length: 5}}},
+		{name: "lowercased", text: "@Ivan.Petrov", want: []MentionToken{{Handle: "ivan.petrov", Offset: 0, Length: 12}}},
+		{name: "full email", text: "@ivan@example.com", want: []MentionToken{{Handle: "ivan@example.com", Offset: 0, Length: 17}}},
+		{name: "email with plus and subdomain", text: "@ivan+tasks@mail.example.com", want: []MentionToken{{Handle: "ivan+tasks@mail.example.com", Offset: 0, Length: 28}}},
+		{name: "domain without dot is not part of handle", text: "@ivan@localhost", want: []MentionToken{{Handle: "ivan", Offset: 0, Length: 5}}},
+		{name: "email in text is not a mention", text: "пишите на ivan@example.com", want: nil},
+		{name: "at after letter", text: "ivan@petr", want: nil},
+		{name: "at after cyrillic letter", text: "Иван@petr", want: nil},
+		{name: "double at", text: "@@ivan", want: nil},
+		{name: "cyrillic handle", text: "@Иван", want: nil},
+		{name: "empty handle", text: "@ ivan", want: nil},
+		{name: "only punctuation", text: "@.-_", want: nil},
+		{name: "sentence end", text: "Спасибо, @ivan.", want: []MentionToken{{Handle: "ivan", Offset: 9, Length: 5}}},
+		{name: "email at sentence end", text: "@ivan@example.com.", want: []MentionToken{{Handle: "ivan@example.com", Offset: 0, Length: 17}}},
+		{name: "trailing dash and underscore", text: "@ivan-_ ок", want: []MentionToken{{Handle: "ivan", Offset: 0, Length: 5}}},
+		{name: "exclamation and question", text: "@ivan! @petr?", want: []MentionToken{
+			{Handle: "ivan", Offset: 0, Length: 5},
+			{Handle: "petr", Offset: 7, Length: 5},
+		}},
+		{name: "brackets", text: "(@ivan)", want: []MentionToken{{Handle: "ivan", Offset: 1, Length: 5}}},
+		{name: "comma separated", text: "@ivan,@petr", want: []MentionToken{
+			{Handle: "ivan", Offset: 0, Length: 5},
+			{Handle: "petr", Offset: 6, Length: 5},
+		}},
+		{name: "cyrillic suffix stops handle", text: "@ivanу задачи", want: []MentionToken{{Handle: "ivan", Offset: 0, Length: 5}}},
+		{name: "repeated", text: "@ivan и @ivan", want: []MentionToken{
+			{Handle: "ivan", Offset: 0, Length: 5},
+			{Handle: "ivan", Offset: 8, Length: 5},
+		}},
+		{name: "after cyrillic text", text: "Привет, @ivan", want: []MentionToken{{Handle: "ivan", Offset: 8, Length: 5}}},
+		// смайлик занимает две UTF-16 единицы
+		{name: "after emoji", text: "😀 @ivan 😀 @petr", want: []MentionToken{
+			{Handle: "ivan", Offset: 3, Length: 5},
+			{Handle: "petr", Offset: 12, Length: 5},
+		}},
+		{name: "after newline", text: "строка\n@ivan", want: []MentionToken{{Handle: "ivan", Offset: 7, Length: 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseMentions(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseMentions(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+
+			// Offset и Length указывают на "@handle" в UTF-16 представлении текста, как считает клиент
+			units := utf16.Encode([]rune(tt.text))
+			for _, token := range got {
+				mention := string(utf16.Decode(units[token.Offset : token.Offset+token.Length]))
+				if mention[0] != '@' || len(mention) != len(token.Handle)+1 {
+					t.Errorf("token %+v points at %q", token, mention)
+				}
+			}
+		})
+	}
+}
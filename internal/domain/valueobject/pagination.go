@@ -4,6 +4,10 @@ type PaginationParams struct {
 	Offset int
 	Limit  int
 	Page   int
+
+	// CursorMode keyset пагинация по (created_at, id) вместо Offset. Cursor nil для первой страницы
+	CursorMode bool
+	Cursor     *Cursor
 }
 
 func NewPaginationParams(page, pageSize int) PaginationParams {
@@ -14,3 +18,11 @@ func NewPaginationParams(page, pageSize int) PaginationParams {
 		Page:   page,
 	}
 }
+
+func NewCursorPaginationParams(cursor *Cursor, pageSize int) PaginationParams {
+	return PaginationParams{
+		Limit:      pageSize,
+		CursorMode: true,
+		Cursor:     cursor,
+	}
+}
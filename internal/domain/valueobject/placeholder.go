@@ -0,0 +1,40 @@
+package valueobject
+
+import (
+	"regexp"
+	"slices"
+)
+
+// placeholderPattern плейсхолдер вида {{name}}, пробелы внутри скобок допускаются
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z][a-zA-Z0-9_]*)\s*\}\}`)
+
+// Placeholders имена плейсхолдеров в текстах по порядку, без повторов
+func Placeholders(texts ...string) []string {
+	var names []string
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if !slices.Contains(names, match[1]) {
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// RenderPlaceholders подставляет значения в текст. Плейсхолдеры без значения остаются
+// в тексте как есть и возвращаются списком
+func RenderPlaceholders(text string, values map[string]string) (string, []string) {
+	var missing []string
+	rendered := placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := values[name]
+		if !ok {
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return match
+		}
+		return value
+	})
+	return rendered, missing
+}
@@ -0,0 +1,121 @@
+package valueobject
+
+import (
+	domainerrors "rttask/internal/domain/errors"
+	"strings"
+)
+
+// Ранги задач в колонке доски: строки из rankDigits, сравниваются побайтно (COLLATE "C").
+// Между любыми двумя рангами можно вставить новый, поэтому перемещение меняет только одну строку
+
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxRankLength после многократных вставок в одно место ранги удлиняются, тогда колонка перенумеровывается
+const MaxRankLength = 64
+
+// RankBetween ранг строго между prev и next. Пустой prev означает начало колонки, пустой next конец
+func RankBetween(prev, next string) (string, error) {
+	if !validRank(prev) || !validRank(next) {
+		return "", domainerrors.NewValidationError("Invalid rank")
+	}
+	if next != "" && prev >= next {
+		return "", domainerrors.NewConflictError("rank order is broken")
+	}
+	if next == "" && prev != "" {
+		return rankAfter(prev), nil
+	}
+	return rankMidpoint(prev, next, next != ""), nil
+}
+
+// rankAfter ранг для конца колонки: первый не максимальный разряд prev увеличивается на единицу,
+// остальные отбрасываются. Середина до бесконечности удлиняла бы ранг на разряд каждые несколько
+// вставок, а так ранг удлиняется только после len(rankDigits) вставок подряд
+func rankAfter(prev string) string {
+	last := rankDigits[len(rankDigits)-1]
+	i := 0
+	for i < len(prev) && prev[i] == last {
+		i++
+	}
+	digit := strings.IndexByte(rankDigits, digitAt(prev, i))
+	return prev[:i] + string(rankDigits[digit+1])
+}
+
+// EvenRanks n равномерно распределенных рангов одинаковой длины, для перенумерации колонки
+func EvenRanks(n int) []string {
+	base := len(rankDigits)
+	width, capacity := 1, base
+	for capacity <= n*2 {
+		width++
+		capacity *= base
+	}
+	step := capacity / (n + 1)
+
+	ranks := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		value := i * step
+		digits := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = rankDigits[value%base]
+			value /= base
+		}
+		// нули в конце не меняют порядок, но мешают вставке перед рангом
+		ranks = append(ranks, strings.TrimRight(string(digits), "0"))
+	}
+	return ranks
+}
+
+// rankMidpoint дробная середина между a и b в системе rankDigits. hasB false означает бесконечность
+func rankMidpoint(a, b string, hasB bool) string {
+	if hasB {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + rankMidpoint(suffix(a, n), b[n:], true)
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(rankDigits, a[0])
+	}
+	digitB := len(rankDigits)
+	if hasB {
+		digitB = strings.IndexByte(rankDigits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(rankDigits[(digitA+digitB+1)/2])
+	}
+	if hasB && len(b) > 1 {
+		return b[:1]
+	}
+	return string(rankDigits[digitA]) + rankMidpoint(suffix(a, 1), "", false)
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return rankDigits[0]
+}
+
+func suffix(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	return s[n:]
+}
+
+func validRank(rank string) bool {
+	if strings.HasSuffix(rank, "0") {
+		return false
+	}
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
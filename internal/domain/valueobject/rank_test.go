@@ -0,0 +1,128 @@
+package valueobject
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev, next string
+		want       string
+	}{
+		{name: "empty column", prev: "", next: "", want: "i"},
+		{name: "before first", prev: "", next: "i", want: "9"},
+		{name: "after last", prev: "i", next: "", want: "j"},
+		{name: "after max digit", prev: "z", next: "", want: "z1"},
+		{name: "after max prefix", prev: "zz5", next: "", want: "zz6"},
+		{name: "after long rank", prev: "5zzk", next: "", want: "6"},
+		{name: "between", prev: "a", next: "c", want: "b"},
+		{name: "between adjacent", prev: "a", next: "b", want: "ai"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RankBetween(tt.prev, tt.next)
+			if err != nil {
+				t.Fatalf("RankBetween(%q, %q) error: %v", tt.prev, tt.next, err)
+			}
+			if got != tt.want {
+				t.Errorf("RankBetween(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankBetweenErrors(t *testing.T) {
+	for _, tt := range []struct{ prev, next string }{
+		{"b", "a"},
+		{"a", "a"},
+		{"a0", ""},
+		{"A", ""},
+	} {
+		if _, err := RankBetween(tt.prev, tt.next); err == nil {
+			t.Errorf("RankBetween(%q, %q) expected error", tt.prev, tt.next)
+		}
+	}
+}
+
+// TestRankAppendGrowth вставки в конец колонки удлиняют ранг не быстрее чем на разряд за len(rankDigits) вставок
+func TestRankAppendGrowth(t *testing.T) {
+	const appends = 5000
+	last := ""
+	for i := 0; i < appends; i++ {
+		rank, err := RankBetween(last, "")
+		if err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+		if rank <= last {
+			t.Fatalf("append %d: rank %q is not after %q", i, rank, last)
+		}
+		last = rank
+	}
+	if max := appends/(len(rankDigits)-1) + 2; len(last) > max {
+		t.Errorf("rank length after %d appends = %d, want <= %d", appends, len(last), max)
+	}
+}
+
+// TestRankAppendWithRebalance колонка из многих тысяч задач, созданных по одной в конец, как insertTask:
+// ранг выходит за MaxRankLength, колонка перенумеровывается, порядок сохраняется
+func TestRankAppendWithRebalance(t *testing.T) {
+	var column []string
+	for i := 0; i < 20000; i++ {
+		last := ""
+		if len(column) > 0 {
+			last = column[len(column)-1]
+		}
+		rank, err := RankBetween(last, "")
+		if err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+		if len(rank) > MaxRankLength {
+			column = EvenRanks(len(column))
+			if rank, err = RankBetween(column[len(column)-1], ""); err != nil {
+				t.Fatalf("append %d after rebalance: %v", i, err)
+			}
+		}
+		if len(rank) > MaxRankLength {
+			t.Fatalf("append %d: rank %q longer than %d after rebalance", i, rank, MaxRankLength)
+		}
+		column = append(column, rank)
+	}
+	for i := 1; i < len(column); i++ {
+		if column[i-1] >= column[i] {
+			t.Fatalf("ranks %d and %d out of order: %q >= %q", i-1, i, column[i-1], column[i])
+		}
+	}
+}
+
+func TestRankInsertBeforeFirst(t *testing.T) {
+	first := "i"
+	for i := 0; i < 200; i++ {
+		rank, err := RankBetween("", first)
+		if err != nil {
+			t.Fatalf("insert %d: %v", i, err)
+		}
+		if rank >= first || strings.HasSuffix(rank, "0") {
+			t.Fatalf("insert %d: bad rank %q before %q", i, rank, first)
+		}
+		first = rank
+	}
+}
+
+func TestEvenRanks(t *testing.T) {
+	for _, n := range []int{1, 2, 35, 36, 1000, 20000} {
+		ranks := EvenRanks(n)
+		if len(ranks) != n {
+			t.Fatalf("EvenRanks(%d) returned %d ranks", n, len(ranks))
+		}
+		for i, rank := range ranks {
+			if !validRank(rank) {
+				t.Fatalf("EvenRanks(%d)[%d] = %q is invalid", n, i, rank)
+			}
+			if i > 0 && ranks[i-1] >= rank {
+				t.Fatalf("EvenRanks(%d) out of order at %d: %q >= %q", n, i, ranks[i-1], rank)
+			}
+		}
+	}
+}
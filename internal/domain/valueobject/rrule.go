@@ -0,0 +1,250 @@
+package valueobject
+
+import (
+	domainerrors "rttask/internal/domain/errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// maxRRuleIterations защита от бесконечного перебора для правил, у которых фильтр не дает дат
+const maxRRuleIterations = 100000
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RRule подмножество RFC 5545: FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, BYDAY без номеров, UNTIL и COUNT.
+// Время повторения берется из DTSTART
+type RRule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	Until    time.Time
+	Count    int
+}
+
+// ParseRRule разбирает правило вида "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10", префикс "RRULE:" допускается
+func ParseRRule(raw string) (RRule, error) {
+	rule := RRule{Interval: 1}
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "RRULE:")
+	if raw == "" {
+		return rule, domainerrors.NewValidationError("Recurrence rule is required")
+	}
+
+	for _, part := range strings.Split(raw, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return rule, invalidRRule(raw, part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				return rule, invalidRRule(raw, part)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > 365 {
+				return rule, invalidRRule(raw, part)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(value), ",") {
+				weekday, ok := rruleWeekdays[day]
+				if !ok {
+					return rule, invalidRRule(raw, part)
+				}
+				if !slices.Contains(rule.ByDay, weekday) {
+					rule.ByDay = append(rule.ByDay, weekday)
+				}
+			}
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return rule, invalidRRule(raw, part)
+			}
+			rule.Until = until
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return rule, invalidRRule(raw, part)
+			}
+			rule.Count = count
+		default:
+			return rule, invalidRRule(raw, part)
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, domainerrors.NewValidationError("FREQ is required in recurrence rule")
+	}
+	if rule.Freq == Monthly && len(rule.ByDay) > 0 {
+		return rule, domainerrors.NewValidationError("BYDAY is not supported for MONTHLY recurrence")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return rule, domainerrors.NewValidationError("UNTIL and COUNT cannot be used together")
+	}
+	// дни недели по порядку с понедельника, как при WKST=MO
+	slices.SortFunc(rule.ByDay, func(a, b time.Weekday) int { return mondayIndex(a) - mondayIndex(b) })
+	return rule, nil
+}
+
+func (r RRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			for name, day := range rruleWeekdays {
+				if day == weekday {
+					days = append(days, name)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between повторения в интервале (after, before]. dtstart первое возможное повторение,
+// его часовой пояс определяет переходы на летнее время
+func (r RRule) Between(dtstart, after, before time.Time) []time.Time {
+	var occurrences []time.Time
+	r.iterate(dtstart, func(t time.Time) bool {
+		if t.After(before) {
+			return false
+		}
+		if t.After(after) {
+			occurrences = append(occurrences, t)
+		}
+		return true
+	})
+	return occurrences
+}
+
+// Finished после last повторений больше не будет
+func (r RRule) Finished(dtstart, last time.Time) bool {
+	finished := true
+	r.iterate(dtstart, func(t time.Time) bool {
+		if t.After(last) {
+			finished = false
+			return false
+		}
+		return true
+	})
+	return finished
+}
+
+// iterate перебирает повторения по порядку, пока fn возвращает true, с учетом UNTIL и COUNT
+func (r RRule) iterate(dtstart time.Time, fn func(t time.Time) bool) {
+	interval := max(r.Interval, 1)
+	emitted := 0
+	// emit false останавливает перебор
+	emit := func(t time.Time) bool {
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return fn(t)
+	}
+	afterUntil := func(t time.Time) bool {
+		return !r.Until.IsZero() && t.After(r.Until)
+	}
+
+	switch r.Freq {
+	case Daily:
+		for i := 0; i < maxRRuleIterations; i++ {
+			t := dtstart.AddDate(0, 0, i*interval)
+			if afterUntil(t) {
+				return
+			}
+			if len(r.ByDay) > 0 && !slices.Contains(r.ByDay, t.Weekday()) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	case Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{dtstart.Weekday()}
+		}
+		weekStart := dtstart.AddDate(0, 0, -mondayIndex(dtstart.Weekday()))
+		for week := 0; week < maxRRuleIterations; week++ {
+			base := weekStart.AddDate(0, 0, week*7*interval)
+			for _, day := range days {
+				t := base.AddDate(0, 0, mondayIndex(day))
+				if t.Before(dtstart) {
+					continue
+				}
+				if afterUntil(t) || !emit(t) {
+					return
+				}
+			}
+		}
+	case Monthly:
+		for i := 0; i < maxRRuleIterations; i++ {
+			month := dtstart.Month() + time.Month(i*interval)
+			first := time.Date(dtstart.Year(), month, 1, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+			if afterUntil(first) {
+				return
+			}
+			t := time.Date(dtstart.Year(), month, dtstart.Day(), dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+			// несуществующие даты (31 число в коротком месяце) пропускаются, как в RFC 5545
+			if t.Month() != first.Month() {
+				continue
+			}
+			if afterUntil(t) || !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// дата без времени включает весь день
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, domainerrors.NewValidationError("Invalid UNTIL")
+}
+
+func mondayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func invalidRRule(raw, part string) error {
+	return domainerrors.NewValidationError("Invalid recurrence rule").
+		WithMeta("rule", raw).
+		WithMeta("part", part)
+}
@@ -0,0 +1,300 @@
+package valueobject
+
+import (
+	domainerrors "rttask/internal/domain/errors"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{raw: "RRULE:FREQ=WEEKLY;INTERVAL=2", want: "FREQ=WEEKLY;INTERVAL=2"},
+		{raw: "freq=weekly;byday=fr,mo,fr", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{raw: "FREQ=WEEKLY;BYDAY=SU,MO", want: "FREQ=WEEKLY;BYDAY=MO,SU"},
+		{raw: "FREQ=MONTHLY;COUNT=3", want: "FREQ=MONTHLY;COUNT=3"},
+		{raw: "FREQ=DAILY;INTERVAL=1;UNTIL=20250110T120000Z", want: "FREQ=DAILY;UNTIL=20250110T120000Z"},
+		{raw: "FREQ=DAILY;UNTIL=20250110", want: "FREQ=DAILY;UNTIL=20250110T235959Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			rule, err := ParseRRule(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error: %v", tt.raw, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("ParseRRule(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"RRULE:",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=366",
+		"FREQ=DAILY;INTERVAL=x",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYDAY=",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;UNTIL=2025-01-10",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250110",
+		"FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=DAILY;BYMONTH=1",
+		"FREQ=DAILY;",
+	} {
+		t.Run(raw, func(t *testing.T) {
+			_, err := ParseRRule(raw)
+			if domainErr := domainerrors.GetDomainError(err); domainErr == nil || domainErr.Type != domainerrors.ErrorTypeValidation {
+				t.Errorf("ParseRRule(%q) error = %v, want validation error", raw, err)
+			}
+		})
+	}
+}
+
+func TestRRuleBetween(t *testing.T) {
+	utc := func(day string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", day)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart string
+		before  string
+		want    []string
+	}{
+		{
+			name:    "daily",
+			rule:    "FREQ=DAILY",
+			dtstart: "2025-01-06 09:00",
+			before:  "2025-01-09 09:00",
+			want:    []string{"2025-01-06 09:00", "2025-01-07 09:00", "2025-01-08 09:00", "2025-01-09 09:00"},
+		},
+		{
+			name:    "daily interval",
+			rule:    "FREQ=DAILY;INTERVAL=3",
+			dtstart: "2025-01-06 09:00",
+			before:  "2025-01-16 00:00",
+			want:    []string{"2025-01-06 09:00", "2025-01-09 09:00", "2025-01-12 09:00", "2025-01-15 09:00"},
+		},
+		{
+			name:    "daily on weekdays",
+			rule:    "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			dtstart: "2025-01-09 09:00",
+			before:  "2025-01-14 09:00",
+			want:    []string{"2025-01-09 09:00", "2025-01-10 09:00", "2025-01-13 09:00", "2025-01-14 09:00"},
+		},
+		{
+			name:    "weekly on dtstart weekday",
+			rule:    "FREQ=WEEKLY",
+			dtstart: "2025-01-08 09:00",
+			before:  "2025-01-31 00:00",
+			want:    []string{"2025-01-08 09:00", "2025-01-15 09:00", "2025-01-22 09:00", "2025-01-29 09:00"},
+		},
+		{
+			// дни раньше dtstart в первой неделе пропускаются
+			name:    "weekly byday from midweek",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			dtstart: "2025-01-08 09:00",
+			before:  "2025-01-15 09:00",
+			want:    []string{"2025-01-08 09:00", "2025-01-10 09:00", "2025-01-13 09:00", "2025-01-15 09:00"},
+		},
+		{
+			name:    "weekly interval byday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU",
+			dtstart: "2025-01-07 09:00",
+			before:  "2025-02-04 09:00",
+			want:    []string{"2025-01-07 09:00", "2025-01-12 09:00", "2025-01-21 09:00", "2025-01-26 09:00", "2025-02-04 09:00"},
+		},
+		{
+			name:    "weekly across year boundary",
+			rule:    "FREQ=WEEKLY;BYDAY=MO",
+			dtstart: "2024-12-23 09:00",
+			before:  "2025-01-06 09:00",
+			want:    []string{"2024-12-23 09:00", "2024-12-30 09:00", "2025-01-06 09:00"},
+		},
+		{
+			name:    "count",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=3",
+			dtstart: "2025-01-06 09:00",
+			before:  "2025-12-31 00:00",
+			want:    []string{"2025-01-06 09:00", "2025-01-10 09:00", "2025-01-13 09:00"},
+		},
+		{
+			// COUNT считает только прошедшие через BYDAY даты
+			name:    "count with daily byday",
+			rule:    "FREQ=DAILY;BYDAY=SA;COUNT=2",
+			dtstart: "2025-01-06 09:00",
+			before:  "2025-12-31 00:00",
+			want:    []string{"2025-01-11 09:00", "2025-01-18 09:00"},
+		},
+		{
+			name:    "until inclusive",
+			rule:    "FREQ=DAILY;UNTIL=20250108T090000Z",
+			dtstart: "2025-01-06 09:00",
+			before:  "2025-12-31 00:00",
+			want:    []string{"2025-01-06 09:00", "2025-01-07 09:00", "2025-01-08 09:00"},
+		},
+		{
+			name:    "until date covers whole day",
+			rule:    "FREQ=DAILY;UNTIL=20250107",
+			dtstart: "2025-01-06 18:00",
+			before:  "2025-12-31 00:00",
+			want:    []string{"2025-01-06 18:00", "2025-01-07 18:00"},
+		},
+		{
+			name:    "monthly",
+			rule:    "FREQ=MONTHLY",
+			dtstart: "2025-01-15 09:00",
+			before:  "2025-04-15 09:00",
+			want:    []string{"2025-01-15 09:00", "2025-02-15 09:00", "2025-03-15 09:00", "2025-04-15 09:00"},
+		},
+		{
+			name:    "monthly skips short months",
+			rule:    "FREQ=MONTHLY",
+			dtstart: "2025-01-31 09:00",
+			before:  "2025-09-01 00:00",
+			want:    []string{"2025-01-31 09:00", "2025-03-31 09:00", "2025-05-31 09:00", "2025-07-31 09:00", "2025-08-31 09:00"},
+		},
+		{
+			name:    "monthly on 29th outside leap year",
+			rule:    "FREQ=MONTHLY;INTERVAL=12",
+			dtstart: "2024-02-29 09:00",
+			before:  "2029-01-01 00:00",
+			want:    []string{"2024-02-29 09:00", "2028-02-29 09:00"},
+		},
+		{
+			// пропущенные месяцы не расходуют COUNT
+			name:    "monthly count with skipped months",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: "2025-01-30 09:00",
+			before:  "2025-12-31 00:00",
+			want:    []string{"2025-01-30 09:00", "2025-03-30 09:00", "2025-04-30 09:00"},
+		},
+		{
+			name:    "monthly interval across year",
+			rule:    "FREQ=MONTHLY;INTERVAL=5",
+			dtstart: "2025-10-10 09:00",
+			before:  "2026-09-01 00:00",
+			want:    []string{"2025-10-10 09:00", "2026-03-10 09:00", "2026-08-10 09:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error: %v", tt.rule, err)
+			}
+			dtstart := utc(tt.dtstart)
+			// after на секунду раньше dtstart, чтобы в интервал попало первое повторение
+			got := rule.Between(dtstart, dtstart.Add(-time.Second), utc(tt.before))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Between = %v, want %v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if !got[i].Equal(utc(want)) {
+					t.Errorf("Between[%d] = %v, want %s", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRRuleBetweenWindow(t *testing.T) {
+	rule, err := ParseRRule("FREQ=DAILY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dtstart := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	// интервал (after, before]: повторение ровно в after не попадает, ровно в before попадает
+	got := rule.Between(dtstart, dtstart.AddDate(0, 0, 2), dtstart.AddDate(0, 0, 4))
+	want := []time.Time{dtstart.AddDate(0, 0, 3), dtstart.AddDate(0, 0, 4)}
+	if len(got) != len(want) || !got[0].Equal(want[0]) || !got[1].Equal(want[1]) {
+		t.Errorf("Between = %v, want %v", got, want)
+	}
+}
+
+func TestRRuleKeepsLocalTimeAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		before  time.Time
+		count   int
+	}{
+		// 30 марта 2025 часы переводятся вперед
+		{name: "daily spring forward", rule: "FREQ=DAILY", dtstart: time.Date(2025, 3, 28, 9, 0, 0, 0, berlin), before: time.Date(2025, 4, 2, 0, 0, 0, 0, berlin), count: 5},
+		// 26 октября 2025 часы переводятся назад
+		{name: "weekly fall back", rule: "FREQ=WEEKLY;BYDAY=SA,SU", dtstart: time.Date(2025, 10, 18, 9, 0, 0, 0, berlin), before: time.Date(2025, 11, 3, 0, 0, 0, 0, berlin), count: 6},
+		{name: "monthly across both", rule: "FREQ=MONTHLY", dtstart: time.Date(2025, 1, 15, 9, 0, 0, 0, berlin), before: time.Date(2025, 12, 31, 0, 0, 0, 0, berlin), count: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := rule.Between(tt.dtstart, tt.dtstart.Add(-time.Second), tt.before)
+			if len(got) != tt.count {
+				t.Fatalf("Between returned %d occurrences, want %d: %v", len(got), tt.count, got)
+			}
+			offsets := map[int]bool{}
+			for _, occurrence := range got {
+				local := occurrence.In(berlin)
+				if local.Hour() != 9 || local.Minute() != 0 {
+					t.Errorf("occurrence %v is not at 09:00 Berlin time", local)
+				}
+				_, offset := local.Zone()
+				offsets[offset] = true
+			}
+			// правило действительно пересекает переход, иначе тест ничего не проверяет
+			if len(offsets) != 2 {
+				t.Errorf("occurrences do not cross a DST change: %v", got)
+			}
+		})
+	}
+}
+
+func TestRRuleFinished(t *testing.T) {
+	dtstart := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule string
+		last time.Time
+		want bool
+	}{
+		{rule: "FREQ=DAILY", last: dtstart.AddDate(1, 0, 0), want: false},
+		{rule: "FREQ=DAILY;COUNT=3", last: dtstart.AddDate(0, 0, 1), want: false},
+		{rule: "FREQ=DAILY;COUNT=3", last: dtstart.AddDate(0, 0, 2), want: true},
+		{rule: "FREQ=WEEKLY;UNTIL=20250120T090000Z", last: dtstart.AddDate(0, 0, 7), want: false},
+		{rule: "FREQ=WEEKLY;UNTIL=20250120T090000Z", last: dtstart.AddDate(0, 0, 14), want: true},
+		{rule: "FREQ=MONTHLY;UNTIL=20250305", last: dtstart.AddDate(0, 1, 0), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rule.Finished(dtstart, tt.last); got != tt.want {
+				t.Errorf("Finished(%v) = %v, want %v", tt.last, got, tt.want)
+			}
+		})
+	}
+}
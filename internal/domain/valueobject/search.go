@@ -0,0 +1,9 @@
+package valueobject
+
+type SearchParams struct {
+	Query string
+	// ViewerID ищется только по компаниям, в которых состоит пользователь
+	ViewerID  uint
+	CompanyID uint // 0 по всем компаниям пользователя
+	Limit     int
+}
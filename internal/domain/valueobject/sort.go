@@ -0,0 +1,35 @@
+package valueobject
+
+import (
+	domainerrors "rttask/internal/domain/errors"
+	"slices"
+	"strings"
+)
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort разбирает строку вида "deadlineAt,-priority": минус перед полем означает сортировку по убыванию
+func ParseSort(raw string, allowed []string) ([]SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !slices.Contains(allowed, field.Field) {
+			return nil, domainerrors.NewValidationError("Invalid sort field").
+				WithMeta("field", field.Field).
+				WithMeta("allowed", allowed)
+		}
+		if slices.ContainsFunc(fields, func(f SortField) bool { return f.Field == field.Field }) {
+			return nil, domainerrors.NewValidationError("Duplicate sort field").WithMeta("field", field.Field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -0,0 +1,36 @@
+package valueobject
+
+import (
+	"rttask/internal/domain/model"
+	"time"
+)
+
+var TaskSortFields = []string{"createdAt", "updatedAt", "startAt", "deadlineAt", "priority", "status", "title"}
+
+// TaskFilter условия выборки задач. Пустые поля не ограничивают выборку
+type TaskFilter struct {
+	// ViewerID видны только задачи компаний, в которых состоит пользователь
+	ViewerID uint
+
+	CompanyIDs []uint
+	// ExecutorIDs любой из исполнителей задачи, не только основной
+	ExecutorIDs []uint
+	WatcherIDs  []uint
+	CreatorIDs  []uint
+	Statuses    []model.Status
+	Priorities  []uint
+	// ParentIDs подзадачи указанных задач
+	ParentIDs []uint
+
+	StartFrom    *time.Time
+	StartTo      *time.Time
+	DeadlineFrom *time.Time
+	DeadlineTo   *time.Time
+
+	// Overdue true только просроченные невыполненные задачи, false только непросроченные
+	Overdue *bool
+	Search  string
+
+	// Sort по умолчанию сначала новые
+	Sort []SortField
+}
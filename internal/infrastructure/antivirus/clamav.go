@@ -0,0 +1,114 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const clamdChunkSize = 64 * 1024
+
+// ErrStreamTooLarge файл больше StreamMaxLength clamd, повторная проверка не поможет
+var ErrStreamTooLarge = errors.New("clamd: stream size limit exceeded")
+
+// ClamAVScanner проверяет файлы через clamd по TCP командой INSTREAM
+type ClamAVScanner struct {
+	address string
+	timeout time.Duration
+}
+
+func NewClamAVScanner(address string, timeout time.Duration) FileScanner {
+	return &ClamAVScanner{
+		address: address,
+		timeout: timeout,
+	}
+}
+
+// Scan отправляет файл частями формата [длина uint32 big endian][данные], конец потока это часть нулевой длины.
+// clamd отвечает "stream: OK" или "stream: <сигнатура> FOUND"
+func (s *ClamAVScanner) Scan(ctx context.Context, file io.Reader) (*ScanResult, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return nil, fmt.Errorf("clamd connect: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("clamd set deadline: %w", err)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("clamd write command: %w", err)
+	}
+
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := file.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, replyOr(conn, fmt.Errorf("clamd write chunk: %w", err))
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, replyOr(conn, fmt.Errorf("clamd write chunk: %w", err))
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("read file: %w", readErr)
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, fmt.Errorf("clamd write end of stream: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("clamd read reply: %w", err)
+	}
+	return parseClamdReply(reply)
+}
+
+// replyOr clamd при превышении лимита отвечает и закрывает соединение, не дочитав поток.
+// Если ответ успел прийти, причина в нем, иначе writeErr
+func replyOr(conn net.Conn, writeErr error) error {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF || strings.TrimSpace(strings.TrimRight(reply, "\x00")) == "" {
+		return writeErr
+	}
+	if _, err := parseClamdReply(reply); err != nil {
+		return err
+	}
+	return writeErr
+}
+
+func parseClamdReply(reply string) (*ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return &ScanResult{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &ScanResult{Infected: true, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	case strings.Contains(reply, "size limit exceeded"):
+		return nil, ErrStreamTooLarge
+	default:
+		return nil, fmt.Errorf("clamd error: %s", reply)
+	}
+}
@@ -0,0 +1,201 @@
+package antivirus
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// received то, что fake clamd получил от клиента
+type received struct {
+	command    string
+	chunks     []int
+	data       []byte
+	terminated bool
+}
+
+// fakeClamd принимает одно соединение, разбирает INSTREAM и отвечает reply.
+// limit > 0 как StreamMaxLength: после limit байт ответ отправляется сразу, остаток потока вычитывается
+func fakeClamd(t *testing.T, reply string, limit int) (string, <-chan received) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	result := make(chan received, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var got received
+		defer func() { result <- got }()
+
+		command := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, command); err != nil {
+			return
+		}
+		got.command = string(command)
+
+		replied := false
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				got.terminated = true
+				break
+			}
+			chunk := make([]byte, n)
+			if _, err := io.ReadFull(conn, chunk); err != nil {
+				return
+			}
+			got.chunks = append(got.chunks, int(n))
+			got.data = append(got.data, chunk...)
+			if limit > 0 && len(got.data) > limit && !replied {
+				conn.Write([]byte(reply + "\x00"))
+				replied = true
+			}
+		}
+		if reply != "" && !replied {
+			conn.Write([]byte(reply + "\x00"))
+		}
+	}()
+	return listener.Addr().String(), result
+}
+
+func TestClamAVScannerStream(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 15000) // две полные части по 64 KB и остаток
+	address, result := fakeClamd(t, "stream: OK", 0)
+
+	scan, err := NewClamAVScanner(address, time.Second).Scan(context.Background(), bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if scan.Infected {
+		t.Fatalf("clean file reported infected: %+v", scan)
+	}
+
+	got := <-result
+	if got.command != "zINSTREAM\x00" {
+		t.Errorf("command = %q, want zINSTREAM with null terminator", got.command)
+	}
+	wantChunks := []int{clamdChunkSize, clamdChunkSize, len(content) - 2*clamdChunkSize}
+	if len(got.chunks) != len(wantChunks) {
+		t.Fatalf("chunks = %v, want %v", got.chunks, wantChunks)
+	}
+	for i := range wantChunks {
+		if got.chunks[i] != wantChunks[i] {
+			t.Fatalf("chunks = %v, want %v", got.chunks, wantChunks)
+		}
+	}
+	if !bytes.Equal(got.data, content) {
+		t.Errorf("clamd received %d bytes that differ from the file", len(got.data))
+	}
+	if !got.terminated {
+		t.Error("stream was not finished with a zero-length chunk")
+	}
+}
+
+func TestClamAVScannerEmptyFile(t *testing.T) {
+	address, result := fakeClamd(t, "stream: OK", 0)
+	if _, err := NewClamAVScanner(address, time.Second).Scan(context.Background(), strings.NewReader("")); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	got := <-result
+	if len(got.chunks) != 0 || !got.terminated {
+		t.Fatalf("empty file sent chunks %v, terminated %v, want only the terminator", got.chunks, got.terminated)
+	}
+}
+
+func TestClamAVScannerReplies(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		limit     int
+		want      *ScanResult
+		wantErr   error
+		errSubstr string
+	}{
+		{name: "clean", reply: "stream: OK", want: &ScanResult{}},
+		{name: "infected", reply: "stream: Win.Test.EICAR_HDB-1 FOUND", want: &ScanResult{Infected: true, Signature: "Win.Test.EICAR_HDB-1"}},
+		{name: "error", reply: "UNKNOWN COMMAND", errSubstr: "clamd error: UNKNOWN COMMAND"},
+		{name: "size limit", reply: "INSTREAM size limit exceeded. ERROR", limit: 1000, wantErr: ErrStreamTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, _ := fakeClamd(t, tt.reply, tt.limit)
+			content := bytes.Repeat([]byte("x"), 5000)
+
+			scan, err := NewClamAVScanner(address, time.Second).Scan(context.Background(), bytes.NewReader(content))
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Scan error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errSubstr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+					t.Fatalf("Scan error = %v, want %q", err, tt.errSubstr)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Scan: %v", err)
+				}
+				if *scan != *tt.want {
+					t.Fatalf("Scan = %+v, want %+v", scan, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClamAVScannerTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		// принимает данные, но не отвечает
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+
+	start := time.Now()
+	_, err = NewClamAVScanner(listener.Addr().String(), 200*time.Millisecond).Scan(context.Background(), strings.NewReader("data"))
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Scan error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Scan took %v, want about the 200ms timeout", elapsed)
+	}
+}
+
+func TestClamAVScannerConnectError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	if _, err := NewClamAVScanner(address, time.Second).Scan(context.Background(), strings.NewReader("data")); err == nil || !strings.Contains(err.Error(), "clamd connect") {
+		t.Fatalf("Scan error = %v, want connect error", err)
+	}
+}
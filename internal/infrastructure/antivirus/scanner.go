@@ -0,0 +1,37 @@
+package antivirus
+
+import (
+	"context"
+	"io"
+	"rttask/internal/config"
+	"time"
+)
+
+type ScanResult struct {
+	Infected  bool
+	Signature string
+	Skipped   bool // файл не проверялся, антивирус выключен
+}
+
+type FileScanner interface {
+	Scan(ctx context.Context, file io.Reader) (*ScanResult, error)
+}
+
+// NewScanner возвращает ClamAV сканер, если он включен в конфиге, иначе сканер-заглушку
+func NewScanner(cfg config.Antivirus) FileScanner {
+	if !cfg.Enabled {
+		return NewNoopScanner()
+	}
+	return NewClamAVScanner(cfg.Address, time.Duration(cfg.Timeout)*time.Second)
+}
+
+// NoopScanner пропускает все файлы без проверки
+type NoopScanner struct{}
+
+func NewNoopScanner() FileScanner {
+	return &NoopScanner{}
+}
+
+func (s *NoopScanner) Scan(ctx context.Context, file io.Reader) (*ScanResult, error) {
+	return &ScanResult{Skipped: true}, nil
+}
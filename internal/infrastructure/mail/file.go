@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer складывает письма .eml файлами в каталог. Для локальной разработки и тестов
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir string, from string) Mailer {
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	data, err := build(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("mail dir: %w", err)
+	}
+
+	random := make([]byte, 4)
+	rand.Read(random)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), hex.EncodeToString(random))
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0644); err != nil {
+		return fmt.Errorf("write mail: %w", err)
+	}
+	return nil
+}
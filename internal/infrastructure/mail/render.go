@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// TemplateData уже локализованные тексты письма, шаблоны отвечают только за разметку
+type TemplateData struct {
+	Title        string
+	Greeting     string
+	Intro        string
+	Items        []TemplateItem
+	ActionText   string
+	Footer       string
+	SettingsText string
+	SettingsURL  string
+}
+
+type TemplateItem struct {
+	Text      string
+	TaskTitle string
+	URL       string
+}
+
+// Renderer собирает текстовую и HTML версии письма из шаблонов templates/<name>.txt.tmpl и <name>.html.tmpl
+type Renderer struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+func MustNewRenderer() *Renderer {
+	return &Renderer{
+		html: htmltemplate.Must(htmltemplate.ParseFS(templatesFS, "templates/*.html.tmpl")),
+		text: texttemplate.Must(texttemplate.ParseFS(templatesFS, "templates/*.txt.tmpl")),
+	}
+}
+
+func (r *Renderer) Render(name string, data TemplateData) (text string, html string, err error) {
+	var textBuf, htmlBuf bytes.Buffer
+	if err := r.text.ExecuteTemplate(&textBuf, name+".txt.tmpl", data); err != nil {
+		return "", "", err
+	}
+	if err := r.html.ExecuteTemplate(&htmlBuf, name+".html.tmpl", data); err != nil {
+		return "", "", err
+	}
+	return textBuf.String(), htmlBuf.String(), nil
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"rttask/internal/config"
+	"strconv"
+)
+
+// SMTPMailer отправляет письма через SMTP сервер, STARTTLS используется, если сервер его поддерживает
+type SMTPMailer struct {
+	cfg  config.SMTP
+	from string
+}
+
+func NewSMTPMailer(cfg config.SMTP, from string) Mailer {
+	return &SMTPMailer{
+		cfg:  cfg,
+		from: from,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := build(m.from, msg)
+	if err != nil {
+		return err
+	}
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", m.from, err)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+
+	var auth smtp.Auth
+	if m.cfg.User != "" {
+		auth = smtp.PlainAuth("", m.cfg.User, m.cfg.Password, m.cfg.Host)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	if err := smtp.SendMail(addr, auth, sender.Address, []string{recipient.Address}, data); err != nil {
+		return fmt.Errorf("smtp send: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PgAssigneeRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgAssigneeRepository(db *gorm.DB, logger *zap.Logger) repository.AssigneeRepository {
+	return &PgAssigneeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgAssigneeRepository) Replace(ctx context.Context, taskID uint, assignees []*model.TaskAssignee) error {
+	r.logger.Info("start AssigneeRepository.Replace")
+	db := conn(ctx, r.db)
+	if err := db.Where("task_id = ?", taskID).Delete(&model.TaskAssignee{}).Error; err != nil {
+		return MapGormError(err, "task assignee")
+	}
+	if len(assignees) == 0 {
+		return nil
+	}
+
+	for _, assignee := range assignees {
+		assignee.ID = 0
+		assignee.TaskID = taskID
+	}
+	if err := db.Create(&assignees).Error; err != nil {
+		return MapGormError(err, "task assignee")
+	}
+	return nil
+}
+
+func (r *PgAssigneeRepository) SetAcknowledged(ctx context.Context, taskID uint, userID uint, at time.Time) error {
+	r.logger.Info("start AssigneeRepository.SetAcknowledged")
+	result := conn(ctx, r.db).Model(&model.TaskAssignee{}).
+		Where("task_id = ? AND user_id = ? AND role = ?", taskID, userID, model.ExecutorRole).
+		Update("acknowledged_at", at)
+	if result.Error != nil {
+		return MapGormError(result.Error, "task assignee")
+	}
+	if result.RowsAffected == 0 {
+		return MapGormError(gorm.ErrRecordNotFound, "task assignee")
+	}
+	return nil
+}
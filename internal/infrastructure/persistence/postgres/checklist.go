@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PgChecklistRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgChecklistRepository(db *gorm.DB, logger *zap.Logger) repository.ChecklistRepository {
+	return &PgChecklistRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgChecklistRepository) Create(ctx context.Context, item *model.ChecklistItem) (*model.ChecklistItem, error) {
+	r.logger.Info("start ChecklistRepository.Create")
+	err := conn(ctx, r.db).Create(item).Error
+	if err != nil {
+		return nil, MapGormError(err, "checklist item")
+	}
+	return item, nil
+}
+
+func (r *PgChecklistRepository) GetByID(ctx context.Context, taskID uint, id uint) (*model.ChecklistItem, error) {
+	r.logger.Info("start ChecklistRepository.GetByID")
+	var item model.ChecklistItem
+	err := conn(ctx, r.db).First(&item, "id = ? AND task_id = ?", id, taskID).Error
+	if err != nil {
+		return nil, MapGormError(err, "checklist item")
+	}
+	return &item, nil
+}
+
+func (r *PgChecklistRepository) Update(ctx context.Context, item *model.ChecklistItem) error {
+	r.logger.Info("start ChecklistRepository.Update")
+	err := conn(ctx, r.db).Save(item).Error
+	if err != nil {
+		return MapGormError(err, "checklist item")
+	}
+	return nil
+}
+
+func (r *PgChecklistRepository) Delete(ctx context.Context, item *model.ChecklistItem) error {
+	r.logger.Info("start ChecklistRepository.Delete")
+	err := conn(ctx, r.db).Delete(item).Error
+	if err != nil {
+		return MapGormError(err, "checklist item")
+	}
+	return nil
+}
+
+func (r *PgChecklistRepository) NextPosition(ctx context.Context, taskID uint) (int, error) {
+	r.logger.Info("start ChecklistRepository.NextPosition")
+	var position int
+	err := conn(ctx, r.db).Model(&model.ChecklistItem{}).
+		Select("COALESCE(MAX(position), 0) + 1").
+		Where("task_id = ?", taskID).
+		Scan(&position).Error
+	if err != nil {
+		return 0, MapGormError(err, "checklist item")
+	}
+	return position, nil
+}
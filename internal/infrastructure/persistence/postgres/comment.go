@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/valueobject"
+	"slices"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgCommentRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgCommentRepository(db *gorm.DB, logger *zap.Logger) repository.CommentRepository {
+	return &PgCommentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgCommentRepository) Create(ctx context.Context, comment *model.Comment) (*model.Comment, error) {
+	r.logger.Info("start CommentRepository.Create")
+	err := conn(ctx, r.db).Omit(clause.Associations).Create(comment).Error
+	if err != nil {
+		return nil, MapGormError(err, "comment")
+	}
+	return comment, nil
+}
+
+func (r *PgCommentRepository) GetByID(ctx context.Context, id uint) (*model.Comment, error) {
+	r.logger.Info("start CommentRepository.GetByID")
+	var comment model.Comment
+	err := conn(ctx, r.db).Preload("Task").First(&comment, "id = ?", id).Error
+	if err != nil {
+		return nil, MapGormError(err, "comment")
+	}
+	return &comment, nil
+}
+
+func (r *PgCommentRepository) ListByTask(ctx context.Context, taskID uint, params valueobject.PaginationParams) ([]*model.Comment, int64, error) {
+	r.logger.Info("start CommentRepository.ListByTask")
+	var comments []*model.Comment
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Comment{}).Where("task_id = ?", taskID).Count(&count).Error
+	if err != nil {
+		return nil, 0, MapGormError(err, "comment")
+	}
+
+	if params.CursorMode {
+		paged, backward := paginateByCursor(conn(ctx, r.db).Where("task_id = ?", taskID), params, false)
+		if err := paged.Find(&comments).Error; err != nil {
+			return nil, 0, MapGormError(err, "comment")
+		}
+		if backward {
+			slices.Reverse(comments)
+		}
+		return comments, count, nil
+	}
+
+	err = conn(ctx, r.db).
+		Where("task_id = ?", taskID).
+		Order("created_at, id").
+		Offset(params.Offset).
+		Limit(params.Limit).
+		Find(&comments).Error
+	if err != nil {
+		return nil, 0, MapGormError(err, "comment")
+	}
+	return comments, count, nil
+}
+
+func (r *PgCommentRepository) UpdateContent(ctx context.Context, id uint, content string) error {
+	r.logger.Info("start CommentRepository.UpdateContent")
+	err := conn(ctx, r.db).Model(&model.Comment{}).Where("id = ?", id).Update("content", content).Error
+	if err != nil {
+		return MapGormError(err, "comment")
+	}
+	return nil
+}
+
+func (r *PgCommentRepository) Delete(ctx context.Context, comment *model.Comment) error {
+	r.logger.Info("start CommentRepository.Delete")
+	err := conn(ctx, r.db).Delete(comment).Error
+	if err != nil {
+		return MapGormError(err, "comment")
+	}
+	return nil
+}
@@ -24,7 +24,7 @@ func NewPgCompanyRepository(db *gorm.DB, logger *zap.Logger) repository.CompanyR
 
 func (r *PgCompanyRepository) Create(ctx context.Context, company *model.Company) (*model.Company, error) {
 	r.logger.Info("start CompanyRepository.Create")
-	err := r.db.WithContext(ctx).Create(company).Error
+	err := conn(ctx, r.db).Create(company).Error
 	if err != nil {
 		return nil, MapGormError(err, "company")
 	}
@@ -33,7 +33,7 @@ func (r *PgCompanyRepository) Create(ctx context.Context, company *model.Company
 
 func (r *PgCompanyRepository) GetByName(ctx context.Context, name string) (*model.Company, error) {
 	var company *model.Company
-	err := r.db.WithContext(ctx).First(&company, "name = ?", name).Error
+	err := conn(ctx, r.db).First(&company, "name = ?", name).Error
 	if err != nil {
 		return nil, MapGormError(err, "company")
 	}
@@ -43,7 +43,7 @@ func (r *PgCompanyRepository) GetAll(ctx context.Context, params valueobject.Pag
 	r.logger.Info("start CompanyRepository.GetAll")
 	var companies []*model.Company
 	var count int64
-	err := r.db.WithContext(ctx).Model(&model.Company{}).Offset(params.Offset).Limit(params.Limit).Find(&companies).Count(&count).Error
+	err := conn(ctx, r.db).Model(&model.Company{}).Offset(params.Offset).Limit(params.Limit).Find(&companies).Count(&count).Error
 	if err != nil {
 		return nil, 0, MapGormError(err, "company")
 	}
@@ -53,7 +53,7 @@ func (r *PgCompanyRepository) GetAll(ctx context.Context, params valueobject.Pag
 func (r *PgCompanyRepository) GetByID(ctx context.Context, id uint) (*model.Company, error) {
 	r.logger.Info("start CompanyRepository.GetByID")
 	var company model.Company
-	err := r.db.WithContext(ctx).First(&company, "id = ?", id).Error
+	err := conn(ctx, r.db).First(&company, "id = ?", id).Error
 	if err != nil {
 		return nil, MapGormError(err, "company")
 	}
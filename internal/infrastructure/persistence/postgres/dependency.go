@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgDependencyRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgDependencyRepository(db *gorm.DB, logger *zap.Logger) repository.DependencyRepository {
+	return &PgDependencyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgDependencyRepository) Create(ctx context.Context, dependency *model.TaskDependency) error {
+	r.logger.Info("start DependencyRepository.Create")
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(dependency).Error
+	if err != nil {
+		return MapGormError(err, "task dependency")
+	}
+	return nil
+}
+
+func (r *PgDependencyRepository) Delete(ctx context.Context, taskID uint, blockerID uint) error {
+	r.logger.Info("start DependencyRepository.Delete")
+	result := conn(ctx, r.db).Where("task_id = ? AND blocker_id = ?", taskID, blockerID).Delete(&model.TaskDependency{})
+	if result.Error != nil {
+		return MapGormError(result.Error, "task dependency")
+	}
+	if result.RowsAffected == 0 {
+		return MapGormError(gorm.ErrRecordNotFound, "task dependency")
+	}
+	return nil
+}
+
+func (r *PgDependencyRepository) LockGraph(ctx context.Context, companyID uint) error {
+	r.logger.Info("start DependencyRepository.LockGraph")
+	err := conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(hashtext('task_dependencies'), ?)", companyID).Error
+	if err != nil {
+		return MapGormError(err, "task dependency")
+	}
+	return nil
+}
+
+// blockersChainQuery UNION убирает повторы, поэтому рекурсия завершается даже на цикле
+const blockersChainQuery = `
+WITH RECURSIVE chain AS (
+	SELECT blocker_id FROM task_dependencies WHERE task_id = @task
+	UNION
+	SELECT d.blocker_id FROM task_dependencies d JOIN chain c ON d.task_id = c.blocker_id
+)
+SELECT EXISTS (SELECT 1 FROM chain WHERE blocker_id = @blocker)`
+
+func (r *PgDependencyRepository) IsBlockedBy(ctx context.Context, taskID uint, blockerID uint) (bool, error) {
+	r.logger.Info("start DependencyRepository.IsBlockedBy")
+	var blocked bool
+	err := conn(ctx, r.db).Raw(blockersChainQuery, map[string]any{
+		"task":    taskID,
+		"blocker": blockerID,
+	}).Scan(&blocked).Error
+	if err != nil {
+		return false, MapGormError(err, "task dependency")
+	}
+	return blocked, nil
+}
+
+type dependencyLinkRow struct {
+	OwnerID    uint
+	ID         uint
+	Title      string
+	Status     model.Status
+	ExecutorID uint
+}
+
+func (r *PgDependencyRepository) GetLinks(ctx context.Context, taskIDs []uint) (map[uint][]model.TaskLink, map[uint][]model.TaskLink, error) {
+	r.logger.Info("start DependencyRepository.GetLinks")
+	blockedBy := make(map[uint][]model.TaskLink)
+	blocks := make(map[uint][]model.TaskLink)
+	if len(taskIDs) == 0 {
+		return blockedBy, blocks, nil
+	}
+
+	var rows []dependencyLinkRow
+	err := r.links(ctx, "d.task_id", "d.blocker_id", taskIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, nil, MapGormError(err, "task dependency")
+	}
+	for _, row := range rows {
+		blockedBy[row.OwnerID] = append(blockedBy[row.OwnerID], row.link())
+	}
+
+	rows = nil
+	err = r.links(ctx, "d.blocker_id", "d.task_id", taskIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, nil, MapGormError(err, "task dependency")
+	}
+	for _, row := range rows {
+		blocks[row.OwnerID] = append(blocks[row.OwnerID], row.link())
+	}
+	return blockedBy, blocks, nil
+}
+
+func (r *PgDependencyRepository) GetOpenBlockers(ctx context.Context, taskID uint) ([]model.TaskLink, error) {
+	r.logger.Info("start DependencyRepository.GetOpenBlockers")
+	var rows []dependencyLinkRow
+	err := r.links(ctx, "d.task_id", "d.blocker_id", []uint{taskID}).
+		Where("t.status <> ?", model.CompletedStatus).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, MapGormError(err, "task dependency")
+	}
+
+	links := make([]model.TaskLink, 0, len(rows))
+	for _, row := range rows {
+		links = append(links, row.link())
+	}
+	return links, nil
+}
+
+func (r *PgDependencyRepository) GetDependents(ctx context.Context, blockerID uint) ([]*model.Task, error) {
+	r.logger.Info("start DependencyRepository.GetDependents")
+	var tasks []*model.Task
+	err := conn(ctx, r.db).
+		Scopes(withDetails).
+		Where("id IN (?)", r.db.Model(&model.TaskDependency{}).Select("task_id").Where("blocker_id = ?", blockerID)).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, MapGormError(err, "task")
+	}
+	return tasks, nil
+}
+
+// links связанные задачи: ownerColumn задача, для которой собираются связи, linkColumn задача на другом конце
+func (r *PgDependencyRepository) links(ctx context.Context, ownerColumn, linkColumn string, taskIDs []uint) *gorm.DB {
+	return conn(ctx, r.db).
+		Table("task_dependencies d").
+		Select(ownerColumn+" AS owner_id, t.id, t.title, t.status, t.executor_id").
+		Joins("JOIN tasks t ON t.id = "+linkColumn+" AND t.deleted_at IS NULL").
+		Where(ownerColumn+" IN ?", taskIDs).
+		Order("t.id")
+}
+
+func (row dependencyLinkRow) link() model.TaskLink {
+	return model.TaskLink{ID: row.ID, Title: row.Title, Status: row.Status, ExecutorID: row.ExecutorID}
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgEmailRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgEmailRepository(db *gorm.DB, logger *zap.Logger) repository.EmailRepository {
+	return &PgEmailRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgEmailRepository) GetSettings(ctx context.Context, userID uint) (*model.EmailSettings, error) {
+	r.logger.Info("start EmailRepository.GetSettings")
+	var settings model.EmailSettings
+	err := conn(ctx, r.db).Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, MapGormError(err, "email settings")
+	}
+	return &settings, nil
+}
+
+func (r *PgEmailRepository) SaveSettings(ctx context.Context, settings *model.EmailSettings) error {
+	r.logger.Info("start EmailRepository.SaveSettings")
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(settings).Error
+	if err != nil {
+		return MapGormError(err, "email settings")
+	}
+	return nil
+}
+
+func (r *PgEmailRepository) AddDigestItem(ctx context.Context, item *model.EmailDigestItem) error {
+	r.logger.Info("start EmailRepository.AddDigestItem")
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error
+	if err != nil {
+		return MapGormError(err, "email digest item")
+	}
+	return nil
+}
+
+func (r *PgEmailRepository) GetDigestUserIDs(ctx context.Context) ([]uint, error) {
+	r.logger.Info("start EmailRepository.GetDigestUserIDs")
+	var ids []uint
+	err := conn(ctx, r.db).Model(&model.EmailDigestItem{}).
+		Distinct("user_id").
+		Order("user_id").
+		Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, MapGormError(err, "email digest item")
+	}
+	return ids, nil
+}
+
+func (r *PgEmailRepository) LockDigest(ctx context.Context, userID uint) ([]*model.EmailDigestItem, error) {
+	r.logger.Info("start EmailRepository.LockDigest")
+	var items []*model.EmailDigestItem
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Preload("Notification").
+		Where("user_id = ?", userID).
+		Order("created_at, id").
+		Find(&items).Error
+	if err != nil {
+		return nil, MapGormError(err, "email digest item")
+	}
+	return items, nil
+}
+
+func (r *PgEmailRepository) DeleteDigestItems(ctx context.Context, ids []uint) error {
+	r.logger.Info("start EmailRepository.DeleteDigestItems")
+	if len(ids) == 0 {
+		return nil
+	}
+	err := conn(ctx, r.db).Where("id IN ?", ids).Delete(&model.EmailDigestItem{}).Error
+	if err != nil {
+		return MapGormError(err, "email digest item")
+	}
+	return nil
+}
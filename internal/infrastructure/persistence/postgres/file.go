@@ -0,0 +1,270 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// removeFileExpr убирает файл с указанным id из jsonb массива files
+const removeFileExpr = `COALESCE((SELECT jsonb_agg(f) FROM jsonb_array_elements(files) AS f WHERE f->>'id' <> ?), '[]'::jsonb)`
+
+// fileSizeExpr размер файла из jsonb вместе с уменьшенными копиями. original это сам файл, он уже учтен в size
+func fileSizeExpr(file string) string {
+	return fmt.Sprintf(`(%[1]s->>'size')::bigint + COALESCE((SELECT SUM((v.value->>'size')::bigint) FROM jsonb_each(COALESCE(%[1]s->'variants', '{}'::jsonb)) AS v WHERE v.key <> 'original'), 0)`, file)
+}
+
+var companyUsageQuery = fmt.Sprintf(`
+SELECT entity_type, uploader_id, SUM(size)::bigint AS bytes FROM (
+	SELECT 'task' AS entity_type, (f->>'uploaderId')::bigint AS uploader_id, %[1]s AS size
+	FROM tasks t, jsonb_array_elements(t.files) AS f
+	WHERE t.company_id = @company AND t.deleted_at IS NULL
+	UNION ALL
+	SELECT 'comment', (f->>'uploaderId')::bigint, %[1]s
+	FROM comments c JOIN tasks t ON t.id = c.task_id, jsonb_array_elements(c.files) AS f
+	WHERE t.company_id = @company AND c.deleted_at IS NULL AND t.deleted_at IS NULL
+	UNION ALL
+	SELECT 'template', (f->>'uploaderId')::bigint, %[1]s
+	FROM task_templates tt, jsonb_array_elements(tt.files) AS f
+	WHERE tt.company_id = @company AND tt.deleted_at IS NULL
+	UNION ALL
+	SELECT 'company', (c.avatar->>'uploaderId')::bigint, %[2]s
+	FROM companies c
+	WHERE c.id = @company AND c.avatar IS NOT NULL AND c.deleted_at IS NULL
+) AS usage
+GROUP BY entity_type, uploader_id`, fileSizeExpr("f"), fileSizeExpr("c.avatar"))
+
+// userUsageQuery аватар при регистрации загружается до создания пользователя, поэтому учитывается по users.id.
+// Незавершенная загрузка занимает заявленный размер сразу, иначе параллельно созданные загрузки не видят друг друга
+var userUsageQuery = fmt.Sprintf(`
+SELECT COALESCE(SUM(size), 0)::bigint FROM (
+	SELECT %[1]s AS size
+	FROM tasks t, jsonb_array_elements(t.files) AS f
+	WHERE t.deleted_at IS NULL AND (f->>'uploaderId')::bigint = @user
+	UNION ALL
+	SELECT %[1]s
+	FROM comments c, jsonb_array_elements(c.files) AS f
+	WHERE c.deleted_at IS NULL AND (f->>'uploaderId')::bigint = @user
+	UNION ALL
+	SELECT %[1]s
+	FROM task_templates tt, jsonb_array_elements(tt.files) AS f
+	WHERE tt.deleted_at IS NULL AND (f->>'uploaderId')::bigint = @user
+	UNION ALL
+	SELECT %[2]s
+	FROM companies c
+	WHERE c.deleted_at IS NULL AND (c.avatar->>'uploaderId')::bigint = @user
+	UNION ALL
+	SELECT %[3]s
+	FROM users u
+	WHERE u.id = @user AND u.avatar IS NOT NULL AND u.deleted_at IS NULL
+	UNION ALL
+	SELECT length
+	FROM uploads
+	WHERE uploader_id = @user AND status IN ('pending', 'completed') AND expires_at > now()
+) AS usage`, fileSizeExpr("f"), fileSizeExpr("c.avatar"), fileSizeExpr("u.avatar"))
+
+// referencedFilesQuery все jsonb объекты с полем path: файлы сущностей, собранные
+// и еще не прикрепленные загрузки и части незавершенных загрузок
+const referencedFilesQuery = `
+SELECT f FROM tasks t, jsonb_array_elements(t.files) AS f WHERE t.deleted_at IS NULL
+UNION ALL
+SELECT f FROM comments c, jsonb_array_elements(c.files) AS f WHERE c.deleted_at IS NULL
+UNION ALL
+SELECT f FROM task_templates tt, jsonb_array_elements(tt.files) AS f WHERE tt.deleted_at IS NULL
+UNION ALL
+SELECT avatar FROM companies WHERE avatar IS NOT NULL AND deleted_at IS NULL
+UNION ALL
+SELECT avatar FROM users WHERE avatar IS NOT NULL AND deleted_at IS NULL
+UNION ALL
+SELECT file FROM uploads WHERE file IS NOT NULL AND status = 'completed' AND expires_at > now()
+UNION ALL
+SELECT p FROM uploads u, jsonb_array_elements(u.parts) AS p WHERE u.status = 'pending' AND u.expires_at > now()`
+
+type PgFileRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgFileRepository(db *gorm.DB, logger *zap.Logger) repository.FileRepository {
+	return &PgFileRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetRefByID ищет сущность, которая ссылается на файл. Файлы хранятся только в jsonb колонках
+func (r *PgFileRepository) GetRefByID(ctx context.Context, fileID string) (*model.FileRef, error) {
+	r.logger.Info("start FileRepository.GetRefByID")
+
+	filter, err := json.Marshal([]map[string]string{{"id": fileID}})
+	if err != nil {
+		return nil, domainerrors.NewInternalError("failed to build file filter", err)
+	}
+
+	var task model.Task
+	err = conn(ctx, r.db).Where("files @> ?::jsonb", string(filter)).First(&task).Error
+	if err == nil {
+		return &model.FileRef{File: findFile(task.Files, fileID), OwnerType: model.FileOwnerTask, OwnerID: task.ID, CompanyID: task.CompanyID}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, MapGormError(err, "file")
+	}
+
+	var comment model.Comment
+	err = conn(ctx, r.db).Preload("Task").Where("files @> ?::jsonb", string(filter)).First(&comment).Error
+	if err == nil {
+		return &model.FileRef{File: findFile(comment.Files, fileID), OwnerType: model.FileOwnerComment, OwnerID: comment.ID, CompanyID: comment.Task.CompanyID}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, MapGormError(err, "file")
+	}
+
+	var template model.TaskTemplate
+	err = conn(ctx, r.db).Where("files @> ?::jsonb", string(filter)).First(&template).Error
+	if err == nil {
+		return &model.FileRef{File: findFile(template.Files, fileID), OwnerType: model.FileOwnerTemplate, OwnerID: template.ID, CompanyID: template.CompanyID}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, MapGormError(err, "file")
+	}
+
+	var company model.Company
+	err = conn(ctx, r.db).Where("avatar->>'id' = ?", fileID).First(&company).Error
+	if err == nil {
+		return &model.FileRef{File: company.Avatar, OwnerType: model.FileOwnerCompany, OwnerID: company.ID, CompanyID: company.ID}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, MapGormError(err, "file")
+	}
+
+	var user model.User
+	err = conn(ctx, r.db).Where("avatar->>'id' = ?", fileID).First(&user).Error
+	if err == nil {
+		return &model.FileRef{File: user.Avatar, OwnerType: model.FileOwnerUser, OwnerID: user.ID}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, MapGormError(err, "file")
+	}
+
+	return nil, domainerrors.NewNotFoundError("file", fileID)
+}
+
+// Detach убирает ссылку на файл из сущности-владельца
+func (r *PgFileRepository) Detach(ctx context.Context, ref *model.FileRef) error {
+	r.logger.Info("start FileRepository.Detach")
+
+	var err error
+	switch ref.OwnerType {
+	case model.FileOwnerTask:
+		err = conn(ctx, r.db).Model(&model.Task{}).Where("id = ?", ref.OwnerID).Update("files", gorm.Expr(removeFileExpr, ref.File.ID)).Error
+	case model.FileOwnerComment:
+		err = conn(ctx, r.db).Model(&model.Comment{}).Where("id = ?", ref.OwnerID).Update("files", gorm.Expr(removeFileExpr, ref.File.ID)).Error
+	case model.FileOwnerTemplate:
+		err = conn(ctx, r.db).Model(&model.TaskTemplate{}).Where("id = ?", ref.OwnerID).Update("files", gorm.Expr(removeFileExpr, ref.File.ID)).Error
+	case model.FileOwnerCompany:
+		err = conn(ctx, r.db).Model(&model.Company{}).Where("id = ?", ref.OwnerID).Update("avatar", gorm.Expr("NULL")).Error
+	case model.FileOwnerUser:
+		err = conn(ctx, r.db).Model(&model.User{}).Where("id = ?", ref.OwnerID).Update("avatar", gorm.Expr("NULL")).Error
+	default:
+		return domainerrors.NewValidationError("unknown file owner")
+	}
+	if err != nil {
+		return MapGormError(err, "file")
+	}
+	return nil
+}
+
+func (r *PgFileRepository) GetCompanyUsage(ctx context.Context, companyID uint) (*model.StorageUsage, error) {
+	r.logger.Info("start FileRepository.GetCompanyUsage")
+
+	var rows []struct {
+		EntityType string
+		UploaderID uint
+		Bytes      int64
+	}
+	err := conn(ctx, r.db).Raw(companyUsageQuery, map[string]any{"company": companyID}).Scan(&rows).Error
+	if err != nil {
+		return nil, MapGormError(err, "file")
+	}
+
+	usage := model.NewStorageUsage(companyID)
+	for _, row := range rows {
+		usage.Add(model.FileOwnerType(row.EntityType), row.UploaderID, row.Bytes)
+	}
+	return usage, nil
+}
+
+func (r *PgFileRepository) GetUserUsage(ctx context.Context, userID uint) (int64, error) {
+	r.logger.Info("start FileRepository.GetUserUsage")
+
+	var bytes int64
+	err := conn(ctx, r.db).Raw(userUsageQuery, map[string]any{"user": userID}).Scan(&bytes).Error
+	if err != nil {
+		return 0, MapGormError(err, "file")
+	}
+	return bytes, nil
+}
+
+// LockQuota компания блокируется раньше пользователя, чтобы встречные транзакции не ждали друг друга
+func (r *PgFileRepository) LockQuota(ctx context.Context, companyID uint, userID uint) error {
+	r.logger.Info("start FileRepository.LockQuota")
+	if companyID != 0 {
+		if err := conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(hashtext('company_storage_quota'), ?)", companyID).Error; err != nil {
+			return MapGormError(err, "file")
+		}
+	}
+	if userID != 0 {
+		if err := conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(hashtext('user_storage_quota'), ?)", userID).Error; err != nil {
+			return MapGormError(err, "file")
+		}
+	}
+	return nil
+}
+
+func (r *PgFileRepository) GetReferencedPaths(ctx context.Context) (map[string]struct{}, error) {
+	r.logger.Info("start FileRepository.GetReferencedPaths")
+
+	rows, err := conn(ctx, r.db).Raw(referencedFilesQuery).Rows()
+	if err != nil {
+		return nil, MapGormError(err, "file")
+	}
+	defer rows.Close()
+
+	paths := make(map[string]struct{})
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, MapGormError(err, "file")
+		}
+		var f model.File
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, domainerrors.NewInternalError("failed to decode file", err)
+		}
+		if f.Path == "" {
+			continue
+		}
+		for _, path := range f.AllPaths() {
+			paths[path] = struct{}{}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, MapGormError(err, "file")
+	}
+	return paths, nil
+}
+
+func findFile(files []*model.File, fileID string) *model.File {
+	for _, f := range files {
+		if f != nil && f.ID == fileID {
+			return f
+		}
+	}
+	return nil
+}
@@ -24,7 +24,7 @@ func NewPgInviteRepository(db *gorm.DB, logger *zap.Logger) repository.InviteRep
 
 func (r *PgInviteRepository) Create(ctx context.Context, invite *model.InviteLink) (*model.InviteLink, error) {
 	r.logger.Info("start inviteRepository.Create")
-	err := r.db.WithContext(ctx).Create(invite).Error
+	err := conn(ctx, r.db).Create(invite).Error
 	if err != nil {
 		r.logger.Error("Create invite link", zap.Error(err), zap.Any("invite", invite))
 		return nil, err
@@ -35,7 +35,7 @@ func (r *PgInviteRepository) Create(ctx context.Context, invite *model.InviteLin
 func (r *PgInviteRepository) GetByToken(ctx context.Context, token string) (*model.InviteLink, error) {
 	r.logger.Info("start inviteRepository.GetByToken")
 	var invite *model.InviteLink
-	err := r.db.WithContext(ctx).Model(&model.InviteLink{}).Preload("Roles").Where("token = ?", token).First(&invite).Error
+	err := conn(ctx, r.db).Model(&model.InviteLink{}).Preload("Roles").Where("token = ?", token).First(&invite).Error
 	if err != nil {
 		return nil, MapGormError(err, "invite")
 	}
@@ -44,7 +44,7 @@ func (r *PgInviteRepository) GetByToken(ctx context.Context, token string) (*mod
 
 func (r *PgInviteRepository) GetAll(ctx context.Context, userID uint, params valueobject.PaginationParams) ([]*model.InviteLink, error) {
 	var invites []*model.InviteLink
-	err := r.db.WithContext(ctx).
+	err := conn(ctx, r.db).
 		Preload("User").
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
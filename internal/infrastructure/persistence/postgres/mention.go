@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgMentionRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgMentionRepository(db *gorm.DB, logger *zap.Logger) repository.MentionRepository {
+	return &PgMentionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgMentionRepository) Replace(ctx context.Context, source model.MentionSource, sourceID uint, mentions []*model.Mention) error {
+	r.logger.Info("start MentionRepository.Replace")
+	if err := r.DeleteBySource(ctx, source, sourceID); err != nil {
+		return err
+	}
+	if len(mentions) == 0 {
+		return nil
+	}
+	for _, mention := range mentions {
+		mention.SourceType = source
+		mention.SourceID = sourceID
+	}
+	// пользователи упоминаний уже существуют, сохранять их повторно не нужно
+	if err := conn(ctx, r.db).Omit(clause.Associations).Create(&mentions).Error; err != nil {
+		return MapGormError(err, "mention")
+	}
+	return nil
+}
+
+func (r *PgMentionRepository) ListBySources(ctx context.Context, source model.MentionSource, sourceIDs []uint) (map[uint][]*model.Mention, error) {
+	r.logger.Info("start MentionRepository.ListBySources")
+	result := make(map[uint][]*model.Mention, len(sourceIDs))
+	if len(sourceIDs) == 0 {
+		return result, nil
+	}
+	var mentions []*model.Mention
+	err := conn(ctx, r.db).
+		Preload("User").
+		Where("source_type = ? AND source_id IN ?", source, sourceIDs).
+		Order("source_id, \"offset\"").
+		Find(&mentions).Error
+	if err != nil {
+		return nil, MapGormError(err, "mention")
+	}
+	for _, mention := range mentions {
+		result[mention.SourceID] = append(result[mention.SourceID], mention)
+	}
+	return result, nil
+}
+
+func (r *PgMentionRepository) DeleteBySource(ctx context.Context, source model.MentionSource, sourceID uint) error {
+	r.logger.Info("start MentionRepository.DeleteBySource")
+	err := conn(ctx, r.db).
+		Where("source_type = ? AND source_id = ?", source, sourceID).
+		Delete(&model.Mention{}).Error
+	if err != nil {
+		return MapGormError(err, "mention")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/valueobject"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PgNotificationRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgNotificationRepository(db *gorm.DB, logger *zap.Logger) repository.NotificationRepository {
+	return &PgNotificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgNotificationRepository) Create(ctx context.Context, notifications []*model.Notification) error {
+	r.logger.Info("start NotificationRepository.Create")
+	if len(notifications) == 0 {
+		return nil
+	}
+	err := conn(ctx, r.db).Create(&notifications).Error
+	if err != nil {
+		return MapGormError(err, "notification")
+	}
+	return nil
+}
+
+func (r *PgNotificationRepository) List(ctx context.Context, recipientID uint, unreadOnly bool, params valueobject.PaginationParams) ([]*model.Notification, int64, error) {
+	r.logger.Info("start NotificationRepository.List")
+	query := conn(ctx, r.db).Model(&model.Notification{}).Where("recipient_id = ?", recipientID)
+	if unreadOnly {
+		query = query.Where("NOT read")
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, MapGormError(err, "notification")
+	}
+
+	var notifications []*model.Notification
+	err := query.
+		Order("created_at DESC, id DESC").
+		Offset(params.Offset).
+		Limit(params.Limit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, 0, MapGormError(err, "notification")
+	}
+	return notifications, count, nil
+}
+
+func (r *PgNotificationRepository) CountUnread(ctx context.Context, recipientID uint) (int64, error) {
+	r.logger.Info("start NotificationRepository.CountUnread")
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Notification{}).
+		Where("recipient_id = ? AND NOT read", recipientID).
+		Count(&count).Error
+	if err != nil {
+		return 0, MapGormError(err, "notification")
+	}
+	return count, nil
+}
+
+func (r *PgNotificationRepository) MarkRead(ctx context.Context, recipientID uint, ids []uint) (int64, error) {
+	r.logger.Info("start NotificationRepository.MarkRead")
+	query := conn(ctx, r.db).Model(&model.Notification{}).Where("recipient_id = ? AND NOT read", recipientID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Updates(map[string]any{"read": true, "read_at": time.Now()})
+	if result.Error != nil {
+		return 0, MapGormError(result.Error, "notification")
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"rttask/internal/domain/valueobject"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// paginateByCursor keyset пагинация по (created_at, id). Выбирает на одну запись больше Limit,
+// чтобы valueobject.NewCursorPage понял, есть ли следующая страница. При движении назад
+// выборка идет в обратном порядке, вызывающий разворачивает результат, если вернулось true
+func paginateByCursor(query *gorm.DB, params valueobject.PaginationParams, desc bool) (*gorm.DB, bool) {
+	backward := params.Cursor != nil && params.Cursor.Direction == valueobject.CursorPrev
+	scanDesc := desc != backward
+
+	if params.Cursor != nil {
+		if scanDesc {
+			query = query.Where("(created_at, id) < (?, ?)", params.Cursor.CreatedAt, params.Cursor.ID)
+		} else {
+			query = query.Where("(created_at, id) > (?, ?)", params.Cursor.CreatedAt, params.Cursor.ID)
+		}
+	}
+
+	query = query.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: "created_at"}, Desc: scanDesc},
+		{Column: clause.Column{Name: "id"}, Desc: scanDesc},
+	}}).Limit(params.Limit + 1)
+	return query, backward
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgRecurrenceRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgRecurrenceRepository(db *gorm.DB, logger *zap.Logger) repository.RecurrenceRepository {
+	return &PgRecurrenceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgRecurrenceRepository) Create(ctx context.Context, recurrence *model.Recurrence) (*model.Recurrence, error) {
+	r.logger.Info("start RecurrenceRepository.Create")
+	err := conn(ctx, r.db).Create(recurrence).Error
+	if err != nil {
+		return nil, MapGormError(err, "recurrence")
+	}
+	return recurrence, nil
+}
+
+func (r *PgRecurrenceRepository) GetByID(ctx context.Context, id uint) (*model.Recurrence, error) {
+	r.logger.Info("start RecurrenceRepository.GetByID")
+	var recurrence model.Recurrence
+	err := conn(ctx, r.db).First(&recurrence, id).Error
+	if err != nil {
+		return nil, MapGormError(err, "recurrence")
+	}
+	return &recurrence, nil
+}
+
+func (r *PgRecurrenceRepository) ListByCompany(ctx context.Context, companyID uint) ([]*model.Recurrence, error) {
+	r.logger.Info("start RecurrenceRepository.ListByCompany")
+	var recurrences []*model.Recurrence
+	err := conn(ctx, r.db).
+		Where("company_id = ?", companyID).
+		Order("id").
+		Find(&recurrences).Error
+	if err != nil {
+		return nil, MapGormError(err, "recurrence")
+	}
+	return recurrences, nil
+}
+
+func (r *PgRecurrenceRepository) Update(ctx context.Context, recurrence *model.Recurrence) error {
+	r.logger.Info("start RecurrenceRepository.Update")
+	err := conn(ctx, r.db).Save(recurrence).Error
+	if err != nil {
+		return MapGormError(err, "recurrence")
+	}
+	return nil
+}
+
+func (r *PgRecurrenceRepository) Delete(ctx context.Context, recurrence *model.Recurrence) error {
+	r.logger.Info("start RecurrenceRepository.Delete")
+	err := conn(ctx, r.db).Delete(recurrence).Error
+	if err != nil {
+		return MapGormError(err, "recurrence")
+	}
+	return nil
+}
+
+func (r *PgRecurrenceRepository) GetDueIDs(ctx context.Context, horizon time.Time) ([]uint, error) {
+	r.logger.Info("start RecurrenceRepository.GetDueIDs")
+	var ids []uint
+	err := conn(ctx, r.db).Model(&model.Recurrence{}).
+		Where("active AND generated_until < ?", horizon).
+		Order("id").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, MapGormError(err, "recurrence")
+	}
+	return ids, nil
+}
+
+// LockDue SKIP LOCKED не ждет экземпляр, который уже создает задачи по шаблону,
+// а повторная проверка условий отсекает шаблоны, обработанные между GetDueIDs и блокировкой
+func (r *PgRecurrenceRepository) LockDue(ctx context.Context, id uint, horizon time.Time) (*model.Recurrence, error) {
+	r.logger.Info("start RecurrenceRepository.LockDue")
+	var recurrence model.Recurrence
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id = ? AND active AND generated_until < ?", id, horizon).
+		First(&recurrence).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, MapGormError(err, "recurrence")
+	}
+	return &recurrence, nil
+}
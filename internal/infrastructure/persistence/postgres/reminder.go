@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgReminderRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgReminderRepository(db *gorm.DB, logger *zap.Logger) repository.ReminderRepository {
+	return &PgReminderRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgReminderRepository) GetDueTasks(ctx context.Context, ahead time.Duration, from time.Time, to time.Time, limit int) ([]*model.Task, error) {
+	r.logger.Info("start ReminderRepository.GetDueTasks")
+	var tasks []*model.Task
+	err := conn(ctx, r.db).
+		Preload("Assignees").
+		Where("tasks.status <> ? AND tasks.deadline_at > ? AND tasks.deadline_at <= ?", model.CompletedStatus, from, to).
+		Where("NOT EXISTS (SELECT 1 FROM task_reminders tr WHERE tr.task_id = tasks.id AND tr.ahead = ? AND tr.deadline_at = tasks.deadline_at)", ahead).
+		Order("tasks.deadline_at, tasks.id").
+		Limit(limit).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, MapGormError(err, "task")
+	}
+	return tasks, nil
+}
+
+func (r *PgReminderRepository) Create(ctx context.Context, reminder *model.TaskReminder) (bool, error) {
+	r.logger.Info("start ReminderRepository.Create")
+	result := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+	if result.Error != nil {
+		return false, MapGormError(result.Error, "task reminder")
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -22,7 +22,7 @@ func NewPgRoleRepository(db *gorm.DB, logger *zap.Logger) repository.RoleReposit
 }
 
 func (r *PgRoleRepository) Create(ctx context.Context, role *rbac.Role) (*rbac.Role, error) {
-	err := r.db.WithContext(ctx).Create(role).Error
+	err := conn(ctx, r.db).Create(role).Error
 	if err != nil {
 		return nil, MapGormError(err, "role")
 	}
@@ -30,7 +30,7 @@ func (r *PgRoleRepository) Create(ctx context.Context, role *rbac.Role) (*rbac.R
 }
 
 func (r *PgRoleRepository) Update(ctx context.Context, role *rbac.Role) (*rbac.Role, error) {
-	err := r.db.WithContext(ctx).Save(role).Error
+	err := conn(ctx, r.db).Save(role).Error
 	if err != nil {
 		return nil, MapGormError(err, "role")
 	}
@@ -39,7 +39,7 @@ func (r *PgRoleRepository) Update(ctx context.Context, role *rbac.Role) (*rbac.R
 
 func (r *PgRoleRepository) GetByName(ctx context.Context, name string) (*rbac.Role, error) {
 	var role *rbac.Role
-	err := r.db.WithContext(ctx).First(&role, "name = ?", name).Error
+	err := conn(ctx, r.db).First(&role, "name = ?", name).Error
 	if err != nil {
 		return nil, MapGormError(err, "role")
 	}
@@ -49,7 +49,7 @@ func (r *PgRoleRepository) GetByName(ctx context.Context, name string) (*rbac.Ro
 func (r *PgRoleRepository) GetByIDs(ctx context.Context, ids []uint) ([]rbac.Role, error) {
 	r.logger.Info("start RoleRepository.GetByIDs")
 	var roles []rbac.Role
-	err := r.db.WithContext(ctx).Where("id IN (?)", ids).Find(&roles).Error
+	err := conn(ctx, r.db).Where("id IN (?)", ids).Find(&roles).Error
 	if err != nil {
 		return nil, MapGormError(err, "role")
 	}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/valueobject"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// searchMigration колонки search_vector генерируются самой базой, GORM их не мигрирует.
+// Индексируются русская и английская конфигурации: статусы и тексты на русском, но встречаются английские термины
+const searchMigration = `
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('russian', coalesce(description, '')), 'B') ||
+	setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector);
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	to_tsvector('russian', coalesce(content, '')) ||
+	to_tsvector('english', coalesce(content, ''))
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_comments_search_vector ON comments USING GIN (search_vector);
+`
+
+// MustMigrateSearch вызывается после AutoMigrate, миграция идемпотентна
+func MustMigrateSearch(db *gorm.DB, logger *zap.Logger) {
+	if err := db.Exec(searchMigration).Error; err != nil {
+		logger.Error("Failed to migrate search columns", zap.Error(err))
+		panic("failed to migrate search columns")
+	}
+}
+
+var headlineOptions = fmt.Sprintf("StartSel=%s, StopSel=%s, MaxFragments=2, MaxWords=25, MinWords=8, FragmentDelimiter=\" … \"", model.HighlightStart, model.HighlightStop)
+
+var titleHeadlineOptions = fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", model.HighlightStart, model.HighlightStop)
+
+// Запрос строится в обеих конфигурациях, совпадение в любой из них считается найденным
+const searchQueryCTE = `
+WITH q AS (
+	SELECT websearch_to_tsquery('russian', @query) || websearch_to_tsquery('english', @query) AS query
+)`
+
+const searchTasksQuery = searchQueryCTE + `
+SELECT t.id, t.id AS task_id, t.company_id, t.status,
+	ts_headline('russian', t.title, q.query, @titleOptions) AS title,
+	ts_headline('russian', coalesce(t.description, ''), q.query, @options) AS snippet,
+	ts_rank_cd(t.search_vector, q.query) AS rank,
+	count(*) OVER () AS total
+FROM tasks t, q
+WHERE t.search_vector @@ q.query
+	AND t.deleted_at IS NULL
+	AND t.company_id IN (SELECT company_id FROM users_companies WHERE user_id = @viewer)
+	AND (@company = 0 OR t.company_id = @company)
+ORDER BY rank DESC, t.id DESC
+LIMIT @limit`
+
+const searchCommentsQuery = searchQueryCTE + `
+SELECT c.id, c.task_id, t.company_id, t.status,
+	t.title,
+	ts_headline('russian', coalesce(c.content, ''), q.query, @options) AS snippet,
+	ts_rank_cd(c.search_vector, q.query) AS rank,
+	count(*) OVER () AS total
+FROM comments c JOIN tasks t ON t.id = c.task_id, q
+WHERE c.search_vector @@ q.query
+	AND c.deleted_at IS NULL
+	AND t.deleted_at IS NULL
+	AND t.company_id IN (SELECT company_id FROM users_companies WHERE user_id = @viewer)
+	AND (@company = 0 OR t.company_id = @company)
+ORDER BY rank DESC, c.id DESC
+LIMIT @limit`
+
+type searchRow struct {
+	ID        uint
+	TaskID    uint
+	CompanyID uint
+	Status    model.Status
+	Title     string
+	Snippet   string
+	Rank      float64
+	Total     int64
+}
+
+type PgSearchRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgSearchRepository(db *gorm.DB, logger *zap.Logger) repository.SearchRepository {
+	return &PgSearchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgSearchRepository) SearchTasks(ctx context.Context, params valueobject.SearchParams) ([]*model.SearchHit, int64, error) {
+	r.logger.Info("start SearchRepository.SearchTasks")
+	return r.search(ctx, searchTasksQuery, model.SearchEntityTask, params)
+}
+
+func (r *PgSearchRepository) SearchComments(ctx context.Context, params valueobject.SearchParams) ([]*model.SearchHit, int64, error) {
+	r.logger.Info("start SearchRepository.SearchComments")
+	return r.search(ctx, searchCommentsQuery, model.SearchEntityComment, params)
+}
+
+func (r *PgSearchRepository) search(ctx context.Context, query string, entityType model.SearchEntityType, params valueobject.SearchParams) ([]*model.SearchHit, int64, error) {
+	var rows []searchRow
+	err := conn(ctx, r.db).Raw(query, map[string]any{
+		"query":        params.Query,
+		"viewer":       params.ViewerID,
+		"company":      params.CompanyID,
+		"limit":        params.Limit,
+		"options":      headlineOptions,
+		"titleOptions": titleHeadlineOptions,
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, MapGormError(err, string(entityType))
+	}
+
+	var total int64
+	hits := make([]*model.SearchHit, 0, len(rows))
+	for _, row := range rows {
+		total = row.Total
+		hits = append(hits, &model.SearchHit{
+			EntityType: entityType,
+			ID:         row.ID,
+			TaskID:     row.TaskID,
+			CompanyID:  row.CompanyID,
+			Title:      row.Title,
+			Snippet:    row.Snippet,
+			Status:     row.Status,
+			Rank:       row.Rank,
+		})
+	}
+	return hits, total, nil
+}
@@ -5,9 +5,13 @@ import (
 	"rttask/internal/domain/model"
 	"rttask/internal/domain/repository"
 	"rttask/internal/domain/valueobject"
+	"slices"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PgTaskRepository struct {
@@ -24,25 +28,357 @@ func NewPgTaskRepository(db *gorm.DB, logger *zap.Logger) repository.TaskReposit
 
 func (r *PgTaskRepository) Create(ctx context.Context, task *model.Task) (*model.Task, error) {
 	r.logger.Info("start TaskRepository.Create")
-	err := r.db.WithContext(ctx).Create(&task).Error
+	err := conn(ctx, r.db).Create(&task).Error
 	if err != nil {
 		return nil, MapGormError(err, "task")
 	}
 	return task, nil
 }
 
-func (r *PgTaskRepository) GetUserTasks(ctx context.Context, params valueobject.PaginationParams, userID uint) ([]*model.Task, error) {
-	r.logger.Info("start TaskRepository.GetUserTasks")
+func (r *PgTaskRepository) GetByID(ctx context.Context, id uint) (*model.Task, error) {
+	r.logger.Info("start TaskRepository.GetByID")
+	var task model.Task
+	err := conn(ctx, r.db).Scopes(withDetails).First(&task, "id = ?", id).Error
+	if err != nil {
+		return nil, MapGormError(err, "task")
+	}
+	return &task, nil
+}
+
+func (r *PgTaskRepository) GetByIDForUpdate(ctx context.Context, id uint) (*model.Task, error) {
+	r.logger.Info("start TaskRepository.GetByIDForUpdate")
+	var task model.Task
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Assignees").
+		First(&task, "id = ?", id).Error
+	if err != nil {
+		return nil, MapGormError(err, "task")
+	}
+	return &task, nil
+}
+
+func (r *PgTaskRepository) UpdateExecutor(ctx context.Context, id uint, executorID uint) error {
+	r.logger.Info("start TaskRepository.UpdateExecutor")
+	err := conn(ctx, r.db).Model(&model.Task{}).Where("id = ?", id).Update("executor_id", executorID).Error
+	if err != nil {
+		return MapGormError(err, "task")
+	}
+	return nil
+}
+
+func (r *PgTaskRepository) UpdateBoardPosition(ctx context.Context, task *model.Task) error {
+	r.logger.Info("start TaskRepository.UpdateBoardPosition")
+	err := conn(ctx, r.db).Model(task).Updates(map[string]any{
+		"status":       task.Status,
+		"board_rank":   task.BoardRank,
+		"completed_at": task.CompletedAt,
+	}).Error
+	if err != nil {
+		return MapGormError(err, "task")
+	}
+	return nil
+}
+
+// boardOrder ранги сравниваются побайтно независимо от локали базы, id разрешает равные ранги
+const boardOrder = `board_rank COLLATE "C", id`
+
+func (r *PgTaskRepository) GetBoardColumn(ctx context.Context, companyID uint, status model.Status, limit int) ([]*model.Task, int64, error) {
+	r.logger.Info("start TaskRepository.GetBoardColumn")
+	query := conn(ctx, r.db).Model(&model.Task{}).Where("company_id = ? AND status = ?", companyID, status)
+
+	var count int64
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, MapGormError(err, "task")
+	}
+
+	var tasks []*model.Task
+	err := query.Scopes(withDetails).Order(boardOrder).Limit(limit).Find(&tasks).Error
+	if err != nil {
+		return nil, 0, MapGormError(err, "task")
+	}
+	return tasks, count, nil
+}
+
+func (r *PgTaskRepository) GetLastRank(ctx context.Context, companyID uint, status model.Status, excludeID uint) (string, error) {
+	r.logger.Info("start TaskRepository.GetLastRank")
+	var rank string
+	err := conn(ctx, r.db).Model(&model.Task{}).
+		Select(`COALESCE(MAX(board_rank COLLATE "C"), '')`).
+		Where("company_id = ? AND status = ? AND id <> ?", companyID, status, excludeID).
+		Scan(&rank).Error
+	if err != nil {
+		return "", MapGormError(err, "task")
+	}
+	return rank, nil
+}
+
+func (r *PgTaskRepository) CountBetween(ctx context.Context, companyID uint, status model.Status, excludeID uint, after, before *model.Task) (int64, error) {
+	r.logger.Info("start TaskRepository.CountBetween")
+	query := conn(ctx, r.db).Model(&model.Task{}).
+		Where("company_id = ? AND status = ? AND id <> ?", companyID, status, excludeID)
+	if after != nil {
+		query = query.Where(`(board_rank COLLATE "C", id) > (?, ?)`, after.BoardRank, after.ID)
+	}
+	if before != nil {
+		query = query.Where(`(board_rank COLLATE "C", id) < (?, ?)`, before.BoardRank, before.ID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, MapGormError(err, "task")
+	}
+	return count, nil
+}
+
+// withDetails подгружает чек-лист в порядке добавления и участников задачи
+func withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Checklist", func(db *gorm.DB) *gorm.DB { return db.Order("position, id") }).
+		Preload("Assignees", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+}
+
+func (r *PgTaskRepository) GetSubtaskProgress(ctx context.Context, taskIDs []uint) (map[uint]model.SubtaskProgress, error) {
+	r.logger.Info("start TaskRepository.GetSubtaskProgress")
+	progress := make(map[uint]model.SubtaskProgress, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return progress, nil
+	}
+
+	var rows []struct {
+		ParentID  uint
+		Total     int64
+		Completed int64
+	}
+	err := conn(ctx, r.db).Model(&model.Task{}).
+		Select("parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE status = ?) AS completed", model.CompletedStatus).
+		Where("parent_id IN ?", taskIDs).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, MapGormError(err, "task")
+	}
+
+	for _, row := range rows {
+		progress[row.ParentID] = model.SubtaskProgress{Total: row.Total, Completed: row.Completed}
+	}
+	return progress, nil
+}
+
+// ancestorsQuery depth ограничивает рекурсию, даже если в данных оказался цикл
+const ancestorsQuery = `
+WITH RECURSIVE ancestors AS (
+	SELECT id, parent_id, 0 AS depth FROM tasks WHERE id = @id AND deleted_at IS NULL
+	UNION ALL
+	SELECT t.id, t.parent_id, a.depth + 1
+	FROM tasks t JOIN ancestors a ON t.id = a.parent_id
+	WHERE t.deleted_at IS NULL AND a.depth < @limit
+)
+SELECT id FROM ancestors WHERE depth > 0 ORDER BY depth`
+
+func (r *PgTaskRepository) GetAncestorIDs(ctx context.Context, id uint) ([]uint, error) {
+	r.logger.Info("start TaskRepository.GetAncestorIDs")
+	var ids []uint
+	err := conn(ctx, r.db).Raw(ancestorsQuery, map[string]any{
+		"id":    id,
+		"limit": model.MaxTaskDepth + 1,
+	}).Scan(&ids).Error
+	if err != nil {
+		return nil, MapGormError(err, "task")
+	}
+	return ids, nil
+}
+
+const subtreeHeightQuery = `
+WITH RECURSIVE subtree AS (
+	SELECT id, 0 AS depth FROM tasks WHERE id = @id AND deleted_at IS NULL
+	UNION ALL
+	SELECT t.id, s.depth + 1
+	FROM tasks t JOIN subtree s ON t.parent_id = s.id
+	WHERE t.deleted_at IS NULL AND s.depth < @limit
+)
+SELECT COALESCE(MAX(depth), 0) FROM subtree`
+
+func (r *PgTaskRepository) GetSubtreeHeight(ctx context.Context, id uint) (int, error) {
+	r.logger.Info("start TaskRepository.GetSubtreeHeight")
+	var height int
+	err := conn(ctx, r.db).Raw(subtreeHeightQuery, map[string]any{
+		"id":    id,
+		"limit": model.MaxTaskDepth + 1,
+	}).Scan(&height).Error
+	if err != nil {
+		return 0, MapGormError(err, "task")
+	}
+	return height, nil
+}
+
+func (r *PgTaskRepository) UpdateParent(ctx context.Context, id uint, parentID *uint) error {
+	r.logger.Info("start TaskRepository.UpdateParent")
+	err := conn(ctx, r.db).Model(&model.Task{}).Where("id = ?", id).Update("parent_id", parentID).Error
+	if err != nil {
+		return MapGormError(err, "task")
+	}
+	return nil
+}
+
+func (r *PgTaskRepository) LockTree(ctx context.Context, companyID uint) error {
+	r.logger.Info("start TaskRepository.LockTree")
+	err := conn(ctx, r.db).Exec("SELECT pg_advisory_xact_lock(hashtext('task_tree'), ?)", companyID).Error
+	if err != nil {
+		return MapGormError(err, "task")
+	}
+	return nil
+}
+
+func (r *PgTaskRepository) RebalanceColumn(ctx context.Context, companyID uint, status model.Status) error {
+	r.logger.Info("start TaskRepository.RebalanceColumn")
+	db := conn(ctx, r.db)
+
+	var ids []uint
+	err := db.Model(&model.Task{}).
+		Where("company_id = ? AND status = ?", companyID, status).
+		Order(boardOrder).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return MapGormError(err, "task")
+	}
+
+	for i, rank := range valueobject.EvenRanks(len(ids)) {
+		// UpdateColumn не трогает updated_at: порядок на доске не изменение задачи
+		err := db.Model(&model.Task{}).Where("id = ?", ids[i]).UpdateColumn("board_rank", rank).Error
+		if err != nil {
+			return MapGormError(err, "task")
+		}
+	}
+	return nil
+}
+
+// taskSortColumns поля сортировки API и соответствующие им колонки
+var taskSortColumns = map[string]string{
+	"createdAt":  "created_at",
+	"updatedAt":  "updated_at",
+	"startAt":    "start_at",
+	"deadlineAt": "deadline_at",
+	"priority":   "priority",
+	"status":     "status",
+	"title":      "title",
+}
+
+func (r *PgTaskRepository) List(ctx context.Context, filter valueobject.TaskFilter, params valueobject.PaginationParams) ([]*model.Task, int64, error) {
+	r.logger.Info("start TaskRepository.List")
+
+	query := r.applyFilter(conn(ctx, r.db).Model(&model.Task{}), filter)
+
+	var count int64
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, MapGormError(err, "task")
+	}
+
 	var tasks []*model.Task
-	err := r.db.WithContext(ctx).
-		Model(&model.Task{}).
-		Order("created_at desc").
+	if params.CursorMode {
+		// в курсорном режиме сортировка только по времени создания
+		desc := len(filter.Sort) == 0 || filter.Sort[0].Desc
+		paged, backward := paginateByCursor(query, params, desc)
+		if err := paged.Scopes(withDetails).Find(&tasks).Error; err != nil {
+			return nil, 0, MapGormError(err, "task")
+		}
+		if backward {
+			slices.Reverse(tasks)
+		}
+		return tasks, count, nil
+	}
+
+	err := query.
+		Scopes(withDetails).
+		Order(taskOrder(filter.Sort)).
 		Offset(params.Offset).
 		Limit(params.Limit).
-		Where("user_id = ?", userID).
 		Find(&tasks).Error
 	if err != nil {
-		return nil, MapGormError(err, "task")
+		return nil, 0, MapGormError(err, "task")
+	}
+	return tasks, count, nil
+}
+
+func (r *PgTaskRepository) applyFilter(query *gorm.DB, filter valueobject.TaskFilter) *gorm.DB {
+	visible := r.db.Table("users_companies").Select("company_id").Where("user_id = ?", filter.ViewerID)
+	query = query.Where("company_id IN (?)", visible)
+
+	if len(filter.CompanyIDs) > 0 {
+		query = query.Where("company_id IN ?", filter.CompanyIDs)
+	}
+	if len(filter.ExecutorIDs) > 0 {
+		executors := r.db.Model(&model.TaskAssignee{}).Select("task_id").
+			Where("role = ? AND user_id IN ?", model.ExecutorRole, filter.ExecutorIDs)
+		query = query.Where("(executor_id IN ? OR id IN (?))", filter.ExecutorIDs, executors)
+	}
+	if len(filter.WatcherIDs) > 0 {
+		watchers := r.db.Model(&model.TaskAssignee{}).Select("task_id").
+			Where("role = ? AND user_id IN ?", model.WatcherRole, filter.WatcherIDs)
+		query = query.Where("id IN (?)", watchers)
+	}
+	if len(filter.CreatorIDs) > 0 {
+		query = query.Where("creator_id IN ?", filter.CreatorIDs)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("priority IN ?", filter.Priorities)
+	}
+	if len(filter.ParentIDs) > 0 {
+		query = query.Where("parent_id IN ?", filter.ParentIDs)
+	}
+
+	if filter.StartFrom != nil {
+		query = query.Where("start_at >= ?", *filter.StartFrom)
 	}
-	return tasks, nil
+	if filter.StartTo != nil {
+		query = query.Where("start_at <= ?", *filter.StartTo)
+	}
+	if filter.DeadlineFrom != nil {
+		query = query.Where("deadline_at >= ?", *filter.DeadlineFrom)
+	}
+	if filter.DeadlineTo != nil {
+		query = query.Where("deadline_at <= ?", *filter.DeadlineTo)
+	}
+
+	if filter.Overdue != nil {
+		now := time.Now()
+		if *filter.Overdue {
+			query = query.Where("deadline_at < ? AND status <> ?", now, model.CompletedStatus)
+		} else {
+			query = query.Where("(deadline_at >= ? OR status = ?)", now, model.CompletedStatus)
+		}
+	}
+
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("(title ILIKE ? OR description ILIKE ?)", pattern, pattern)
+	}
+	return query
+}
+
+// taskOrder id в конце делает порядок однозначным при равных значениях
+func taskOrder(sort []valueobject.SortField) clause.OrderBy {
+	if len(sort) == 0 {
+		sort = []valueobject.SortField{{Field: "createdAt", Desc: true}}
+	}
+
+	columns := make([]clause.OrderByColumn, 0, len(sort)+1)
+	for _, field := range sort {
+		column, ok := taskSortColumns[field.Field]
+		if !ok {
+			continue
+		}
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: field.Desc})
+	}
+	columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: sort[len(sort)-1].Desc})
+	return clause.OrderBy{Columns: columns}
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы поиск шел по подстроке как есть
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
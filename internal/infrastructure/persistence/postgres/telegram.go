@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgTelegramRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgTelegramRepository(db *gorm.DB, logger *zap.Logger) repository.TelegramRepository {
+	return &PgTelegramRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgTelegramRepository) ReplaceCode(ctx context.Context, code *model.TelegramLinkCode) error {
+	r.logger.Info("start TelegramRepository.ReplaceCode")
+	if err := conn(ctx, r.db).Where("user_id = ?", code.UserID).Delete(&model.TelegramLinkCode{}).Error; err != nil {
+		return MapGormError(err, "telegram link code")
+	}
+	if err := conn(ctx, r.db).Create(code).Error; err != nil {
+		return MapGormError(err, "telegram link code")
+	}
+	return nil
+}
+
+func (r *PgTelegramRepository) ConsumeCode(ctx context.Context, code string, now time.Time) (*model.TelegramLinkCode, error) {
+	r.logger.Info("start TelegramRepository.ConsumeCode")
+	var codes []*model.TelegramLinkCode
+	err := conn(ctx, r.db).
+		Clauses(clause.Returning{}).
+		Where("code = ? AND expires_at > ?", code, now).
+		Delete(&codes).Error
+	if err != nil {
+		return nil, MapGormError(err, "telegram link code")
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	return codes[0], nil
+}
+
+func (r *PgTelegramRepository) SaveLink(ctx context.Context, link *model.TelegramLink) error {
+	r.logger.Info("start TelegramRepository.SaveLink")
+	err := conn(ctx, r.db).
+		Where("user_id = ? OR telegram_user_id = ? OR chat_id = ?", link.UserID, link.TelegramUserID, link.ChatID).
+		Delete(&model.TelegramLink{}).Error
+	if err != nil {
+		return MapGormError(err, "telegram link")
+	}
+	if err := conn(ctx, r.db).Create(link).Error; err != nil {
+		return MapGormError(err, "telegram link")
+	}
+	return nil
+}
+
+func (r *PgTelegramRepository) GetLinkByUser(ctx context.Context, userID uint) (*model.TelegramLink, error) {
+	r.logger.Info("start TelegramRepository.GetLinkByUser")
+	return r.getLink(ctx, "user_id = ?", userID)
+}
+
+func (r *PgTelegramRepository) GetLinkByTelegramUser(ctx context.Context, telegramUserID int64) (*model.TelegramLink, error) {
+	r.logger.Info("start TelegramRepository.GetLinkByTelegramUser")
+	return r.getLink(ctx, "telegram_user_id = ?", telegramUserID)
+}
+
+func (r *PgTelegramRepository) DeleteLink(ctx context.Context, userID uint) (bool, error) {
+	r.logger.Info("start TelegramRepository.DeleteLink")
+	result := conn(ctx, r.db).Where("user_id = ?", userID).Delete(&model.TelegramLink{})
+	if result.Error != nil {
+		return false, MapGormError(result.Error, "telegram link")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PgTelegramRepository) getLink(ctx context.Context, query string, value any) (*model.TelegramLink, error) {
+	var link model.TelegramLink
+	err := conn(ctx, r.db).Where(query, value).First(&link).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, MapGormError(err, "telegram link")
+	}
+	return &link, nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PgTemplateRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgTemplateRepository(db *gorm.DB, logger *zap.Logger) repository.TemplateRepository {
+	return &PgTemplateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgTemplateRepository) Create(ctx context.Context, template *model.TaskTemplate) (*model.TaskTemplate, error) {
+	r.logger.Info("start TemplateRepository.Create")
+	err := conn(ctx, r.db).Create(template).Error
+	if err != nil {
+		return nil, MapGormError(err, "template")
+	}
+	return template, nil
+}
+
+func (r *PgTemplateRepository) GetByID(ctx context.Context, id uint) (*model.TaskTemplate, error) {
+	r.logger.Info("start TemplateRepository.GetByID")
+	var template model.TaskTemplate
+	err := conn(ctx, r.db).First(&template, id).Error
+	if err != nil {
+		return nil, MapGormError(err, "template")
+	}
+	return &template, nil
+}
+
+func (r *PgTemplateRepository) ListByCompany(ctx context.Context, companyID uint) ([]*model.TaskTemplate, error) {
+	r.logger.Info("start TemplateRepository.ListByCompany")
+	var templates []*model.TaskTemplate
+	err := conn(ctx, r.db).
+		Where("company_id = ?", companyID).
+		Order("name, id").
+		Find(&templates).Error
+	if err != nil {
+		return nil, MapGormError(err, "template")
+	}
+	return templates, nil
+}
+
+func (r *PgTemplateRepository) Update(ctx context.Context, template *model.TaskTemplate) error {
+	r.logger.Info("start TemplateRepository.Update")
+	err := conn(ctx, r.db).Save(template).Error
+	if err != nil {
+		return MapGormError(err, "template")
+	}
+	return nil
+}
+
+func (r *PgTemplateRepository) Delete(ctx context.Context, template *model.TaskTemplate) error {
+	r.logger.Info("start TemplateRepository.Delete")
+	err := conn(ctx, r.db).Delete(template).Error
+	if err != nil {
+		return MapGormError(err, "template")
+	}
+	return nil
+}
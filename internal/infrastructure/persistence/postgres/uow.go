@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	domainerrors "rttask/internal/domain/errors"
+	"rttask/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type PgUnitOfWork struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgUnitOfWork(db *gorm.DB, logger *zap.Logger) repository.UnitOfWork {
+	return &PgUnitOfWork{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Do открывает транзакцию и кладет ее в контекст. Вложенные вызовы используют уже открытую транзакцию
+func (u *PgUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	if err != nil && !domainerrors.IsDomainError(err) {
+		u.logger.Error("transaction failed", zap.Error(err))
+		return MapGormError(err, "transaction")
+	}
+	return err
+}
+
+// conn возвращает транзакцию из контекста, если она есть, иначе обычное соединение
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgUploadRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgUploadRepository(db *gorm.DB, logger *zap.Logger) repository.UploadRepository {
+	return &PgUploadRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgUploadRepository) Create(ctx context.Context, upload *model.Upload) (*model.Upload, error) {
+	r.logger.Info("start UploadRepository.Create")
+	err := conn(ctx, r.db).Create(upload).Error
+	if err != nil {
+		return nil, MapGormError(err, "upload")
+	}
+	return upload, nil
+}
+
+func (r *PgUploadRepository) GetByID(ctx context.Context, id string) (*model.Upload, error) {
+	var upload model.Upload
+	err := conn(ctx, r.db).First(&upload, "id = ?", id).Error
+	if err != nil {
+		return nil, MapGormError(err, "upload")
+	}
+	return &upload, nil
+}
+
+// AppendPart оптимистичная блокировка по offset: параллельный PATCH с тем же смещением не пройдет
+func (r *PgUploadRepository) AppendPart(ctx context.Context, upload *model.Upload, part *model.UploadPart) (bool, error) {
+	r.logger.Info("start UploadRepository.AppendPart")
+	parts := append(upload.Parts, part)
+	result := conn(ctx, r.db).Model(&model.Upload{}).
+		Where("id = ? AND \"offset\" = ?", upload.ID, upload.Offset).
+		Select("offset", "parts").
+		Updates(&model.Upload{Offset: upload.Offset + part.Size, Parts: parts})
+	if result.Error != nil {
+		return false, MapGormError(result.Error, "upload")
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	upload.Parts = parts
+	upload.Offset += part.Size
+	return true, nil
+}
+
+// ClaimAssembly просроченная сборка (упавший запрос) занимается заново
+func (r *PgUploadRepository) ClaimAssembly(ctx context.Context, id string, until time.Time) (bool, error) {
+	r.logger.Info("start UploadRepository.ClaimAssembly")
+	result := conn(ctx, r.db).Model(&model.Upload{}).
+		Where("id = ? AND status = ? AND \"offset\" = length AND (assembling_until IS NULL OR assembling_until < ?)", id, model.UploadStatusPending, time.Now()).
+		Update("assembling_until", until)
+	if result.Error != nil {
+		return false, MapGormError(result.Error, "upload")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PgUploadRepository) ReleaseAssembly(ctx context.Context, id string) error {
+	r.logger.Info("start UploadRepository.ReleaseAssembly")
+	err := conn(ctx, r.db).Model(&model.Upload{}).
+		Where("id = ? AND status = ?", id, model.UploadStatusPending).
+		Update("assembling_until", nil).Error
+	if err != nil {
+		return MapGormError(err, "upload")
+	}
+	return nil
+}
+
+func (r *PgUploadRepository) Finish(ctx context.Context, upload *model.Upload) (bool, error) {
+	r.logger.Info("start UploadRepository.Finish")
+	result := conn(ctx, r.db).Model(&model.Upload{}).
+		Where("id = ? AND status = ?", upload.ID, model.UploadStatusPending).
+		Select("status", "file", "parts", "expires_at", "assembling_until").
+		Updates(&model.Upload{Status: upload.Status, File: upload.File, Parts: upload.Parts, ExpiresAt: upload.ExpiresAt})
+	if result.Error != nil {
+		return false, MapGormError(result.Error, "upload")
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	upload.AssemblingUntil = nil
+	return true, nil
+}
+
+// GetCompletedByIDs ищет собранные загрузки по ID получившихся файлов
+func (r *PgUploadRepository) GetCompletedByIDs(ctx context.Context, fileIDs []string, uploaderID uint) ([]*model.Upload, error) {
+	var uploads []*model.Upload
+	err := conn(ctx, r.db).
+		Where("file->>'id' IN (?) AND uploader_id = ? AND status = ? AND expires_at > ?", fileIDs, uploaderID, model.UploadStatusCompleted, time.Now()).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Find(&uploads).Error
+	if err != nil {
+		return nil, MapGormError(err, "upload")
+	}
+	return uploads, nil
+}
+
+func (r *PgUploadRepository) MarkAttached(ctx context.Context, ids []string) error {
+	err := conn(ctx, r.db).Model(&model.Upload{}).
+		Where("id IN (?)", ids).
+		Update("status", model.UploadStatusAttached).Error
+	if err != nil {
+		return MapGormError(err, "upload")
+	}
+	return nil
+}
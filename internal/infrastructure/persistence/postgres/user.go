@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"rttask/internal/domain/model"
+	"rttask/internal/domain/model/rbac"
 	"rttask/internal/domain/repository"
 
 	"go.uber.org/zap"
@@ -23,7 +24,7 @@ func NewPgUserRepository(db *gorm.DB, logger *zap.Logger) repository.UserReposit
 
 func (r *PgUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user *model.User
-	err := r.db.WithContext(ctx).First(&user, "email = ?", email).Error
+	err := conn(ctx, r.db).First(&user, "email = ?", email).Error
 	if err != nil {
 		return nil, MapGormError(err, "user")
 	}
@@ -32,7 +33,7 @@ func (r *PgUserRepository) GetUserByEmail(ctx context.Context, email string) (*m
 
 func (r *PgUserRepository) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
 	var user *model.User
-	err := r.db.WithContext(ctx).Preload("Roles").First(&user, "id = ?", id).Error
+	err := conn(ctx, r.db).Preload("Roles").First(&user, "id = ?", id).Error
 	if err != nil {
 		return nil, MapGormError(err, "user")
 	}
@@ -40,7 +41,7 @@ func (r *PgUserRepository) GetUserByID(ctx context.Context, id uint) (*model.Use
 }
 
 func (r *PgUserRepository) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
-	err := r.db.WithContext(ctx).Create(&user).Error
+	err := conn(ctx, r.db).Create(&user).Error
 	if err != nil {
 		return nil, MapGormError(err, "user")
 	}
@@ -49,7 +50,7 @@ func (r *PgUserRepository) CreateUser(ctx context.Context, user *model.User) (*m
 
 func (r *PgUserRepository) GetUserByIDWithRoles(ctx context.Context, id uint) (*model.User, error) {
 	var user *model.User
-	err := r.db.WithContext(ctx).Preload("Roles").First(&user, "id = ?", id).Error
+	err := conn(ctx, r.db).Preload("Roles").First(&user, "id = ?", id).Error
 	if err != nil {
 		return nil, MapGormError(err, "user")
 	}
@@ -60,6 +61,44 @@ func (r *PgUserRepository) IsUserInCompany(ctx context.Context, userID uint, com
 	var user model.User
 	user.ID = userID
 
-	count := r.db.WithContext(ctx).Model(&user).Where("id = ?", companyID).Association("Companies").Count()
+	count := conn(ctx, r.db).Model(&user).Where("id = ?", companyID).Association("Companies").Count()
 	return count > 0, nil
 }
+
+func (r *PgUserRepository) ShareCompany(ctx context.Context, userID uint, otherID uint) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Table("users_companies AS own").
+		Joins("JOIN users_companies AS other ON other.company_id = own.company_id").
+		Where("own.user_id = ? AND other.user_id = ?", userID, otherID).
+		Count(&count).Error
+	if err != nil {
+		return false, MapGormError(err, "user")
+	}
+	return count > 0, nil
+}
+
+func (r *PgUserRepository) FindCompanyMembers(ctx context.Context, companyID uint, handles []string) ([]*model.User, error) {
+	r.logger.Info("start UserRepository.FindCompanyMembers")
+	var users []*model.User
+	if len(handles) == 0 {
+		return users, nil
+	}
+	err := conn(ctx, r.db).
+		Joins("JOIN users_companies ON users_companies.user_id = users.id").
+		Where("users_companies.company_id = ?", companyID).
+		Where("LOWER(users.email) IN ? OR LOWER(SPLIT_PART(users.email, '@', 1)) IN ?", handles, handles).
+		Find(&users).Error
+	if err != nil {
+		return nil, MapGormError(err, "user")
+	}
+	return users, nil
+}
+
+func (r *PgUserRepository) AssignRoles(ctx context.Context, user *model.User, roles []rbac.Role) error {
+	r.logger.Info("start UserRepository.AssignRoles")
+	err := conn(ctx, r.db).Model(user).Association("Roles").Append(roles)
+	if err != nil {
+		return MapGormError(err, "user")
+	}
+	return nil
+}
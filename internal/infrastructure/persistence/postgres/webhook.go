@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/repository"
+	"rttask/internal/domain/valueobject"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PgWebhookRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPgWebhookRepository(db *gorm.DB, logger *zap.Logger) repository.WebhookRepository {
+	return &PgWebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PgWebhookRepository) Create(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	r.logger.Info("start WebhookRepository.Create")
+	err := conn(ctx, r.db).Create(webhook).Error
+	if err != nil {
+		return nil, MapGormError(err, "webhook")
+	}
+	return webhook, nil
+}
+
+func (r *PgWebhookRepository) GetByID(ctx context.Context, id uint) (*model.Webhook, error) {
+	r.logger.Info("start WebhookRepository.GetByID")
+	var webhook model.Webhook
+	err := conn(ctx, r.db).First(&webhook, id).Error
+	if err != nil {
+		return nil, MapGormError(err, "webhook")
+	}
+	return &webhook, nil
+}
+
+func (r *PgWebhookRepository) ListByCompany(ctx context.Context, companyID uint) ([]*model.Webhook, error) {
+	r.logger.Info("start WebhookRepository.ListByCompany")
+	var webhooks []*model.Webhook
+	err := conn(ctx, r.db).
+		Where("company_id = ?", companyID).
+		Order("id").
+		Find(&webhooks).Error
+	if err != nil {
+		return nil, MapGormError(err, "webhook")
+	}
+	return webhooks, nil
+}
+
+func (r *PgWebhookRepository) ListSubscribed(ctx context.Context, companyID uint, eventType string) ([]*model.Webhook, error) {
+	r.logger.Info("start WebhookRepository.ListSubscribed")
+	var webhooks []*model.Webhook
+	err := conn(ctx, r.db).
+		Where("company_id = ? AND active AND events @> jsonb_build_array(?::text)", companyID, eventType).
+		Order("id").
+		Find(&webhooks).Error
+	if err != nil {
+		return nil, MapGormError(err, "webhook")
+	}
+	return webhooks, nil
+}
+
+func (r *PgWebhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	r.logger.Info("start WebhookRepository.Update")
+	err := conn(ctx, r.db).Save(webhook).Error
+	if err != nil {
+		return MapGormError(err, "webhook")
+	}
+	return nil
+}
+
+func (r *PgWebhookRepository) Delete(ctx context.Context, webhook *model.Webhook) error {
+	r.logger.Info("start WebhookRepository.Delete")
+	err := conn(ctx, r.db).Delete(webhook).Error
+	if err != nil {
+		return MapGormError(err, "webhook")
+	}
+	return nil
+}
+
+func (r *PgWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error {
+	r.logger.Info("start WebhookRepository.CreateDeliveries")
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := conn(ctx, r.db).Create(&deliveries).Error
+	if err != nil {
+		return MapGormError(err, "webhook delivery")
+	}
+	return nil
+}
+
+func (r *PgWebhookRepository) GetDelivery(ctx context.Context, webhookID uint, deliveryID uint) (*model.WebhookDelivery, error) {
+	r.logger.Info("start WebhookRepository.GetDelivery")
+	var delivery model.WebhookDelivery
+	err := conn(ctx, r.db).Where("id = ? AND webhook_id = ?", deliveryID, webhookID).First(&delivery).Error
+	if err != nil {
+		return nil, MapGormError(err, "webhook delivery")
+	}
+	return &delivery, nil
+}
+
+func (r *PgWebhookRepository) ListDeliveries(ctx context.Context, webhookID uint, params valueobject.PaginationParams) ([]*model.WebhookDelivery, int64, error) {
+	r.logger.Info("start WebhookRepository.ListDeliveries")
+	query := conn(ctx, r.db).Model(&model.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, MapGormError(err, "webhook delivery")
+	}
+
+	var deliveries []*model.WebhookDelivery
+	err := query.
+		Order("created_at DESC, id DESC").
+		Offset(params.Offset).
+		Limit(params.Limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, MapGormError(err, "webhook delivery")
+	}
+	return deliveries, count, nil
+}
+
+func (r *PgWebhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	r.logger.Info("start WebhookRepository.UpdateDelivery")
+	err := conn(ctx, r.db).Save(delivery).Error
+	if err != nil {
+		return MapGormError(err, "webhook delivery")
+	}
+	return nil
+}
+
+func (r *PgWebhookRepository) GetDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	r.logger.Info("start WebhookRepository.GetDueDeliveryIDs")
+	var ids []uint
+	err := conn(ctx, r.db).Model(&model.WebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, MapGormError(err, "webhook delivery")
+	}
+	return ids, nil
+}
+
+func (r *PgWebhookRepository) LockDueDelivery(ctx context.Context, id uint, now time.Time) (*model.WebhookDelivery, error) {
+	r.logger.Info("start WebhookRepository.LockDueDelivery")
+	var delivery model.WebhookDelivery
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, model.DeliveryPending, now).
+		First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, MapGormError(err, "webhook delivery")
+	}
+	return &delivery, nil
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	domainerrors "rttask/internal/domain/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConformance общие проверки FileStorage, одинаковые для всех драйверов.
+// prefix отделяет файлы теста от остального содержимого хранилища
+func testConformance(t *testing.T, store FileStorage, prefix string) {
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+	t.Run("SaveOpen", func(t *testing.T) {
+		path := prefix + "save/file.bin"
+		if err := store.Save(ctx, bytes.NewReader(content), path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		defer store.Delete(ctx, path)
+
+		file, err := store.Open(ctx, path)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer file.Close()
+		got, err := io.ReadAll(file)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Fatalf("read %d bytes, want the saved %d bytes", len(got), len(content))
+		}
+	})
+
+	t.Run("SaveOverwrites", func(t *testing.T) {
+		path := prefix + "overwrite.txt"
+		if err := store.Save(ctx, strings.NewReader("first version"), path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		defer store.Delete(ctx, path)
+		if err := store.Save(ctx, strings.NewReader("second"), path); err != nil {
+			t.Fatalf("Save again: %v", err)
+		}
+		if got := readAll(t, store, path); got != "second" {
+			t.Fatalf("content = %q, want %q", got, "second")
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		path := prefix + "stat.bin"
+		before := time.Now().Add(-time.Minute)
+		if err := store.Save(ctx, bytes.NewReader(content), path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		defer store.Delete(ctx, path)
+
+		info, err := store.Stat(ctx, path)
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		if info.Size != int64(len(content)) {
+			t.Errorf("Size = %d, want %d", info.Size, len(content))
+		}
+		if info.ModTime.Before(before) {
+			t.Errorf("ModTime = %v, want after %v", info.ModTime, before)
+		}
+	})
+
+	t.Run("RangeRead", func(t *testing.T) {
+		path := prefix + "range.bin"
+		if err := store.Save(ctx, bytes.NewReader(content), path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		defer store.Delete(ctx, path)
+
+		file, err := store.Open(ctx, path)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer file.Close()
+
+		// так http.ServeContent отвечает на Range: сначала размер через SeekEnd, затем чтение с середины
+		size, err := file.Seek(0, io.SeekEnd)
+		if err != nil || size != int64(len(content)) {
+			t.Fatalf("Seek end = %d, %v, want %d", size, err, len(content))
+		}
+		for _, r := range []struct{ offset, length int64 }{{0, 16}, {1000, 500}, {size - 10, 10}} {
+			if _, err := file.Seek(r.offset, io.SeekStart); err != nil {
+				t.Fatalf("Seek %d: %v", r.offset, err)
+			}
+			got := make([]byte, r.length)
+			if _, err := io.ReadFull(file, got); err != nil {
+				t.Fatalf("read range %d+%d: %v", r.offset, r.length, err)
+			}
+			if want := content[r.offset : r.offset+r.length]; !bytes.Equal(got, want) {
+				t.Fatalf("range %d+%d = %q, want %q", r.offset, r.length, got, want)
+			}
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		path := prefix + "delete.txt"
+		if err := store.Save(ctx, strings.NewReader("bye"), path); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if err := store.Delete(ctx, path); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.Stat(ctx, path); !isNotFound(err) {
+			t.Fatalf("Stat after Delete = %v, want not found", err)
+		}
+		// повторное удаление не ошибка: сборщик мусора и удаление файла могут пересечься
+		if err := store.Delete(ctx, path); err != nil {
+			t.Fatalf("Delete missing: %v", err)
+		}
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		path := prefix + "missing/nothing.txt"
+		if _, err := store.Open(ctx, path); !isNotFound(err) {
+			t.Errorf("Open missing = %v, want not found", err)
+		}
+		if _, err := store.Stat(ctx, path); !isNotFound(err) {
+			t.Errorf("Stat missing = %v, want not found", err)
+		}
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		paths := map[string]string{
+			prefix + "walk/a.txt":        "a",
+			prefix + "walk/nested/b.txt": "bb",
+			prefix + "walk/nested/c.txt": "ccc",
+		}
+		for path, data := range paths {
+			if err := store.Save(ctx, strings.NewReader(data), path); err != nil {
+				t.Fatalf("Save %s: %v", path, err)
+			}
+			defer store.Delete(ctx, path)
+		}
+
+		seen := make(map[string]int64)
+		err := store.Walk(ctx, func(path string, info FileInfo) error {
+			if strings.HasPrefix(path, prefix+"walk/") {
+				seen[path] = info.Size
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Walk: %v", err)
+		}
+		for path, data := range paths {
+			if size, ok := seen[path]; !ok {
+				t.Errorf("Walk did not visit %s", path)
+			} else if size != int64(len(data)) {
+				t.Errorf("Walk size of %s = %d, want %d", path, size, len(data))
+			}
+		}
+		if len(seen) != len(paths) {
+			t.Errorf("Walk visited %v, want %d files", seen, len(paths))
+		}
+
+		stop := errors.New("stop")
+		visited := 0
+		err = store.Walk(ctx, func(path string, info FileInfo) error {
+			visited++
+			return stop
+		})
+		if visited != 1 || !errors.Is(err, stop) {
+			t.Errorf("Walk stopped after %d files with %v, want 1 file and the callback error", visited, err)
+		}
+	})
+}
+
+func readAll(t *testing.T, store FileStorage, path string) string {
+	t.Helper()
+	file, err := store.Open(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Open %s: %v", path, err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func isNotFound(err error) bool {
+	var domainErr *domainerrors.DomainError
+	return errors.As(err, &domainErr) && domainErr.Type == domainerrors.ErrorTypeNotFound
+}
+
+func testPrefix() string {
+	return fmt.Sprintf("conformance-%d/", time.Now().UnixNano())
+}
@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	domainerrors "rttask/internal/domain/errors"
@@ -38,3 +40,72 @@ func (s *LocalStorage) Save(ctx context.Context, file io.Reader, path string) er
 	}
 	return nil
 }
+
+func (s *LocalStorage) Open(ctx context.Context, path string) (io.ReadSeekCloser, error) {
+	fullPath := filepath.Join(s.basePath, path)
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, domainerrors.NewNotFoundError("file", path)
+		}
+		return nil, domainerrors.NewInternalError("open file error", err)
+	}
+	return file, nil
+}
+
+func (s *LocalStorage) Stat(ctx context.Context, path string) (*FileInfo, error) {
+	fullPath := filepath.Join(s.basePath, path)
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, domainerrors.NewNotFoundError("file", path)
+		}
+		return nil, domainerrors.NewInternalError("stat file error", err)
+	}
+	return &FileInfo{Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, path string) error {
+	fullPath := filepath.Join(s.basePath, path)
+
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return domainerrors.NewInternalError("delete file error", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) Walk(ctx context.Context, fn func(path string, info FileInfo) error) error {
+	err := filepath.WalkDir(s.basePath, func(fullPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && fullPath == s.basePath {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(s.basePath, fullPath)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), FileInfo{Size: info.Size(), ModTime: info.ModTime()})
+	})
+	if err != nil {
+		return domainerrors.NewInternalError("walk storage error", err)
+	}
+	return nil
+}
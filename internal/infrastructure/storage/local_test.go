@@ -0,0 +1,18 @@
+package storage
+
+import "testing"
+
+func TestLocalStorageConformance(t *testing.T) {
+	testConformance(t, NewLocalStorage(t.TempDir()), testPrefix())
+}
+
+func TestLocalStorageWalkMissingBase(t *testing.T) {
+	store := NewLocalStorage(t.TempDir() + "/not-created")
+	err := store.Walk(t.Context(), func(path string, info FileInfo) error {
+		t.Errorf("unexpected file %s", path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk of missing base dir: %v", err)
+	}
+}
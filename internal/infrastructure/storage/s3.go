@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"rttask/internal/config"
+	domainerrors "rttask/internal/domain/errors"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage хранилище в S3-совместимом объектном хранилище (MinIO, Yandex Object Storage и т.д.)
+type S3Storage struct {
+	client *minio.Client
+	// presignClient подписывает ссылки для публичного адреса хранилища, nil если адрес не задан
+	presignClient *minio.Client
+	bucket        string
+	partSize      uint64
+	presignTTL    time.Duration
+}
+
+func NewS3Storage(ctx context.Context, cfg config.S3) (FileStorage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, err
+		}
+	}
+
+	var presignClient *minio.Client
+	if cfg.PublicEndpoint != "" {
+		// подпись включает адрес, поэтому внутренний адрес вроде minio:9000 для ссылок не годится.
+		// Регион задан явно, чтобы подпись не требовала запросов к хранилищу по публичному адресу
+		presignClient, err = minio.New(cfg.PublicEndpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+			Secure: cfg.PublicUseSSL,
+			Region: cfg.Region,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3Storage{
+		client:        client,
+		presignClient: presignClient,
+		bucket:        cfg.Bucket,
+		partSize:      cfg.PartSizeBytes(),
+		presignTTL:    cfg.PresignDuration(),
+	}, nil
+}
+
+// Save размер заранее неизвестен, поэтому файл грузится multipart частями по partSize
+func (s *S3Storage) Save(ctx context.Context, file io.Reader, path string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, path, file, -1, minio.PutObjectOptions{
+		PartSize: s.partSize,
+	})
+	if err != nil {
+		return domainerrors.NewInternalError("upload file error", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, path string) (io.ReadSeekCloser, error) {
+	if _, err := s.Stat(ctx, path); err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(ctx, s.bucket, path, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, domainerrors.NewInternalError("open file error", err)
+	}
+	return object, nil
+}
+
+func (s *S3Storage) Stat(ctx context.Context, path string) (*FileInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, path, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, domainerrors.NewNotFoundError("file", path)
+		}
+		return nil, domainerrors.NewInternalError("stat file error", err)
+	}
+	return &FileInfo{Size: info.Size, ModTime: info.LastModified}, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, path string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, path, minio.RemoveObjectOptions{}); err != nil {
+		return domainerrors.NewInternalError("delete file error", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) Walk(ctx context.Context, fn func(path string, info FileInfo) error) error {
+	// отмена контекста останавливает листинг, если обход прерван раньше конца
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			return domainerrors.NewInternalError("walk storage error", object.Err)
+		}
+		if err := fn(object.Key, FileInfo{Size: object.Size, ModTime: object.LastModified}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PresignGet временная ссылка на скачивание напрямую из хранилища по публичному адресу.
+// Заголовки ответа те же, что при отдаче через приложение
+func (s *S3Storage) PresignGet(ctx context.Context, path string, contentType string, disposition string) (string, error) {
+	if s.presignClient == nil {
+		return "", nil
+	}
+	params := url.Values{}
+	params.Set("response-content-disposition", disposition)
+	if contentType != "" {
+		params.Set("response-content-type", contentType)
+	}
+
+	presigned, err := s.presignClient.PresignedGetObject(ctx, s.bucket, path, s.presignTTL, params)
+	if err != nil {
+		return "", domainerrors.NewInternalError("presign file error", err)
+	}
+	return presigned.String(), nil
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"rttask/internal/config"
+	"rttask/internal/domain/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// TestS3StorageConformance запускается против MinIO или другого S3, заданного переменными
+// TEST_S3_ENDPOINT, TEST_S3_ACCESS_KEY, TEST_S3_SECRET_KEY и необязательной TEST_S3_BUCKET:
+//
+//	docker run -p 9000:9000 minio/minio:RELEASE.2025-09-07T16-13-09Z server /data
+//	TEST_S3_ENDPOINT=localhost:9000 TEST_S3_ACCESS_KEY=minioadmin TEST_S3_SECRET_KEY=minioadmin go test ./internal/infrastructure/storage
+func TestS3StorageConformance(t *testing.T) {
+	endpoint := os.Getenv("TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_S3_ENDPOINT is not set, S3 storage is not available")
+	}
+	bucket := os.Getenv("TEST_S3_BUCKET")
+	if bucket == "" {
+		bucket = "rttask-test"
+	}
+
+	store, err := NewS3Storage(context.Background(), config.S3{
+		Endpoint:   endpoint,
+		AccessKey:  os.Getenv("TEST_S3_ACCESS_KEY"),
+		SecretKey:  os.Getenv("TEST_S3_SECRET_KEY"),
+		Bucket:     bucket,
+		Region:     "us-east-1",
+		UseSSL:     os.Getenv("TEST_S3_USE_SSL") == "true",
+		PartSize:   5,
+		PresignTTL: 15,
+	})
+	if err != nil {
+		t.Fatalf("connect to S3 at %s: %v", endpoint, err)
+	}
+	testConformance(t, store, testPrefix())
+}
+
+func TestS3StoragePresignGet(t *testing.T) {
+	newStore := func(publicEndpoint string) *S3Storage {
+		t.Helper()
+		// регион задан, поэтому подпись считается локально без обращения к хранилищу
+		options := &minio.Options{Creds: credentials.NewStaticV4("key", "secret", ""), Region: "us-east-1"}
+		client, err := minio.New("minio:9000", options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store := &S3Storage{client: client, bucket: "rttask", presignTTL: time.Minute}
+		if publicEndpoint != "" {
+			options.Secure = true
+			if store.presignClient, err = minio.New(publicEndpoint, options); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return store
+	}
+
+	t.Run("WithoutPublicEndpoint", func(t *testing.T) {
+		link, err := newStore("").PresignGet(context.Background(), "a/b.png", "image/png", "inline")
+		if err != nil || link != "" {
+			t.Fatalf("PresignGet = %q, %v, want empty link to stream through the app", link, err)
+		}
+	})
+
+	t.Run("PublicEndpoint", func(t *testing.T) {
+		file := &model.File{Name: "фото.png", MimeType: "image/png"}
+		link, err := newStore("files.example.com").PresignGet(context.Background(), "a/b.png", file.MimeType, file.ContentDisposition())
+		if err != nil {
+			t.Fatalf("PresignGet: %v", err)
+		}
+		parsed, err := url.Parse(link)
+		if err != nil {
+			t.Fatalf("parse %q: %v", link, err)
+		}
+		if parsed.Scheme != "https" || parsed.Host != "files.example.com" {
+			t.Errorf("link host = %s://%s, want https://files.example.com", parsed.Scheme, parsed.Host)
+		}
+		query := parsed.Query()
+		if got := query.Get("response-content-disposition"); got != file.ContentDisposition() {
+			t.Errorf("disposition = %q, want %q", got, file.ContentDisposition())
+		}
+		if !strings.HasPrefix(query.Get("response-content-disposition"), "inline") {
+			t.Errorf("image should be presigned inline like the local backend serves it")
+		}
+		if got := query.Get("response-content-type"); got != "image/png" {
+			t.Errorf("content type = %q, want image/png", got)
+		}
+	})
+}
@@ -3,8 +3,51 @@ package storage
 import (
 	"context"
 	"io"
+	"rttask/internal/config"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
 )
 
+type FileInfo struct {
+	Size    int64
+	ModTime time.Time
+}
+
 type FileStorage interface {
 	Save(ctx context.Context, file io.Reader, path string) error
+	Open(ctx context.Context, path string) (io.ReadSeekCloser, error)
+	Stat(ctx context.Context, path string) (*FileInfo, error)
+	Delete(ctx context.Context, path string) error
+	// Walk обходит все файлы хранилища. Пути в том же виде, в котором передаются в Save
+	Walk(ctx context.Context, fn func(path string, info FileInfo) error) error
+}
+
+// Presigner хранилища, которые умеют выдавать временные ссылки на скачивание.
+// Пустая ссылка без ошибки значит, что файл нужно отдать через приложение
+type Presigner interface {
+	PresignGet(ctx context.Context, path string, contentType string, disposition string) (string, error)
+}
+
+// MustNewStorage создает хранилище по драйверу из конфига
+func MustNewStorage(ctx context.Context, cfg config.Storage, logger *zap.Logger) FileStorage {
+	switch cfg.Driver {
+	case DriverS3:
+		store, err := NewS3Storage(ctx, cfg.S3)
+		if err != nil {
+			logger.Error("Failed to connect to s3 storage", zap.Error(err))
+			panic("failed to connect s3 storage")
+		}
+		logger.Info("s3 storage initialized", zap.String("endpoint", cfg.S3.Endpoint), zap.String("bucket", cfg.S3.Bucket))
+		return store
+	case DriverLocal, "":
+		return NewLocalStorage(cfg.LocalPath)
+	default:
+		panic("unknown storage driver: " + cfg.Driver)
+	}
 }
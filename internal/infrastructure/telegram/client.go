@@ -0,0 +1,209 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rttask/internal/config"
+	"strings"
+	"time"
+)
+
+// Update обновление Bot API. Нужны только сообщения и нажатия на inline кнопки
+type Update struct {
+	UpdateID      int64          `json:"update_id"`
+	Message       *Message       `json:"message,omitempty"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text,omitempty"`
+}
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username,omitempty"`
+}
+
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    User     `json:"from"`
+	Message *Message `json:"message,omitempty"`
+	Data    string   `json:"data,omitempty"`
+}
+
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+type Client interface {
+	SendMessage(ctx context.Context, chatID int64, text string, markup *InlineKeyboardMarkup) error
+	// EditReplyMarkup заменяет кнопки сообщения, nil убирает их
+	EditReplyMarkup(ctx context.Context, chatID int64, messageID int64, markup *InlineKeyboardMarkup) error
+	AnswerCallbackQuery(ctx context.Context, callbackID string, text string) error
+	// GetUpdates long polling, timeout в секундах
+	GetUpdates(ctx context.Context, offset int64, timeout int) ([]Update, error)
+}
+
+// APIError ответ Bot API с ok=false
+type APIError struct {
+	Code        int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram api error %d: %s", e.Code, e.Description)
+}
+
+// HTTPClient клиент Bot API. baseURL настраивается, чтобы в тестах подставлять локальный сервер
+type HTTPClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewHTTPClient(baseURL string, token string, timeout time.Duration) Client {
+	return &HTTPClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *HTTPClient) SendMessage(ctx context.Context, chatID int64, text string, markup *InlineKeyboardMarkup) error {
+	return c.call(ctx, "sendMessage", map[string]any{
+		"chat_id":      chatID,
+		"text":         text,
+		"reply_markup": markup,
+	}, nil)
+}
+
+func (c *HTTPClient) EditReplyMarkup(ctx context.Context, chatID int64, messageID int64, markup *InlineKeyboardMarkup) error {
+	if markup == nil {
+		markup = &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{}}
+	}
+	return c.call(ctx, "editMessageReplyMarkup", map[string]any{
+		"chat_id":      chatID,
+		"message_id":   messageID,
+		"reply_markup": markup,
+	}, nil)
+}
+
+func (c *HTTPClient) AnswerCallbackQuery(ctx context.Context, callbackID string, text string) error {
+	return c.call(ctx, "answerCallbackQuery", map[string]any{
+		"callback_query_id": callbackID,
+		"text":              text,
+	}, nil)
+}
+
+func (c *HTTPClient) GetUpdates(ctx context.Context, offset int64, timeout int) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         timeout,
+		"allowed_updates": []string{"message", "callback_query"},
+	}, &updates)
+	return updates, err
+}
+
+func (c *HTTPClient) call(ctx context.Context, method string, params map[string]any, result any) error {
+	for key, value := range params {
+		if markup, ok := value.(*InlineKeyboardMarkup); ok && markup == nil {
+			delete(params, key)
+		}
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// в ошибке net/http есть URL, а в нем токен бота
+		return fmt.Errorf("telegram %s: %w", method, redact(err, c.token))
+	}
+	defer resp.Body.Close()
+
+	var apiResp struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("telegram %s: decode response: %w", method, err)
+	}
+	if !apiResp.OK {
+		return &APIError{Code: apiResp.ErrorCode, Description: apiResp.Description}
+	}
+	if result != nil {
+		return json.Unmarshal(apiResp.Result, result)
+	}
+	return nil
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+func redact(err error, token string) error {
+	if token == "" {
+		return err
+	}
+	return &redactedError{msg: strings.ReplaceAll(err.Error(), token, "***"), err: err}
+}
+
+// NewClient клиент Bot API, если бот включен в конфиге, иначе заглушка
+func NewClient(cfg config.Telegram) Client {
+	if !cfg.Enabled {
+		return NewNoopClient()
+	}
+	return NewHTTPClient(cfg.BaseURL, cfg.Token, cfg.TimeoutDuration())
+}
+
+// NoopClient сообщения никуда не уходят, обновлений нет
+type NoopClient struct{}
+
+func NewNoopClient() Client {
+	return &NoopClient{}
+}
+
+func (c *NoopClient) SendMessage(ctx context.Context, chatID int64, text string, markup *InlineKeyboardMarkup) error {
+	return nil
+}
+
+func (c *NoopClient) EditReplyMarkup(ctx context.Context, chatID int64, messageID int64, markup *InlineKeyboardMarkup) error {
+	return nil
+}
+
+func (c *NoopClient) AnswerCallbackQuery(ctx context.Context, callbackID string, text string) error {
+	return nil
+}
+
+func (c *NoopClient) GetUpdates(ctx context.Context, offset int64, timeout int) ([]Update, error) {
+	return nil, nil
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// addressGuard запрещает соединения с внутренними адресами, чтобы вебхук компании не мог
+// обращаться к сервисам внутри сети приложения. Проверка идет при подключении, уже после
+// разрешения имени, поэтому DNS с подменой адреса после сохранения URL ее не обходит
+type addressGuard struct {
+	allowed []netip.Prefix
+}
+
+func newAddressGuard(allowedNetworks []string) (*addressGuard, error) {
+	guard := &addressGuard{}
+	for _, network := range allowedNetworks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook allowed network %q: %w", network, err)
+		}
+		guard.allowed = append(guard.allowed, prefix.Masked())
+	}
+	return guard, nil
+}
+
+// control для net.Dialer.Control, address уже содержит IP, к которому идет подключение
+func (g *addressGuard) control(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !g.permitted(addr.Unmap()) {
+		return fmt.Errorf("webhook address %s is not allowed", addr)
+	}
+	return nil
+}
+
+func (g *addressGuard) permitted(addr netip.Addr) bool {
+	for _, prefix := range g.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return !addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
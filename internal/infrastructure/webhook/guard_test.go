@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAddressGuardPermitted(t *testing.T) {
+	guard, err := newAddressGuard([]string{"10.1.0.0/16", "::1/128"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"127.8.8.8", false},
+		{"10.0.0.5", false},
+		{"10.1.2.3", true}, // разрешенная сеть
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // метаданные облака
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::", false},
+		{"::1", true}, // разрешенная сеть
+		{"fd00::1", false},
+		{"fe80::1", false},
+	}
+	for _, tt := range tests {
+		if got := guard.permitted(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("permitted(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestAddressGuardMappedIPv4(t *testing.T) {
+	guard, _ := newAddressGuard(nil)
+	if err := guard.control("tcp6", "[::ffff:127.0.0.1]:80", nil); err == nil {
+		t.Fatal("IPv4-mapped loopback must be rejected")
+	}
+	if err := guard.control("tcp4", "93.184.216.34:443", nil); err != nil {
+		t.Fatalf("public address rejected: %v", err)
+	}
+}
+
+func TestAddressGuardInvalidNetwork(t *testing.T) {
+	if _, err := newAddressGuard([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("invalid CIDR must fail")
+	}
+}
+
+func TestHTTPSenderRejectsLoopback(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	// localhost разрешается в 127.0.0.1 уже при подключении, как и имя с подменой DNS
+	target := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	req := Request{URL: target, Secret: "secret", Event: "task.created", DeliveryID: "1", Body: []byte(`{}`)}
+
+	_, err := MustNewHTTPSender(time.Second, nil).Send(context.Background(), req)
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("Send to loopback = %v, want address not allowed", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("receiver got %d requests", n)
+	}
+
+	if _, err := MustNewHTTPSender(time.Second, []string{"127.0.0.0/8", "::1/128"}).Send(context.Background(), req); err != nil {
+		t.Fatalf("Send to allowed network: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("receiver got %d requests, want 1", n)
+	}
+}
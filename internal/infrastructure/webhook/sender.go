@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventHeader     = "X-RTTask-Event"
+	DeliveryHeader  = "X-RTTask-Delivery"
+	TimestampHeader = "X-RTTask-Timestamp"
+	SignatureHeader = "X-RTTask-Signature"
+
+	// maxResponseBody сколько ответа получателя читается, остальное не нужно
+	maxResponseBody = 64 << 10
+)
+
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+type Sender interface {
+	// Send возвращает код ответа и ошибку, если доставка не удалась. Успех только 2xx
+	Send(ctx context.Context, req Request) (int, error)
+}
+
+// HTTPSender редиректы не выполняются: POST после редиректа превратился бы в GET без тела.
+// Внутренние адреса запрещены, кроме сетей allowedNetworks
+type HTTPSender struct {
+	client *http.Client
+}
+
+// MustNewHTTPSender allowedNetworks CIDR сетей, куда можно доставлять несмотря на запрет
+// внутренних адресов, например 127.0.0.0/8 для локальной разработки
+func MustNewHTTPSender(timeout time.Duration, allowedNetworks []string) Sender {
+	guard, err := newAddressGuard(allowedNetworks)
+	if err != nil {
+		panic(err)
+	}
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: guard.control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// через прокси проверялся бы адрес прокси, а не получателя
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, req Request) (int, error) {
+	timestamp := time.Now().Unix()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "RTTask-Webhook/1.0")
+	httpReq.Header.Set(EventHeader, req.Event)
+	httpReq.Header.Set(DeliveryHeader, req.DeliveryID)
+	httpReq.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(SignatureHeader, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign подпись "sha256=<hex>" от "<timestamp>.<body>". Метка времени входит в подпись,
+// чтобы перехваченный запрос нельзя было повторить позже
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверка подписи на стороне получателя, tolerance допустимое расхождение часов
+func Verify(secret string, timestamp int64, body []byte, signature string, tolerance time.Duration) bool {
+	age := time.Since(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
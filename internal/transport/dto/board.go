@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"rttask/internal/domain/event"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/service/task"
+	"time"
+)
+
+type BoardParams struct {
+	// Limit задач в каждой колонке
+	Limit int `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type TaskIDRequest struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+// MoveTaskRequest prevTaskId задача сразу над перемещаемой, nextTaskId сразу под ней.
+// Без соседей задача встает в конец колонки
+type MoveTaskRequest struct {
+	Status     string `json:"status" binding:"required"`
+	PrevTaskID uint   `json:"prevTaskId"`
+	NextTaskID uint   `json:"nextTaskId"`
+}
+
+type BoardColumnResponse struct {
+	Status model.Status   `json:"status"`
+	Total  int64          `json:"total"`
+	Tasks  []TaskResponse `json:"tasks"`
+}
+
+type BoardResponse struct {
+	CompanyID uint                  `json:"companyId"`
+	Columns   []BoardColumnResponse `json:"columns"`
+}
+
+func NewBoardResponse(board *task.Board) BoardResponse {
+	columns := make([]BoardColumnResponse, 0, len(board.Columns))
+	for _, column := range board.Columns {
+		columns = append(columns, BoardColumnResponse{
+			Status: column.Status,
+			Total:  column.Total,
+			Tasks:  NewMultiplyTaskResponse(column.Tasks),
+		})
+	}
+	return BoardResponse{CompanyID: board.CompanyID, Columns: columns}
+}
+
+// TaskMovedMessage событие task:moved для сокетов
+type TaskMovedMessage struct {
+	Task       TaskResponse `json:"task"`
+	FromStatus model.Status `json:"fromStatus"`
+	PrevTaskID uint         `json:"prevTaskId,omitempty"`
+	NextTaskID uint         `json:"nextTaskId,omitempty"`
+	ActorID    uint         `json:"actorId"`
+}
+
+func NewTaskMovedMessage(e event.Event, payload event.TaskMovedPayload) TaskMovedMessage {
+	return TaskMovedMessage{
+		Task:       NewTaskResponse(payload.Task),
+		FromStatus: payload.FromStatus,
+		PrevTaskID: payload.PrevTaskID,
+		NextTaskID: payload.NextTaskID,
+		ActorID:    e.ActorID,
+	}
+}
+
+// BlockerCompletedMessage событие task:blocker-completed исполнителю ожидающей задачи
+type BlockerCompletedMessage struct {
+	Task         TaskLinkResponse `json:"task"`
+	Blocker      TaskLinkResponse `json:"blocker"`
+	OpenBlockers int              `json:"openBlockers"`
+	ActorID      uint             `json:"actorId"`
+}
+
+func NewBlockerCompletedMessage(e event.Event, payload event.BlockerCompletedPayload) BlockerCompletedMessage {
+	return BlockerCompletedMessage{
+		Task:         TaskLinkResponse{ID: payload.Task.ID, Title: payload.Task.Title, Status: payload.Task.Status},
+		Blocker:      TaskLinkResponse{ID: payload.Blocker.ID, Title: payload.Blocker.Title, Status: payload.Blocker.Status},
+		OpenBlockers: payload.OpenBlockers,
+		ActorID:      e.ActorID,
+	}
+}
+
+// DeadlineMessage событие task:deadline исполнителям и автору задачи. aheadMinutes 0 для просроченной задачи
+type DeadlineMessage struct {
+	Task         TaskLinkResponse `json:"task"`
+	DeadlineAt   time.Time        `json:"deadlineAt"`
+	AheadMinutes int              `json:"aheadMinutes"`
+	Overdue      bool             `json:"overdue"`
+	Escalated    bool             `json:"escalated"`
+}
+
+func NewDeadlineMessage(payload event.DeadlinePayload) DeadlineMessage {
+	return DeadlineMessage{
+		Task:         TaskLinkResponse{ID: payload.Task.ID, Title: payload.Task.Title, Status: payload.Task.Status},
+		DeadlineAt:   payload.Task.DeadlineAt,
+		AheadMinutes: int(payload.Ahead / time.Minute),
+		Overdue:      payload.Ahead == 0,
+		Escalated:    payload.Escalated,
+	}
+}
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type CommentIDRequest struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+// CommentRequest в content можно упоминать участников компании: @email или @часть-email-до-@
+type CommentRequest struct {
+	Content string   `json:"content" binding:"required"`
+	FileIDs []string `json:"fileIds"`
+}
+
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// MentionResponse упоминание в тексте. offset и length в UTF-16 единицах, как индексы строк
+// в JavaScript, и включают @
+type MentionResponse struct {
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	UserID uint   `json:"userId"`
+	Name   string `json:"name"`
+}
+
+type CommentResponse struct {
+	ID        uint              `json:"id"`
+	TaskID    uint              `json:"taskId"`
+	UserID    uint              `json:"userId"`
+	Content   string            `json:"content"`
+	Files     []*model.File     `json:"files"`
+	Mentions  []MentionResponse `json:"mentions"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+func NewMentionsResponse(mentions []*model.Mention) []MentionResponse {
+	response := make([]MentionResponse, 0, len(mentions))
+	for _, mention := range mentions {
+		item := MentionResponse{
+			Offset: mention.Offset,
+			Length: mention.Length,
+			UserID: mention.UserID,
+		}
+		if mention.User != nil {
+			item.Name = mention.User.FullName()
+		}
+		response = append(response, item)
+	}
+	return response
+}
+
+func NewCommentResponse(comment *model.Comment) CommentResponse {
+	response := CommentResponse{
+		ID:        comment.ID,
+		TaskID:    comment.TaskID,
+		UserID:    comment.UserID,
+		Content:   comment.Content,
+		Files:     comment.Files,
+		Mentions:  NewMentionsResponse(comment.Mentions),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+	if response.Files == nil {
+		response.Files = []*model.File{}
+	}
+	return response
+}
+
+func NewMultiplyCommentResponse(comments []*model.Comment) []CommentResponse {
+	response := make([]CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		response = append(response, NewCommentResponse(comment))
+	}
+	return response
+}
@@ -1,8 +1,10 @@
 package dto
 
 import (
+	"cmp"
 	"mime/multipart"
 	"rttask/internal/domain/model"
+	"slices"
 )
 
 type CompanyRequest struct {
@@ -32,3 +34,47 @@ func NewMultiplyCompanyResponse(companies []*model.Company) []CompanyResponse {
 	}
 	return response
 }
+
+type CompanyIDRequest struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type UserStorageUsage struct {
+	UserID uint  `json:"userId"`
+	Bytes  int64 `json:"bytes"`
+}
+
+type StorageUsageResponse struct {
+	CompanyID    uint               `json:"companyId"`
+	TotalBytes   int64              `json:"totalBytes"`
+	QuotaBytes   int64              `json:"quotaBytes"`
+	ByEntityType map[string]int64   `json:"byEntityType"`
+	ByUser       []UserStorageUsage `json:"byUser"`
+}
+
+func NewStorageUsageResponse(usage *model.StorageUsage) StorageUsageResponse {
+	byEntityType := map[string]int64{
+		string(model.FileOwnerTask):    0,
+		string(model.FileOwnerComment): 0,
+		string(model.FileOwnerCompany): 0,
+	}
+	for entityType, bytes := range usage.ByEntityType {
+		byEntityType[string(entityType)] = bytes
+	}
+
+	byUser := make([]UserStorageUsage, 0, len(usage.ByUploader))
+	for userID, bytes := range usage.ByUploader {
+		byUser = append(byUser, UserStorageUsage{UserID: userID, Bytes: bytes})
+	}
+	slices.SortFunc(byUser, func(a, b UserStorageUsage) int {
+		return cmp.Or(cmp.Compare(b.Bytes, a.Bytes), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	return StorageUsageResponse{
+		CompanyID:    usage.CompanyID,
+		TotalBytes:   usage.TotalBytes,
+		QuotaBytes:   usage.QuotaBytes,
+		ByEntityType: byEntityType,
+		ByUser:       byUser,
+	}
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type NotificationParams struct {
+	PaginationRequest
+	Unread bool `form:"unread"`
+}
+
+// MarkReadRequest все уведомления отмечаются через /notifications/read-all
+type MarkReadRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100"`
+}
+
+type NotificationResponse struct {
+	ID        uint                   `json:"id"`
+	Type      model.NotificationType `json:"type"`
+	CompanyID uint                   `json:"companyId"`
+	TaskID    uint                   `json:"taskId"`
+	ActorID   uint                   `json:"actorId,omitempty"`
+	Payload   map[string]any         `json:"payload"`
+	Read      bool                   `json:"read"`
+	ReadAt    *time.Time             `json:"readAt,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+type NotificationPageResponse struct {
+	PaginationResponse[NotificationResponse]
+	Unread int64 `json:"unread"`
+}
+
+type UnreadResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+// NotificationMessage событие notification:new в комнату получателя
+type NotificationMessage struct {
+	Notification NotificationResponse `json:"notification"`
+	Unread       int64                `json:"unread"`
+}
+
+func NewNotificationResponse(notification *model.Notification) NotificationResponse {
+	response := NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		CompanyID: notification.CompanyID,
+		TaskID:    notification.TaskID,
+		ActorID:   notification.ActorID,
+		Payload:   notification.Payload,
+		Read:      notification.Read,
+		CreatedAt: notification.CreatedAt,
+	}
+	if response.Payload == nil {
+		response.Payload = map[string]any{}
+	}
+	if !notification.ReadAt.IsZero() {
+		response.ReadAt = &notification.ReadAt
+	}
+	return response
+}
+
+func NewMultiplyNotificationResponse(notifications []*model.Notification) []NotificationResponse {
+	responses := make([]NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, NewNotificationResponse(notification))
+	}
+	return responses
+}
+
+// EmailSettingsRequest режимы по типам уведомлений: instant, digest или off
+type EmailSettingsRequest struct {
+	Locale string                                     `json:"locale" binding:"omitempty,oneof=ru en"`
+	Modes  map[model.NotificationType]model.EmailMode `json:"modes"`
+}
+
+type EmailSettingsResponse struct {
+	Locale string                                     `json:"locale,omitempty"`
+	Modes  map[model.NotificationType]model.EmailMode `json:"modes"`
+}
+
+func NewEmailSettingsResponse(settings *model.EmailSettings) EmailSettingsResponse {
+	return EmailSettingsResponse{
+		Locale: settings.Locale,
+		Modes:  settings.Modes,
+	}
+}
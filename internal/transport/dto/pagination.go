@@ -3,8 +3,17 @@ package dto
 import "math"
 
 type PaginationRequest struct {
-	Page     int `form:"page"`
-	PageSize int `form:"pageSize"`
+	Page     int `form:"page" binding:"min=1"`
+	PageSize int `form:"pageSize" binding:"min=1,max=100"`
+
+	// Paging cursor включает курсорную пагинацию, page тогда игнорируется.
+	// Непустой Cursor включает ее автоматически
+	Paging string `form:"paging" binding:"omitempty,oneof=offset cursor"`
+	Cursor string `form:"cursor"`
+}
+
+func (p *PaginationRequest) IsCursor() bool {
+	return p.Paging == "cursor" || p.Cursor != ""
 }
 
 func (p *PaginationRequest) Offset() int {
@@ -21,11 +30,13 @@ func (p *PaginationRequest) Default() {
 }
 
 type PaginationResponse[T any] struct {
-	Items      []T   `json:"items"`
-	TotalPages int   `json:"totalPages"`
-	Total      int64 `json:"total"`
-	HasNext    bool  `json:"hasNext"`
-	HasPrev    bool  `json:"hasPrev"`
+	Items      []T    `json:"items"`
+	TotalPages int    `json:"totalPages"`
+	Total      int64  `json:"total"`
+	HasNext    bool   `json:"hasNext"`
+	HasPrev    bool   `json:"hasPrev"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
 }
 
 func NewPaginationResponse[T any](items []T, params PaginationRequest, total int64) PaginationResponse[T] {
@@ -42,3 +53,15 @@ func NewPaginationResponse[T any](items []T, params PaginationRequest, total int
 		HasPrev:    params.Page > 1,
 	}
 }
+
+// NewCursorPaginationResponse ответ курсорной пагинации, totalPages не имеет смысла и не заполняется
+func NewCursorPaginationResponse[T any](items []T, nextCursor string, prevCursor string, total int64) PaginationResponse[T] {
+	return PaginationResponse[T]{
+		Items:      items,
+		Total:      total,
+		HasNext:    nextCursor != "",
+		HasPrev:    prevCursor != "",
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}
+}
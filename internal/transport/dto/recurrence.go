@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"rttask/internal/domain/model"
+	"time"
+)
+
+// RecurrenceRequest rrule подмножество RFC 5545, например "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// startAt начало первого повторения, durationMinutes срок каждой задачи от ее начала
+type RecurrenceRequest struct {
+	Title           string    `json:"title" binding:"required"`
+	Description     string    `json:"description" binding:"required"`
+	Priority        uint      `json:"priority" binding:"required"`
+	ExecutorID      uint      `json:"executorId" binding:"required"`
+	ExecutorIDs     []uint    `json:"executorIds"`
+	WatcherIDs      []uint    `json:"watcherIds"`
+	CompanyID       uint      `json:"companyId" binding:"required"`
+	RRule           string    `json:"rrule" binding:"required"`
+	Timezone        string    `json:"timezone"`
+	StartAt         time.Time `json:"startAt" binding:"required"`
+	DurationMinutes int       `json:"durationMinutes" binding:"required,min=1"`
+}
+
+type RecurrenceResponse struct {
+	ID              uint       `json:"id"`
+	CompanyID       uint       `json:"companyId"`
+	CreatorID       uint       `json:"creatorId"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Priority        uint       `json:"priority"`
+	ExecutorID      uint       `json:"executorId"`
+	ExecutorIDs     []uint     `json:"executorIds"`
+	WatcherIDs      []uint     `json:"watcherIds"`
+	RRule           string     `json:"rrule"`
+	Timezone        string     `json:"timezone"`
+	StartAt         time.Time  `json:"startAt"`
+	DurationMinutes int        `json:"durationMinutes"`
+	GeneratedUntil  *time.Time `json:"generatedUntil,omitempty"`
+	Generated       int        `json:"generated"`
+	Active          bool       `json:"active"`
+	LastError       string     `json:"lastError,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+func NewRecurrenceResponse(recurrence *model.Recurrence) RecurrenceResponse {
+	response := RecurrenceResponse{
+		ID:              recurrence.ID,
+		CompanyID:       recurrence.CompanyID,
+		CreatorID:       recurrence.CreatorID,
+		Title:           recurrence.Title,
+		Description:     recurrence.Description,
+		Priority:        recurrence.Priority,
+		ExecutorID:      recurrence.ExecutorID,
+		ExecutorIDs:     recurrence.ExecutorIDs,
+		WatcherIDs:      recurrence.WatcherIDs,
+		RRule:           recurrence.RRule,
+		Timezone:        recurrence.Timezone,
+		StartAt:         recurrence.StartAt,
+		DurationMinutes: int(recurrence.Duration / time.Minute),
+		Generated:       recurrence.Generated,
+		Active:          recurrence.Active,
+		LastError:       recurrence.LastError,
+		CreatedAt:       recurrence.CreatedAt,
+	}
+	if response.ExecutorIDs == nil {
+		response.ExecutorIDs = []uint{}
+	}
+	if response.WatcherIDs == nil {
+		response.WatcherIDs = []uint{}
+	}
+	if !recurrence.GeneratedUntil.IsZero() {
+		response.GeneratedUntil = &recurrence.GeneratedUntil
+	}
+	return response
+}
+
+func NewMultiplyRecurrenceResponse(recurrences []*model.Recurrence) []RecurrenceResponse {
+	responses := make([]RecurrenceResponse, 0, len(recurrences))
+	for _, recurrence := range recurrences {
+		responses = append(responses, NewRecurrenceResponse(recurrence))
+	}
+	return responses
+}
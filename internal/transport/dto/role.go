@@ -9,6 +9,11 @@ type RoleRequest struct {
 	Permissions []string `json:"permissions" binding:"required"`
 }
 
+type AssignRolesRequest struct {
+	UserID   uint   `json:"userId" binding:"required"`
+	RolesIDs []uint `json:"rolesIds" binding:"required"`
+}
+
 // RESPONSE
 
 type RoleResponse struct {
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"html"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/service/search"
+	"strings"
+)
+
+type SearchRequest struct {
+	Query     string   `form:"q" binding:"required"`
+	CompanyID uint     `form:"companyId"`
+	Types     []string `form:"type"`
+	Limit     int      `form:"limit"`
+}
+
+type SearchHitResponse struct {
+	EntityType model.SearchEntityType `json:"entityType"`
+	ID         uint                   `json:"id"`
+	TaskID     uint                   `json:"taskId"`
+	CompanyID  uint                   `json:"companyId"`
+	Status     model.Status           `json:"status"`
+	// Title и Snippet экранированный HTML, совпадения обернуты в <mark>
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+type SearchGroupResponse struct {
+	Total int64               `json:"total"`
+	Items []SearchHitResponse `json:"items"`
+}
+
+type SearchResponse struct {
+	Query  string                         `json:"query"`
+	Groups map[string]SearchGroupResponse `json:"groups"`
+}
+
+func NewSearchResponse(query string, result *search.SearchResult) SearchResponse {
+	groups := make(map[string]SearchGroupResponse, 2)
+	if result.Tasks != nil {
+		groups[string(model.SearchEntityTask)] = newSearchGroupResponse(result.Tasks, result.TasksTotal)
+	}
+	if result.Comments != nil {
+		groups[string(model.SearchEntityComment)] = newSearchGroupResponse(result.Comments, result.CommentsTotal)
+	}
+	return SearchResponse{Query: query, Groups: groups}
+}
+
+func newSearchGroupResponse(hits []*model.SearchHit, total int64) SearchGroupResponse {
+	items := make([]SearchHitResponse, 0, len(hits))
+	for _, hit := range hits {
+		items = append(items, SearchHitResponse{
+			EntityType: hit.EntityType,
+			ID:         hit.ID,
+			TaskID:     hit.TaskID,
+			CompanyID:  hit.CompanyID,
+			Status:     hit.Status,
+			Title:      highlightHTML(hit.Title),
+			Snippet:    highlightHTML(hit.Snippet),
+			Rank:       hit.Rank,
+		})
+	}
+	return SearchGroupResponse{Total: total, Items: items}
+}
+
+// highlightHTML экранирует пользовательский текст и только после этого ставит теги подсветки
+func highlightHTML(s string) string {
+	return strings.NewReplacer(
+		model.HighlightStart, "<mark>",
+		model.HighlightStop, "</mark>",
+	).Replace(html.EscapeString(s))
+}
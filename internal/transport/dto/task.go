@@ -2,12 +2,28 @@ package dto
 
 import (
 	"mime/multipart"
+	"rttask/internal/domain/model"
 	"time"
 )
 
+// TaskParams параметры списка задач. Повторяющиеся параметры (?status=a&status=b) объединяются через ИЛИ
 type TaskParams struct {
 	PaginationRequest
-	CompanyID int `json:"companyId"`
+	CompanyIDs   []uint     `form:"companyId"`
+	ExecutorIDs  []uint     `form:"executorId"`
+	WatcherIDs   []uint     `form:"watcherId"`
+	CreatorIDs   []uint     `form:"creatorId"`
+	Statuses     []string   `form:"status"`
+	Priorities   []uint     `form:"priority"`
+	ParentIDs    []uint     `form:"parentId"`
+	StartFrom    *time.Time `form:"startFrom"`
+	StartTo      *time.Time `form:"startTo"`
+	DeadlineFrom *time.Time `form:"deadlineFrom"`
+	DeadlineTo   *time.Time `form:"deadlineTo"`
+	Overdue      *bool      `form:"overdue"`
+	Search       string     `form:"search"`
+	// Sort поля через запятую, минус перед полем для сортировки по убыванию: "-priority,deadlineAt"
+	Sort string `form:"sort"`
 }
 
 type TaskRequest struct {
@@ -19,14 +35,180 @@ type TaskRequest struct {
 	StartAt     time.Time               `form:"startAt" binding:"required"`
 	DeadlineAt  time.Time               `form:"deadlineAt" binding:"required"`
 	Files       []*multipart.FileHeader `form:"files"`
+	FileIDs     []string                `form:"fileIds"`
+	ParentID    uint                    `form:"parentId"`
+	// ExecutorIDs дополнительные исполнители к executorId
+	ExecutorIDs []uint `form:"executorIds"`
+	WatcherIDs  []uint `form:"watcherIds"`
 }
 
 type TaskResponse struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Priority    uint      `json:"priority"`
-	ExecutorID  uint      `json:"executorId"`
-	CompanyID   uint      `json:"companyId"`
-	StartAt     time.Time `json:"startAt"`
-	DeadlineAt  time.Time `json:"deadlineAt"`
+	ID          uint          `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Status      model.Status  `json:"status"`
+	Priority    uint          `json:"priority"`
+	CreatorID   uint          `json:"creatorId"`
+	ExecutorID  uint          `json:"executorId"`
+	CompanyID   uint          `json:"companyId"`
+	StartAt     time.Time     `json:"startAt"`
+	DeadlineAt  time.Time     `json:"deadlineAt"`
+	CompletedAt *time.Time    `json:"completedAt,omitempty"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	Rank        string        `json:"rank"`
+	ParentID    *uint         `json:"parentId,omitempty"`
+	Files       []*model.File `json:"files"`
+	// RecurrenceID и OccurrenceAt у задач, созданных по шаблону повторения
+	RecurrenceID *uint      `json:"recurrenceId,omitempty"`
+	OccurrenceAt *time.Time `json:"occurrenceAt,omitempty"`
+
+	Subtasks  SubtaskProgressResponse `json:"subtasks"`
+	Checklist []ChecklistItemResponse `json:"checklist"`
+	BlockedBy []TaskLinkResponse      `json:"blockedBy"`
+	Blocks    []TaskLinkResponse      `json:"blocks"`
+	Executors []ExecutorResponse      `json:"executors"`
+	Watchers  []uint                  `json:"watchers"`
+	// Mentions упоминания в description
+	Mentions []MentionResponse `json:"mentions"`
+}
+
+type ExecutorResponse struct {
+	UserID         uint       `json:"userId"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+}
+
+// AssigneesRequest первый из executorIds становится основным исполнителем
+type AssigneesRequest struct {
+	ExecutorIDs []uint `json:"executorIds" binding:"required,min=1"`
+	WatcherIDs  []uint `json:"watcherIds"`
+}
+
+type TaskLinkResponse struct {
+	ID     uint         `json:"id"`
+	Title  string       `json:"title"`
+	Status model.Status `json:"status"`
+}
+
+type DependencyRequest struct {
+	BlockerID uint `json:"blockerId" binding:"required"`
+}
+
+type DependencyURI struct {
+	ID        uint `uri:"id" binding:"required"`
+	BlockerID uint `uri:"blockerId" binding:"required"`
+}
+
+type SubtaskProgressResponse struct {
+	Total     int64 `json:"total"`
+	Completed int64 `json:"completed"`
+	Percent   int   `json:"percent"`
+}
+
+type ChecklistItemResponse struct {
+	ID       uint       `json:"id"`
+	Title    string     `json:"title"`
+	Done     bool       `json:"done"`
+	DoneByID uint       `json:"doneById,omitempty"`
+	DoneAt   *time.Time `json:"doneAt,omitempty"`
+}
+
+// SetParentRequest parentId 0 или null делает задачу корневой
+type SetParentRequest struct {
+	ParentID uint `json:"parentId"`
+}
+
+type ChecklistItemRequest struct {
+	Title string `json:"title" binding:"required,max=500"`
+}
+
+// UpdateChecklistItemRequest переданные поля меняются, остальные остаются как есть
+type UpdateChecklistItemRequest struct {
+	Title *string `json:"title" binding:"omitempty,max=500"`
+	Done  *bool   `json:"done"`
+}
+
+type ChecklistItemURI struct {
+	ID     uint `uri:"id" binding:"required"`
+	ItemID uint `uri:"itemId" binding:"required"`
+}
+
+func NewChecklistItemResponse(item *model.ChecklistItem) ChecklistItemResponse {
+	response := ChecklistItemResponse{
+		ID:       item.ID,
+		Title:    item.Title,
+		Done:     item.Done,
+		DoneByID: item.DoneByID,
+	}
+	if !item.DoneAt.IsZero() {
+		response.DoneAt = &item.DoneAt
+	}
+	return response
+}
+
+func NewTaskResponse(task *model.Task) TaskResponse {
+	response := TaskResponse{
+		ID:           task.ID,
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       task.Status,
+		Priority:     task.Priority,
+		CreatorID:    task.CreatorID,
+		ExecutorID:   task.ExecutorID,
+		CompanyID:    task.CompanyID,
+		StartAt:      task.StartAt,
+		DeadlineAt:   task.DeadlineAt,
+		CreatedAt:    task.CreatedAt,
+		Rank:         task.BoardRank,
+		ParentID:     task.ParentID,
+		Files:        task.Files,
+		RecurrenceID: task.RecurrenceID,
+		OccurrenceAt: task.OccurrenceAt,
+		Subtasks: SubtaskProgressResponse{
+			Total:     task.Subtasks.Total,
+			Completed: task.Subtasks.Completed,
+			Percent:   task.Subtasks.Percent(),
+		},
+		Checklist: make([]ChecklistItemResponse, 0, len(task.Checklist)),
+	}
+	for _, item := range task.Checklist {
+		response.Checklist = append(response.Checklist, NewChecklistItemResponse(item))
+	}
+	response.BlockedBy = newTaskLinksResponse(task.BlockedBy)
+	response.Blocks = newTaskLinksResponse(task.Blocks)
+	response.Mentions = NewMentionsResponse(task.Mentions)
+
+	for _, executorID := range task.ExecutorIDs() {
+		executor := ExecutorResponse{UserID: executorID}
+		if assignee := task.Assignee(executorID); assignee != nil && assignee.Acknowledged() {
+			executor.AcknowledgedAt = &assignee.AcknowledgedAt
+		}
+		response.Executors = append(response.Executors, executor)
+	}
+	response.Watchers = task.WatcherIDs()
+	if response.Watchers == nil {
+		response.Watchers = []uint{}
+	}
+	if !task.CompletedAt.IsZero() {
+		response.CompletedAt = &task.CompletedAt
+	}
+	if response.Files == nil {
+		response.Files = []*model.File{}
+	}
+	return response
+}
+
+func newTaskLinksResponse(links []model.TaskLink) []TaskLinkResponse {
+	response := make([]TaskLinkResponse, 0, len(links))
+	for _, link := range links {
+		response = append(response, TaskLinkResponse{ID: link.ID, Title: link.Title, Status: link.Status})
+	}
+	return response
+}
+
+func NewMultiplyTaskResponse(tasks []*model.Task) []TaskResponse {
+	response := make([]TaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		response = append(response, NewTaskResponse(task))
+	}
+	return response
 }
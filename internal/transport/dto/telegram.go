@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"rttask/internal/domain/model"
+	"time"
+)
+
+// TelegramLinkCodeResponse code отправляется боту командой /link, link открывает бота сразу с кодом
+type TelegramLinkCodeResponse struct {
+	Code      string    `json:"code"`
+	Link      string    `json:"link,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type TelegramLinkResponse struct {
+	Linked   bool       `json:"linked"`
+	Username string     `json:"username,omitempty"`
+	LinkedAt *time.Time `json:"linkedAt,omitempty"`
+}
+
+func NewTelegramLinkResponse(link *model.TelegramLink) TelegramLinkResponse {
+	if link == nil {
+		return TelegramLinkResponse{}
+	}
+	return TelegramLinkResponse{
+		Linked:   true,
+		Username: link.Username,
+		LinkedAt: &link.CreatedAt,
+	}
+}
@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/valueobject"
+	"time"
+)
+
+// TemplateRequest в title и description допускаются плейсхолдеры {{name}}. Встроенные: date, deadline,
+// company, executor. fileIds файлы, загруженные через /uploads
+type TemplateRequest struct {
+	CompanyID            uint     `json:"companyId"`
+	Name                 string   `json:"name" binding:"required,max=255"`
+	Title                string   `json:"title" binding:"required"`
+	Description          string   `json:"description"`
+	Priority             uint     `json:"priority"`
+	ExecutorID           uint     `json:"executorId"`
+	Checklist            []string `json:"checklist" binding:"max=100"`
+	DeadlineAfterMinutes int      `json:"deadlineAfterMinutes" binding:"required,min=1"`
+	FileIDs              []string `json:"fileIds"`
+}
+
+// FromTemplateRequest executorId и priority заменяют значения шаблона, values значения плейсхолдеров
+type FromTemplateRequest struct {
+	StartAt     time.Time         `json:"startAt" binding:"required"`
+	ExecutorID  uint              `json:"executorId"`
+	ExecutorIDs []uint            `json:"executorIds"`
+	WatcherIDs  []uint            `json:"watcherIds"`
+	ParentID    uint              `json:"parentId"`
+	Priority    uint              `json:"priority"`
+	Values      map[string]string `json:"values"`
+}
+
+type TemplateResponse struct {
+	ID                   uint          `json:"id"`
+	CompanyID            uint          `json:"companyId"`
+	CreatorID            uint          `json:"creatorId"`
+	Name                 string        `json:"name"`
+	Title                string        `json:"title"`
+	Description          string        `json:"description"`
+	Priority             uint          `json:"priority"`
+	ExecutorID           uint          `json:"executorId,omitempty"`
+	Checklist            []string      `json:"checklist"`
+	Files                []*model.File `json:"files"`
+	DeadlineAfterMinutes int           `json:"deadlineAfterMinutes"`
+	// Placeholders плейсхолдеры из title и description
+	Placeholders []string  `json:"placeholders"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func NewTemplateResponse(template *model.TaskTemplate) TemplateResponse {
+	response := TemplateResponse{
+		ID:                   template.ID,
+		CompanyID:            template.CompanyID,
+		CreatorID:            template.CreatorID,
+		Name:                 template.Name,
+		Title:                template.Title,
+		Description:          template.Description,
+		Priority:             template.Priority,
+		ExecutorID:           template.ExecutorID,
+		Checklist:            template.Checklist,
+		Files:                template.Files,
+		DeadlineAfterMinutes: int(template.DeadlineAfter / time.Minute),
+		Placeholders:         valueobject.Placeholders(template.Title, template.Description),
+		CreatedAt:            template.CreatedAt,
+		UpdatedAt:            template.UpdatedAt,
+	}
+	if response.Checklist == nil {
+		response.Checklist = []string{}
+	}
+	if response.Files == nil {
+		response.Files = []*model.File{}
+	}
+	if response.Placeholders == nil {
+		response.Placeholders = []string{}
+	}
+	return response
+}
+
+func NewMultiplyTemplateResponse(templates []*model.TaskTemplate) []TemplateResponse {
+	responses := make([]TemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		responses = append(responses, NewTemplateResponse(template))
+	}
+	return responses
+}
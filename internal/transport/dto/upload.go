@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"rttask/internal/domain/model"
+	"strings"
+	"time"
+)
+
+type UploadResponse struct {
+	ID        string             `json:"id"`
+	FileName  string             `json:"fileName"`
+	Length    int64              `json:"length"`
+	Offset    int64              `json:"offset"`
+	Status    model.UploadStatus `json:"status"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+	File      *model.File        `json:"file,omitempty"`
+}
+
+func NewUploadResponse(upload *model.Upload) UploadResponse {
+	return UploadResponse{
+		ID:        upload.ID,
+		FileName:  upload.FileName,
+		Length:    upload.Length,
+		Offset:    upload.Offset,
+		Status:    upload.Status,
+		ExpiresAt: upload.ExpiresAt,
+		File:      upload.File,
+	}
+}
+
+// ParseUploadMetadata разбирает заголовок Upload-Metadata: пары "ключ base64(значение)" через запятую
+func ParseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, fmt.Errorf("invalid Upload-Metadata")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Upload-Metadata value for %s", key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
@@ -0,0 +1,115 @@
+package dto
+
+import (
+	"encoding/json"
+	"rttask/internal/domain/model"
+	"time"
+)
+
+type WebhookIDRequest struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type DeliveryIDRequest struct {
+	ID         uint `uri:"id" binding:"required"`
+	DeliveryID uint `uri:"deliveryId" binding:"required"`
+}
+
+// WebhookRequest secret не обязателен: при создании он генерируется, при изменении остается прежним
+type WebhookRequest struct {
+	CompanyID uint     `json:"companyId"`
+	URL       string   `json:"url" binding:"required"`
+	Events    []string `json:"events" binding:"required,min=1"`
+	Secret    string   `json:"secret"`
+	Active    *bool    `json:"active"`
+}
+
+// WebhookResponse secret отдается только при создании
+type WebhookResponse struct {
+	ID        uint      `json:"id"`
+	CompanyID uint      `json:"companyId"`
+	CreatorID uint      `json:"creatorId"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type DeliveryAttemptResponse struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID            uint                      `json:"id"`
+	WebhookID     uint                      `json:"webhookId"`
+	EventID       string                    `json:"eventId"`
+	Event         string                    `json:"event"`
+	Payload       json.RawMessage           `json:"payload" swaggertype:"object"`
+	Status        model.DeliveryStatus      `json:"status"`
+	AttemptCount  int                       `json:"attemptCount"`
+	NextAttemptAt *time.Time                `json:"nextAttemptAt,omitempty"`
+	DeliveredAt   *time.Time                `json:"deliveredAt,omitempty"`
+	Attempts      []DeliveryAttemptResponse `json:"attempts"`
+	CreatedAt     time.Time                 `json:"createdAt"`
+}
+
+func NewWebhookResponse(webhook *model.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        webhook.ID,
+		CompanyID: webhook.CompanyID,
+		CreatorID: webhook.CreatorID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+func NewMultiplyWebhookResponse(webhooks []*model.Webhook) []WebhookResponse {
+	responses := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		responses = append(responses, NewWebhookResponse(webhook))
+	}
+	return responses
+}
+
+func NewWebhookDeliveryResponse(delivery *model.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:           delivery.ID,
+		WebhookID:    delivery.WebhookID,
+		EventID:      delivery.EventID,
+		Event:        delivery.Event,
+		Payload:      delivery.Payload,
+		Status:       delivery.Status,
+		AttemptCount: delivery.AttemptCount,
+		DeliveredAt:  delivery.DeliveredAt,
+		Attempts:     make([]DeliveryAttemptResponse, 0, len(delivery.Attempts)),
+		CreatedAt:    delivery.CreatedAt,
+	}
+	if delivery.Status == model.DeliveryPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	for _, attempt := range delivery.Attempts {
+		response.Attempts = append(response.Attempts, DeliveryAttemptResponse{
+			At:         attempt.At,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.Duration.Milliseconds(),
+		})
+	}
+	return response
+}
+
+func NewMultiplyWebhookDeliveryResponse(deliveries []*model.WebhookDelivery) []WebhookDeliveryResponse {
+	responses := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responses = append(responses, NewWebhookDeliveryResponse(delivery))
+	}
+	return responses
+}
@@ -102,7 +102,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 	traceID := response.GetTraceID(c)
 
-	if err := c.ShouldBind(&req); err != nil {
+	if err := bindMultipart(c, &req, file.CompanyProfile); err != nil {
 		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
 		problem.Send(c)
 		return
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/service/comment"
+	"rttask/internal/domain/valueobject"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/dto"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CommentHandler struct {
+	service *comment.CommentService
+	mapper  *response.ErrorMapper
+	logger  *zap.Logger
+}
+
+func InitCommentHandler(g *gin.RouterGroup, service *comment.CommentService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &CommentHandler{
+		service: service,
+		mapper:  mapper,
+		logger:  logger,
+	}
+	r := g.Group("/comment")
+	{
+		r.PUT("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.UpdateComment)
+		r.DELETE("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.DeleteComment)
+	}
+	g.POST("/task/:id/comments", middleware.AuthMiddleware(manager, logger, mapper), h.CreateComment)
+	g.GET("/task/:id/comments", middleware.AuthMiddleware(manager, logger, mapper), h.GetComments)
+}
+
+// CreateComment godoc
+// @Summary Add comment
+// @Description Comment on a task. "@email" or "@name" (the part of the e-mail before @) mentions a company member, who gets a notification. Mentions of non-members are rejected, the response lists mention spans for rendering links
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body dto.CommentRequest true "Comment"
+// @Success 201 {object} dto.CommentResponse "Created comment"
+// @Failure 400 {object} response.ProblemDetail "Empty comment or unknown mentions"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no comment:create permission"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Router /task/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var req dto.CommentRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.CreateComment(c.Request.Context(), comment.CommentInput{
+		TaskID:  uri.ID,
+		Content: req.Content,
+		FileIDs: req.FileIDs,
+	}, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewCommentResponse(result))
+}
+
+// GetComments godoc
+// @Summary List task comments
+// @Description Comments oldest first, each with its mention spans
+// @Tags comment
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size (max 100)"
+// @Param paging query string false "offset (default) or cursor"
+// @Param cursor query string false "nextCursor or prevCursor from the previous response, enables cursor paging"
+// @Success 200 {object} dto.PaginationResponse[dto.CommentResponse] "Comments"
+// @Failure 400 {object} response.ProblemDetail "Invalid cursor"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no comment:view permission"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Router /task/{id}/comments [get]
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var params dto.PaginationRequest
+	params.Default()
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	validParams := valueobject.NewPaginationParams(params.Page, params.PageSize)
+	if params.IsCursor() {
+		var cursor *valueobject.Cursor
+		if params.Cursor != "" {
+			decoded, err := valueobject.DecodeCursor(params.Cursor)
+			if err != nil {
+				problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+				problem.Send(c)
+				return
+			}
+			cursor = decoded
+		}
+		validParams = valueobject.NewCursorPaginationParams(cursor, params.PageSize)
+	}
+
+	page, err := h.service.ListComments(c.Request.Context(), uri.ID, validParams, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	items := dto.NewMultiplyCommentResponse(page.Items)
+	if params.IsCursor() {
+		c.JSON(http.StatusOK, dto.NewCursorPaginationResponse(items, page.NextCursor, page.PrevCursor, page.Total))
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewPaginationResponse(items, params, page.Total))
+}
+
+// UpdateComment godoc
+// @Summary Edit comment
+// @Description Only the author can edit the comment. Notifications are sent only to users who were not mentioned before
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Comment ID"
+// @Param request body dto.UpdateCommentRequest true "New text"
+// @Success 200 {object} dto.CommentResponse "Updated comment"
+// @Failure 400 {object} response.ProblemDetail "Empty comment or unknown mentions"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not the author or no comment:update permission"
+// @Failure 404 {object} response.ProblemDetail "Comment not found"
+// @Router /comment/{id} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	var uri dto.CommentIDRequest
+	var req dto.UpdateCommentRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.UpdateComment(c.Request.Context(), uri.ID, req.Content, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewCommentResponse(result))
+}
+
+// DeleteComment godoc
+// @Summary Delete comment
+// @Description Only the author can delete the comment
+// @Tags comment
+// @Security BearerAuth
+// @Param id path int true "Comment ID"
+// @Success 204 "Deleted"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not the author or no comment:delete permission"
+// @Failure 404 {object} response.ProblemDetail "Comment not found"
+// @Router /comment/{id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	var uri dto.CommentIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	if err := h.service.DeleteComment(c.Request.Context(), uri.ID, userID); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -30,6 +30,7 @@ func InitCompanyHandler(g *gin.RouterGroup, service *company.CompanyService, log
 	{
 		r.POST("/", middleware.AuthMiddleware(manager, logger, mapper), h.CreateCompany)
 		r.GET("/", middleware.AuthMiddleware(manager, logger, mapper), h.GetCompanies)
+		r.GET("/:id/storage", middleware.AuthMiddleware(manager, logger, mapper), h.GetStorageUsage)
 	}
 }
 
@@ -38,7 +39,7 @@ func (h *CompanyHandler) CreateCompany(c *gin.Context) {
 	userID := response.GetUserID(c)
 	traceID := response.GetTraceID(c)
 
-	if err := c.ShouldBind(&req); err != nil {
+	if err := bindMultipart(c, &req, file.CompanyProfile); err != nil {
 		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
 		problem.Send(c)
 		return
@@ -93,3 +94,24 @@ func (h *CompanyHandler) GetCompanies(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.NewPaginationResponse(companiesResponse, params, count))
 }
+
+func (h *CompanyHandler) GetStorageUsage(c *gin.Context) {
+	var req dto.CompanyIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	usage, err := h.service.GetStorageUsage(c.Request.Context(), req.ID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewStorageUsageResponse(usage))
+}
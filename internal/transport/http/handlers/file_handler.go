@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/service/file"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type FileHandler struct {
+	service *file.FileService
+	mapper  *response.ErrorMapper
+	logger  *zap.Logger
+}
+
+func InitFileHandler(g *gin.RouterGroup, service *file.FileService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &FileHandler{
+		service: service,
+		mapper:  mapper,
+		logger:  logger,
+	}
+	r := g.Group("/files")
+	{
+		r.GET("/:id", middleware.OptionalAuthMiddleware(manager, logger, mapper), h.GetFile)
+		r.DELETE("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.DeleteFile)
+	}
+}
+
+// GetFile godoc
+// @Summary Download file
+// @Description Stream file content. Supports Range requests. Avatars are public, task and comment files require company membership
+// @Tags files
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Param variant query string false "Image variant: 64, 256 or original"
+// @Success 200 {file} file "File content"
+// @Success 206 {file} file "Partial file content"
+// @Success 302 "Redirect to presigned storage URL"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Don't have permissions"
+// @Failure 404 {object} response.ProblemDetail "File not found"
+// @Failure 500 {object} response.ProblemDetail "Internal server error"
+// @Router /files/{id} [get]
+func (h *FileHandler) GetFile(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	download, err := h.service.Download(c.Request.Context(), c.Param("id"), c.Query("variant"), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if download.URL != "" {
+		c.Redirect(http.StatusFound, download.URL)
+		return
+	}
+	defer download.Content.Close()
+
+	c.Header("Content-Disposition", download.File.ContentDisposition())
+	if download.File.MimeType != "" {
+		c.Header("Content-Type", download.File.MimeType)
+	}
+
+	http.ServeContent(c.Writer, c.Request, download.File.Name, download.ModTime, download.Content)
+}
+
+// DeleteFile godoc
+// @Summary Delete file
+// @Description Remove file from its task, comment, company or user and delete it from storage
+// @Tags files
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "File ID"
+// @Success 204 "File deleted"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Don't have permissions"
+// @Failure 404 {object} response.ProblemDetail "File not found"
+// @Failure 500 {object} response.ProblemDetail "Internal server error"
+// @Router /files/{id} [delete]
+func (h *FileHandler) DeleteFile(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := h.service.DeleteFile(c.Request.Context(), c.Param("id"), userID); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -41,6 +41,7 @@ func InitInviteHandler(g *gin.RouterGroup, service *invite.InviteService, logger
 // @Security BearerAuth
 // @Success 201 {object} dto.InviteResponse "Successfully created invite link"
 // @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Roles grant permissions the caller doesn't have"
 // @Failure 500 {object} response.ProblemDetail "Internal server error"
 // @Router /invite [post]
 func (h *InviteHandler) CreateInvite(c *gin.Context) {
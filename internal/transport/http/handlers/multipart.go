@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"rttask/internal/domain/service/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindMultipart ограничивает тело запроса до разбора формы: иначе gin прочитает все файлы раньше,
+// чем сервис проверит их суммарный размер
+func bindMultipart(c *gin.Context, obj any, profile file.ValidationProfile) error {
+	if limit := profile.MaxRequestSize(); limit > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	}
+	err := c.ShouldBind(obj)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return profile.TotalSizeError()
+	}
+	return err
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/service/notification"
+	"rttask/internal/domain/valueobject"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/dto"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type NotificationHandler struct {
+	service      *notification.NotificationService
+	emailService *notification.EmailService
+	mapper       *response.ErrorMapper
+	logger       *zap.Logger
+}
+
+func InitNotificationHandler(g *gin.RouterGroup, service *notification.NotificationService, emailService *notification.EmailService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &NotificationHandler{
+		service:      service,
+		emailService: emailService,
+		mapper:       mapper,
+		logger:       logger,
+	}
+	r := g.Group("/notifications")
+	{
+		r.GET("/", middleware.AuthMiddleware(manager, logger, mapper), h.GetNotifications)
+		r.POST("/read", middleware.AuthMiddleware(manager, logger, mapper), h.MarkRead)
+		r.POST("/read-all", middleware.AuthMiddleware(manager, logger, mapper), h.MarkAllRead)
+		r.GET("/preferences", middleware.AuthMiddleware(manager, logger, mapper), h.GetPreferences)
+		r.PUT("/preferences", middleware.AuthMiddleware(manager, logger, mapper), h.UpdatePreferences)
+	}
+}
+
+// GetNotifications godoc
+// @Summary List notifications
+// @Description Notifications of the current user, newest first, with the number of unread ones
+// @Tags notification
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size (max 100)"
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} dto.NotificationPageResponse "Notifications"
+// @Failure 400 {object} response.ProblemDetail "Invalid parameters"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	var params dto.NotificationParams
+	params.Default()
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	notifications, total, unread, err := h.service.ListNotifications(c.Request.Context(), userID, params.Unread,
+		valueobject.NewPaginationParams(params.Page, params.PageSize))
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NotificationPageResponse{
+		PaginationResponse: dto.NewPaginationResponse(dto.NewMultiplyNotificationResponse(notifications), params.PaginationRequest, total),
+		Unread:             unread,
+	})
+}
+
+// MarkRead godoc
+// @Summary Mark notifications read
+// @Tags notification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.MarkReadRequest true "Notification IDs"
+// @Success 200 {object} dto.UnreadResponse "Unread left"
+// @Failure 400 {object} response.ProblemDetail "Invalid request"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Router /notifications/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	var req dto.MarkReadRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	unread, err := h.service.MarkRead(c.Request.Context(), userID, req.IDs)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.UnreadResponse{Unread: unread})
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications read
+// @Tags notification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.UnreadResponse "Unread left"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	unread, err := h.service.MarkRead(c.Request.Context(), userID, nil)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.UnreadResponse{Unread: unread})
+}
+
+// GetPreferences godoc
+// @Summary Get email preferences
+// @Description Email delivery mode per notification type (instant, digest, off) and the email language
+// @Tags notification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.EmailSettingsResponse "Email preferences"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	settings, err := h.emailService.GetSettings(c.Request.Context(), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewEmailSettingsResponse(settings))
+}
+
+// UpdatePreferences godoc
+// @Summary Update email preferences
+// @Description Types missing from modes keep their current mode, an empty locale resets the language to the default one
+// @Tags notification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.EmailSettingsRequest true "Email preferences"
+// @Success 200 {object} dto.EmailSettingsResponse "Email preferences"
+// @Failure 400 {object} response.ProblemDetail "Invalid request"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req dto.EmailSettingsRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	settings, err := h.emailService.UpdateSettings(c.Request.Context(), userID, notification.EmailSettingsInput{
+		Locale: req.Locale,
+		Modes:  req.Modes,
+	})
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewEmailSettingsResponse(settings))
+}
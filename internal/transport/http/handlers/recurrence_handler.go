@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/service/task"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/dto"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type RecurrenceHandler struct {
+	service *task.TaskService
+	mapper  *response.ErrorMapper
+	logger  *zap.Logger
+}
+
+func InitRecurrenceHandler(g *gin.RouterGroup, service *task.TaskService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &RecurrenceHandler{
+		service: service,
+		mapper:  mapper,
+		logger:  logger,
+	}
+	r := g.Group("/recurrence")
+	{
+		r.POST("/", middleware.AuthMiddleware(manager, logger, mapper), h.CreateRecurrence)
+		r.DELETE("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.DeleteRecurrence)
+	}
+	g.GET("/company/:id/recurrences", middleware.AuthMiddleware(manager, logger, mapper), h.GetRecurrences)
+}
+
+// CreateRecurrence godoc
+// @Summary Create recurring task
+// @Description Task template with a recurrence rule (RFC 5545 subset: FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, BYDAY, UNTIL, COUNT). Tasks are created ahead of time by the scheduler
+// @Tags recurrence
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.RecurrenceRequest true "Template and rule"
+// @Success 201 {object} dto.RecurrenceResponse "Created recurrence"
+// @Failure 400 {object} response.ProblemDetail "Invalid rule, timezone or assignees"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "No task:create and task:assign permissions"
+// @Router /recurrence [post]
+func (h *RecurrenceHandler) CreateRecurrence(c *gin.Context) {
+	var req dto.RecurrenceRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	recurrence, err := h.service.CreateRecurrence(c.Request.Context(), task.RecurrenceInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    req.Priority,
+		ExecutorID:  req.ExecutorID,
+		ExecutorIDs: req.ExecutorIDs,
+		WatcherIDs:  req.WatcherIDs,
+		CompanyID:   req.CompanyID,
+		RRule:       req.RRule,
+		Timezone:    req.Timezone,
+		StartAt:     req.StartAt,
+		Duration:    time.Duration(req.DurationMinutes) * time.Minute,
+	}, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewRecurrenceResponse(recurrence))
+}
+
+// GetRecurrences godoc
+// @Summary List recurring tasks of the company
+// @Tags recurrence
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Company ID"
+// @Success 200 {array} dto.RecurrenceResponse "Recurrences"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no task:list permission"
+// @Router /company/{id}/recurrences [get]
+func (h *RecurrenceHandler) GetRecurrences(c *gin.Context) {
+	var req dto.CompanyIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	recurrences, err := h.service.ListRecurrences(c.Request.Context(), req.ID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewMultiplyRecurrenceResponse(recurrences))
+}
+
+// DeleteRecurrence godoc
+// @Summary Stop recurring task
+// @Description Already created tasks are kept
+// @Tags recurrence
+// @Security BearerAuth
+// @Param id path int true "Recurrence ID"
+// @Success 204 "Deleted"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Only creator or task:delete"
+// @Failure 404 {object} response.ProblemDetail "Recurrence not found"
+// @Router /recurrence/{id} [delete]
+func (h *RecurrenceHandler) DeleteRecurrence(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	if err := h.service.DeleteRecurrence(c.Request.Context(), uri.ID, userID); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -23,6 +23,7 @@ func InitRoleHandler(r *gin.RouterGroup, service *role.RoleService, logger *zap.
 	g := r.Group("/role")
 	{
 		g.POST("/", middleware.AuthMiddleware(manager, logger, mapper), h.CreateRole)
+		g.POST("/assign", middleware.AuthMiddleware(manager, logger, mapper), h.AssignRoles)
 		g.GET("/permissions", h.GetAllPermissions)
 	}
 }
@@ -64,6 +65,45 @@ func (h *RoleHandler) CreateRole(c *gin.Context) {
 	c.JSON(http.StatusCreated, dto.NewRoleResponse(newRole))
 }
 
+// AssignRoles godoc
+// @Summary Assign roles to user
+// @Description Assign roles to a user. Roles can't grant permissions the caller doesn't have
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.AssignRolesRequest true "User and roles"
+// @Success 200 {object} dto.UserResponse "Roles assigned"
+// @Failure 400 {object} response.ProblemDetail "Not valid data"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Don't have permissions"
+// @Failure 404 {object} response.ProblemDetail "User not found"
+// @Failure 500 {object} response.ProblemDetail "Internal server error"
+// @Router /role/assign [post]
+func (h *RoleHandler) AssignRoles(c *gin.Context) {
+	var req dto.AssignRolesRequest
+	traceID := response.GetTraceID(c)
+	userID := response.GetUserID(c)
+
+	if err := c.ShouldBind(&req); err != nil {
+		h.logger.Error("bind json error", zap.Error(err))
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID)
+		problem.Send(c)
+		return
+	}
+
+	rawInput := role.AssignRolesInput{TargetUserID: req.UserID, RolesIDs: req.RolesIDs, UserID: userID}
+
+	user, err := h.service.AssignRoles(c.Request.Context(), rawInput)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID)
+		problem.Send(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewUserResponse(user))
+}
+
 // GetAllPermissions godoc
 // @Summary Systems permissions
 // @Description Get all system permissions for role.
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/model"
+	"rttask/internal/domain/service/search"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/dto"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SearchHandler struct {
+	service *search.SearchService
+	mapper  *response.ErrorMapper
+	logger  *zap.Logger
+}
+
+func InitSearchHandler(g *gin.RouterGroup, service *search.SearchService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &SearchHandler{
+		service: service,
+		mapper:  mapper,
+		logger:  logger,
+	}
+	r := g.Group("/search")
+	{
+		r.GET("/", middleware.AuthMiddleware(manager, logger, mapper), h.Search)
+	}
+}
+
+// Search godoc
+// @Summary Full-text search
+// @Description Search tasks and comments of the caller's companies. Results are ranked and grouped by entity type, snippets are escaped HTML with matches wrapped in <mark>
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search phrase, supports quotes, OR and -exclusion"
+// @Param companyId query int false "Restrict to one company"
+// @Param type query []string false "Entity types: task, comment" collectionFormat(multi)
+// @Param limit query int false "Max results per group (default 10, max 50)"
+// @Success 200 {object} dto.SearchResponse "Search results"
+// @Failure 400 {object} response.ProblemDetail "Invalid query"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no view permissions"
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	var req dto.SearchRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	types := make([]model.SearchEntityType, 0, len(req.Types))
+	for _, entityType := range req.Types {
+		types = append(types, model.SearchEntityType(entityType))
+	}
+	input := search.SearchInput{
+		Query:       req.Query,
+		CompanyID:   req.CompanyID,
+		EntityTypes: types,
+		Limit:       req.Limit,
+	}
+
+	result, err := h.service.Search(c.Request.Context(), input, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewSearchResponse(req.Query, result))
+}
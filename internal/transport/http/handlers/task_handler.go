@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"net/http"
+	"rttask/internal/domain/model"
 	"rttask/internal/domain/service/file"
 	"rttask/internal/domain/service/task"
+	"rttask/internal/domain/valueobject"
 	"rttask/internal/infrastructure/security"
 	"rttask/internal/transport/dto"
 	"rttask/internal/transport/http/middleware"
 	"rttask/internal/transport/http/response"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -28,7 +31,20 @@ func InitTaskHandler(g *gin.RouterGroup, service *task.TaskService, logger *zap.
 	r := g.Group("/task")
 	{
 		r.POST("/", middleware.AuthMiddleware(manager, logger, mapper), h.CreateTask)
+		r.GET("/", middleware.AuthMiddleware(manager, logger, mapper), h.GetTasks)
+		r.GET("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.GetTask)
+		r.POST("/:id/move", middleware.AuthMiddleware(manager, logger, mapper), h.MoveTask)
+		r.PUT("/:id/parent", middleware.AuthMiddleware(manager, logger, mapper), h.SetParent)
+		r.PUT("/:id/assignees", middleware.AuthMiddleware(manager, logger, mapper), h.SetAssignees)
+		r.POST("/:id/acknowledge", middleware.AuthMiddleware(manager, logger, mapper), h.Acknowledge)
+		r.DELETE("/:id/acknowledge", middleware.AuthMiddleware(manager, logger, mapper), h.Acknowledge)
+		r.POST("/:id/dependencies", middleware.AuthMiddleware(manager, logger, mapper), h.AddDependency)
+		r.DELETE("/:id/dependencies/:blockerId", middleware.AuthMiddleware(manager, logger, mapper), h.RemoveDependency)
+		r.POST("/:id/checklist", middleware.AuthMiddleware(manager, logger, mapper), h.AddChecklistItem)
+		r.PATCH("/:id/checklist/:itemId", middleware.AuthMiddleware(manager, logger, mapper), h.UpdateChecklistItem)
+		r.DELETE("/:id/checklist/:itemId", middleware.AuthMiddleware(manager, logger, mapper), h.DeleteChecklistItem)
 	}
+	g.GET("/company/:id/board", middleware.AuthMiddleware(manager, logger, mapper), h.GetBoard)
 }
 
 func (h *TaskHandler) CreateTask(c *gin.Context) {
@@ -36,7 +52,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	userID := response.GetUserID(c)
 	traceID := response.GetTraceID(c)
 
-	if err := c.ShouldBind(&req); err != nil {
+	if err := bindMultipart(c, &req, file.TaskProfile); err != nil {
 		h.logger.Error("failed to bind request", zap.Error(err))
 		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
 		problem.Send(c)
@@ -71,6 +87,10 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		StartAt:     req.StartAt,
 		DeadlineAt:  req.DeadlineAt,
 		Priority:    req.Priority,
+		FileIDs:     req.FileIDs,
+		ParentID:    req.ParentID,
+		ExecutorIDs: req.ExecutorIDs,
+		WatcherIDs:  req.WatcherIDs,
 	}
 
 	newTask, err := h.service.CreateTask(c.Request.Context(), rawData, fileInputs, userID)
@@ -79,5 +99,503 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		problem.Send(c)
 		return
 	}
-	c.JSON(http.StatusCreated, newTask)
+	c.JSON(http.StatusCreated, dto.NewTaskResponse(newTask))
+}
+
+func (h *TaskHandler) GetTasks(c *gin.Context) {
+	var params dto.TaskParams
+	params.Default()
+
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	sort, err := valueobject.ParseSort(params.Sort, valueobject.TaskSortFields)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	statuses := make([]model.Status, 0, len(params.Statuses))
+	for _, status := range params.Statuses {
+		statuses = append(statuses, model.Status(status))
+	}
+	filter := valueobject.TaskFilter{
+		CompanyIDs:   params.CompanyIDs,
+		ExecutorIDs:  params.ExecutorIDs,
+		WatcherIDs:   params.WatcherIDs,
+		CreatorIDs:   params.CreatorIDs,
+		Statuses:     statuses,
+		Priorities:   params.Priorities,
+		ParentIDs:    params.ParentIDs,
+		StartFrom:    params.StartFrom,
+		StartTo:      params.StartTo,
+		DeadlineFrom: params.DeadlineFrom,
+		DeadlineTo:   params.DeadlineTo,
+		Overdue:      params.Overdue,
+		Search:       strings.TrimSpace(params.Search),
+		Sort:         sort,
+	}
+	validParams := valueobject.NewPaginationParams(params.Page, params.PageSize)
+	if params.IsCursor() {
+		var cursor *valueobject.Cursor
+		if params.Cursor != "" {
+			cursor, err = valueobject.DecodeCursor(params.Cursor)
+			if err != nil {
+				problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+				problem.Send(c)
+				return
+			}
+		}
+		validParams = valueobject.NewCursorPaginationParams(cursor, params.PageSize)
+	}
+
+	page, err := h.service.ListTasks(c.Request.Context(), filter, validParams, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	items := dto.NewMultiplyTaskResponse(page.Items)
+	if params.IsCursor() {
+		c.JSON(http.StatusOK, dto.NewCursorPaginationResponse(items, page.NextCursor, page.PrevCursor, page.Total))
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewPaginationResponse(items, params.PaginationRequest, page.Total))
+}
+
+// GetBoard godoc
+// @Summary Company kanban board
+// @Description Tasks of the company grouped by status columns. Columns follow the status order, tasks inside a column are ordered by rank
+// @Tags task
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Company ID"
+// @Param limit query int false "Max tasks per column (default 50, max 200)"
+// @Success 200 {object} dto.BoardResponse "Board"
+// @Failure 400 {object} response.ProblemDetail "Invalid parameters"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no task:list permission"
+// @Router /company/{id}/board [get]
+func (h *TaskHandler) GetBoard(c *gin.Context) {
+	var req dto.CompanyIDRequest
+	var params dto.BoardParams
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	board, err := h.service.GetBoard(c.Request.Context(), req.ID, params.Limit, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewBoardResponse(board))
+}
+
+// MoveTask godoc
+// @Summary Move task on the board
+// @Description Changes task status and position in the column atomically. Status change follows the transition rules, neighbours must be adjacent in the target column
+// @Tags task
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body dto.MoveTaskRequest true "Target status and neighbours"
+// @Success 200 {object} dto.TaskResponse "Moved task"
+// @Failure 400 {object} response.ProblemDetail "Invalid status or transition"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not allowed to move the task"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Failure 409 {object} response.ProblemDetail "Board has changed, reload it"
+// @Router /task/{id}/move [post]
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var req dto.MoveTaskRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	moved, err := h.service.MoveTask(c.Request.Context(), task.MoveTaskInput{
+		TaskID:     uri.ID,
+		Status:     model.Status(req.Status),
+		PrevTaskID: req.PrevTaskID,
+		NextTaskID: req.NextTaskID,
+	}, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTaskResponse(moved))
+}
+
+// GetTask godoc
+// @Summary Get task
+// @Description Task with its checklist and progress of direct subtasks
+// @Tags task
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} dto.TaskResponse "Task"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no task:view permission"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Router /task/{id} [get]
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.GetTask(c.Request.Context(), uri.ID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTaskResponse(result))
+}
+
+// SetParent godoc
+// @Summary Set parent task
+// @Description Moves the task under another task of the same company. Cycles and trees deeper than 3 levels are rejected. parentId 0 makes the task a root task
+// @Tags task
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body dto.SetParentRequest true "New parent"
+// @Success 200 {object} dto.TaskResponse "Updated task"
+// @Failure 400 {object} response.ProblemDetail "Cycle, depth limit or parent from another company"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Only creator or task:update"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Router /task/{id}/parent [put]
+func (h *TaskHandler) SetParent(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var req dto.SetParentRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.SetParent(c.Request.Context(), uri.ID, req.ParentID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTaskResponse(result))
+}
+
+// AddChecklistItem godoc
+// @Summary Add checklist item
+// @Description Appends an item to the task checklist. Requires being the task creator or task:update
+// @Tags task
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body dto.ChecklistItemRequest true "Item"
+// @Success 201 {object} dto.ChecklistItemResponse "Created item"
+// @Failure 400 {object} response.ProblemDetail "Invalid title"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Only creator or task:update"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Router /task/{id}/checklist [post]
+func (h *TaskHandler) AddChecklistItem(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var req dto.ChecklistItemRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	item, err := h.service.AddChecklistItem(c.Request.Context(), uri.ID, req.Title, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewChecklistItemResponse(item))
+}
+
+// UpdateChecklistItem godoc
+// @Summary Update checklist item
+// @Description Renames or checks the item. Checking is allowed to the executor without task:update, renaming requires being the creator or task:update
+// @Tags task
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param itemId path int true "Checklist item ID"
+// @Param request body dto.UpdateChecklistItemRequest true "Changed fields"
+// @Success 200 {object} dto.ChecklistItemResponse "Updated item"
+// @Failure 400 {object} response.ProblemDetail "Invalid title"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not allowed"
+// @Failure 404 {object} response.ProblemDetail "Task or item not found"
+// @Router /task/{id}/checklist/{itemId} [patch]
+func (h *TaskHandler) UpdateChecklistItem(c *gin.Context) {
+	var uri dto.ChecklistItemURI
+	var req dto.UpdateChecklistItemRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	item, err := h.service.UpdateChecklistItem(c.Request.Context(), task.ChecklistItemInput{
+		TaskID: uri.ID,
+		ItemID: uri.ItemID,
+		Title:  req.Title,
+		Done:   req.Done,
+	}, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewChecklistItemResponse(item))
+}
+
+// DeleteChecklistItem godoc
+// @Summary Delete checklist item
+// @Tags task
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param itemId path int true "Checklist item ID"
+// @Success 204 "Deleted"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Only creator or task:update"
+// @Failure 404 {object} response.ProblemDetail "Task or item not found"
+// @Router /task/{id}/checklist/{itemId} [delete]
+func (h *TaskHandler) DeleteChecklistItem(c *gin.Context) {
+	var uri dto.ChecklistItemURI
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	if err := h.service.DeleteChecklistItem(c.Request.Context(), uri.ID, uri.ItemID, userID); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddDependency godoc
+// @Summary Add blocking task
+// @Description The task cannot be moved to "В работе" until the blocking task is completed. Dependencies forming a cycle are rejected
+// @Tags task
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Blocked task ID"
+// @Param request body dto.DependencyRequest true "Blocking task"
+// @Success 200 {object} dto.TaskResponse "Task with dependencies"
+// @Failure 400 {object} response.ProblemDetail "Cycle or task from another company"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Only creator or task:update"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Router /task/{id}/dependencies [post]
+func (h *TaskHandler) AddDependency(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var req dto.DependencyRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.AddDependency(c.Request.Context(), uri.ID, req.BlockerID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTaskResponse(result))
+}
+
+// RemoveDependency godoc
+// @Summary Remove blocking task
+// @Tags task
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Blocked task ID"
+// @Param blockerId path int true "Blocking task ID"
+// @Success 200 {object} dto.TaskResponse "Task with dependencies"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Only creator or task:update"
+// @Failure 404 {object} response.ProblemDetail "Task or dependency not found"
+// @Router /task/{id}/dependencies/{blockerId} [delete]
+func (h *TaskHandler) RemoveDependency(c *gin.Context) {
+	var uri dto.DependencyURI
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.RemoveDependency(c.Request.Context(), uri.ID, uri.BlockerID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTaskResponse(result))
+}
+
+// SetAssignees godoc
+// @Summary Replace task executors and watchers
+// @Description Requires task:assign. The first executor becomes the primary one, acknowledgments of remaining executors are kept. Every assignee must be a company member with task:view, executors also need task:changeStatus
+// @Tags task
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param request body dto.AssigneesRequest true "Executors and watchers"
+// @Success 200 {object} dto.TaskResponse "Updated task"
+// @Failure 400 {object} response.ProblemDetail "Invalid assignees"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "No task:assign permission"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Router /task/{id}/assignees [put]
+func (h *TaskHandler) SetAssignees(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var req dto.AssigneesRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.SetAssignees(c.Request.Context(), task.AssigneesInput{
+		TaskID:      uri.ID,
+		ExecutorIDs: req.ExecutorIDs,
+		WatcherIDs:  req.WatcherIDs,
+	}, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTaskResponse(result))
+}
+
+// Acknowledge godoc
+// @Summary Acknowledge own part of the task
+// @Description POST marks the caller's part as done, DELETE removes the acknowledgment. Only task executors
+// @Tags task
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} dto.TaskResponse "Updated task"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Caller is not an executor"
+// @Failure 404 {object} response.ProblemDetail "Task not found"
+// @Router /task/{id}/acknowledge [post]
+// @Router /task/{id}/acknowledge [delete]
+func (h *TaskHandler) Acknowledge(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	done := c.Request.Method == http.MethodPost
+	result, err := h.service.Acknowledge(c.Request.Context(), uri.ID, done, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTaskResponse(result))
 }
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/service/telegram"
+	"rttask/internal/infrastructure/security"
+	telegramclient "rttask/internal/infrastructure/telegram"
+	"rttask/internal/transport/dto"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// telegramSecretHeader заголовок, в котором Telegram присылает secret_token вебхука
+const telegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+type TelegramHandler struct {
+	service *telegram.TelegramService
+	mapper  *response.ErrorMapper
+	logger  *zap.Logger
+}
+
+func InitTelegramHandler(g *gin.RouterGroup, service *telegram.TelegramService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &TelegramHandler{
+		service: service,
+		mapper:  mapper,
+		logger:  logger,
+	}
+	r := g.Group("/telegram")
+	{
+		r.POST("/link-code", middleware.AuthMiddleware(manager, logger, mapper), h.CreateLinkCode)
+		r.GET("/link", middleware.AuthMiddleware(manager, logger, mapper), h.GetLink)
+		r.DELETE("/link", middleware.AuthMiddleware(manager, logger, mapper), h.Unlink)
+		r.POST("/webhook", h.Webhook)
+	}
+}
+
+// CreateLinkCode godoc
+// @Summary Create Telegram link code
+// @Description One-time code to link the Telegram account: send "/link CODE" to the bot or open the returned link. A new code invalidates the previous one
+// @Tags telegram
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} dto.TelegramLinkCodeResponse "Link code"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Router /telegram/link-code [post]
+func (h *TelegramHandler) CreateLinkCode(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	code, err := h.service.CreateLinkCode(c.Request.Context(), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.TelegramLinkCodeResponse{
+		Code:      code.Code,
+		Link:      h.service.DeepLink(code.Code),
+		ExpiresAt: code.ExpiresAt,
+	})
+}
+
+// GetLink godoc
+// @Summary Get Telegram link
+// @Tags telegram
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TelegramLinkResponse "Link status"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Router /telegram/link [get]
+func (h *TelegramHandler) GetLink(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	link, err := h.service.GetLink(c.Request.Context(), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTelegramLinkResponse(link))
+}
+
+// Unlink godoc
+// @Summary Unlink Telegram account
+// @Tags telegram
+// @Security BearerAuth
+// @Success 204 "Unlinked"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 404 {object} response.ProblemDetail "Account is not linked"
+// @Router /telegram/link [delete]
+func (h *TelegramHandler) Unlink(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := h.service.Unlink(c.Request.Context(), userID); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Webhook обновления от Telegram в режиме webhook. Ответ всегда 200, иначе Telegram будет
+// повторять то же обновление; ошибки обработки только логируются
+func (h *TelegramHandler) Webhook(c *gin.Context) {
+	if !h.service.VerifyWebhookSecret(c.GetHeader(telegramSecretHeader)) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	var update telegramclient.Update
+	if err := c.ShouldBindJSON(&update); err != nil {
+		h.logger.Warn("invalid telegram update", zap.Error(err))
+		c.Status(http.StatusOK)
+		return
+	}
+	if err := h.service.HandleUpdate(c.Request.Context(), update); err != nil {
+		h.logger.Error("failed to handle telegram update", zap.Int64("updateID", update.UpdateID), zap.Error(err))
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/service/template"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/dto"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type TemplateHandler struct {
+	service *template.TemplateService
+	mapper  *response.ErrorMapper
+	logger  *zap.Logger
+}
+
+func InitTemplateHandler(g *gin.RouterGroup, service *template.TemplateService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &TemplateHandler{
+		service: service,
+		mapper:  mapper,
+		logger:  logger,
+	}
+	r := g.Group("/template")
+	{
+		r.POST("/", middleware.AuthMiddleware(manager, logger, mapper), h.CreateTemplate)
+		r.GET("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.GetTemplate)
+		r.PUT("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.UpdateTemplate)
+		r.DELETE("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.DeleteTemplate)
+	}
+	g.GET("/company/:id/templates", middleware.AuthMiddleware(manager, logger, mapper), h.GetTemplates)
+	g.POST("/task/from-template/:id", middleware.AuthMiddleware(manager, logger, mapper), h.CreateTaskFromTemplate)
+}
+
+// CreateTemplate godoc
+// @Summary Create task template
+// @Description Template of a company task. Title and description may contain {{placeholders}}, built-in ones are date, deadline, company and executor
+// @Tags template
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TemplateRequest true "Template"
+// @Success 201 {object} dto.TemplateResponse "Created template"
+// @Failure 400 {object} response.ProblemDetail "Invalid template"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no task:create permission"
+// @Router /template [post]
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req dto.TemplateRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.CreateTemplate(c.Request.Context(), newTemplateInput(req), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewTemplateResponse(result))
+}
+
+// GetTemplate godoc
+// @Summary Get task template
+// @Tags template
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} dto.TemplateResponse "Template"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no task:view permission"
+// @Failure 404 {object} response.ProblemDetail "Template not found"
+// @Router /template/{id} [get]
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.GetTemplate(c.Request.Context(), uri.ID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTemplateResponse(result))
+}
+
+// GetTemplates godoc
+// @Summary List task templates of the company
+// @Tags template
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Company ID"
+// @Success 200 {array} dto.TemplateResponse "Templates"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no task:list permission"
+// @Router /company/{id}/templates [get]
+func (h *TemplateHandler) GetTemplates(c *gin.Context) {
+	var req dto.CompanyIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	templates, err := h.service.ListTemplates(c.Request.Context(), req.ID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewMultiplyTemplateResponse(templates))
+}
+
+// UpdateTemplate godoc
+// @Summary Update task template
+// @Description Replaces template fields. Files from fileIds are added, existing files are removed with DELETE /files/{id}
+// @Tags template
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Param request body dto.TemplateRequest true "Template"
+// @Success 200 {object} dto.TemplateResponse "Updated template"
+// @Failure 400 {object} response.ProblemDetail "Invalid template"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Only creator or task:update"
+// @Failure 404 {object} response.ProblemDetail "Template not found"
+// @Router /template/{id} [put]
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var req dto.TemplateRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.UpdateTemplate(c.Request.Context(), uri.ID, newTemplateInput(req), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewTemplateResponse(result))
+}
+
+// DeleteTemplate godoc
+// @Summary Delete task template
+// @Description Tasks created from the template are kept
+// @Tags template
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Success 204 "Deleted"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Only creator or task:delete"
+// @Failure 404 {object} response.ProblemDetail "Template not found"
+// @Router /template/{id} [delete]
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	if err := h.service.DeleteTemplate(c.Request.Context(), uri.ID, userID); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// CreateTaskFromTemplate godoc
+// @Summary Create task from template
+// @Description Fills placeholders, copies checklist and files of the template. The task is validated the same way as POST /task
+// @Tags template
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Param request body dto.FromTemplateRequest true "Start, assignees and placeholder values"
+// @Success 201 {object} dto.TaskResponse "Created task"
+// @Failure 400 {object} response.ProblemDetail "Missing placeholder values or invalid task"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "No task:create and task:assign permissions"
+// @Failure 404 {object} response.ProblemDetail "Template not found"
+// @Router /task/from-template/{id} [post]
+func (h *TemplateHandler) CreateTaskFromTemplate(c *gin.Context) {
+	var uri dto.TaskIDRequest
+	var req dto.FromTemplateRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	result, err := h.service.CreateTask(c.Request.Context(), template.FromTemplateInput{
+		TemplateID:  uri.ID,
+		StartAt:     req.StartAt,
+		ExecutorID:  req.ExecutorID,
+		ExecutorIDs: req.ExecutorIDs,
+		WatcherIDs:  req.WatcherIDs,
+		ParentID:    req.ParentID,
+		Priority:    req.Priority,
+		Values:      req.Values,
+	}, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.NewTaskResponse(result))
+}
+
+func newTemplateInput(req dto.TemplateRequest) template.TemplateInput {
+	return template.TemplateInput{
+		CompanyID:     req.CompanyID,
+		Name:          req.Name,
+		Title:         req.Title,
+		Description:   req.Description,
+		Priority:      req.Priority,
+		ExecutorID:    req.ExecutorID,
+		Checklist:     req.Checklist,
+		DeadlineAfter: time.Duration(req.DeadlineAfterMinutes) * time.Minute,
+		FileIDs:       req.FileIDs,
+	}
+}
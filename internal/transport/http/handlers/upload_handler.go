@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/service/file"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/dto"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	tusResumable      = "1.0.0"
+	offsetContentType = "application/offset+octet-stream"
+)
+
+type UploadHandler struct {
+	service *file.UploadService
+	mapper  *response.ErrorMapper
+	logger  *zap.Logger
+}
+
+func InitUploadHandler(g *gin.RouterGroup, service *file.UploadService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &UploadHandler{
+		service: service,
+		mapper:  mapper,
+		logger:  logger,
+	}
+	r := g.Group("/uploads")
+	r.Use(middleware.AuthMiddleware(manager, logger, mapper))
+	{
+		r.POST("/", h.CreateUpload)
+		r.HEAD("/:id", h.GetOffset)
+		r.PATCH("/:id", h.WriteChunk)
+		r.GET("/:id", h.GetUpload)
+	}
+}
+
+// CreateUpload godoc
+// @Summary Create resumable upload
+// @Description Start a tus-style resumable upload. Upload-Metadata carries base64 encoded filename, filetype and entityType (task or comment)
+// @Tags uploads
+// @Produce json
+// @Security BearerAuth
+// @Param Upload-Length header int true "Total file size in bytes"
+// @Param Upload-Metadata header string false "filename <base64>,filetype <base64>,entityType <base64>"
+// @Success 201 {object} dto.UploadResponse "Upload created, Location header points to it"
+// @Failure 400 {object} response.ProblemDetail "Invalid size or file type"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 500 {object} response.ProblemDetail "Internal server error"
+// @Router /uploads [post]
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+	c.Header("Tus-Resumable", tusResumable)
+
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		h.sendBadRequest(c, "Invalid Upload-Length header")
+		return
+	}
+	metadata, err := dto.ParseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		h.sendBadRequest(c, err.Error())
+		return
+	}
+	entityType := metadata["entityType"]
+	if entityType == "" {
+		entityType = "task"
+	}
+
+	input := file.UploadInput{
+		Length:     length,
+		FileName:   metadata["filename"],
+		MimeType:   metadata["filetype"],
+		EntityType: entityType,
+		UploaderID: userID,
+	}
+	upload, err := h.service.CreateUpload(c.Request.Context(), input)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	c.Header("Location", "/uploads/"+upload.ID)
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.JSON(http.StatusCreated, dto.NewUploadResponse(upload))
+}
+
+// GetOffset godoc
+// @Summary Upload progress
+// @Description Returns received bytes in Upload-Offset header so the client can resume
+// @Tags uploads
+// @Security BearerAuth
+// @Param id path string true "Upload ID"
+// @Success 200 "Upload-Offset and Upload-Length headers"
+// @Failure 404 {object} response.ProblemDetail "Upload not found"
+// @Router /uploads/{id} [head]
+func (h *UploadHandler) GetOffset(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+	c.Header("Tus-Resumable", tusResumable)
+	c.Header("Cache-Control", "no-store")
+
+	upload, err := h.service.GetUpload(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		c.Status(problem.Status)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusOK)
+}
+
+// WriteChunk godoc
+// @Summary Upload chunk
+// @Description Append bytes starting at Upload-Offset. When all bytes are received the file is assembled and scanned within the same request, and its ID returned in Upload-File-Id header. The final request takes longer for large files. If it fails with 5xx, the parts are kept and an empty PATCH at the final offset retries assembly. While another request is assembling the upload, PATCH returns 409
+// @Tags uploads
+// @Accept application/offset+octet-stream
+// @Security BearerAuth
+// @Param id path string true "Upload ID"
+// @Param Upload-Offset header int true "Offset of this chunk"
+// @Success 204 "Chunk accepted, new offset in Upload-Offset header"
+// @Failure 400 {object} response.ProblemDetail "Invalid headers or file content"
+// @Failure 404 {object} response.ProblemDetail "Upload not found"
+// @Failure 409 {object} response.ProblemDetail "Offset mismatch, or the upload is being assembled by another request"
+// @Failure 415 {object} response.ProblemDetail "Wrong Content-Type"
+// @Failure 500 {object} response.ProblemDetail "Assembly or antivirus failed, retry with an empty PATCH"
+// @Router /uploads/{id} [patch]
+func (h *UploadHandler) WriteChunk(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+	c.Header("Tus-Resumable", tusResumable)
+
+	if c.ContentType() != offsetContentType {
+		problem := response.NewProblemDetail(
+			http.StatusUnsupportedMediaType,
+			"Unsupported Media Type",
+			"Content-Type must be "+offsetContentType,
+		).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		h.sendBadRequest(c, "Invalid Upload-Offset header")
+		return
+	}
+
+	upload, err := h.service.WriteChunk(c.Request.Context(), c.Param("id"), offset, c.Request.Body, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if upload.File != nil {
+		c.Header("Upload-File-Id", upload.File.ID)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetUpload godoc
+// @Summary Upload status
+// @Description Upload state and the resulting file once all bytes are received
+// @Tags uploads
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Upload ID"
+// @Success 200 {object} dto.UploadResponse "Upload"
+// @Failure 404 {object} response.ProblemDetail "Upload not found"
+// @Router /uploads/{id} [get]
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	upload, err := h.service.GetUpload(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewUploadResponse(upload))
+}
+
+func (h *UploadHandler) sendBadRequest(c *gin.Context, detail string) {
+	problem := response.NewProblemDetail(
+		http.StatusBadRequest,
+		"Bad Request",
+		detail,
+	).WithTraceID(response.GetTraceID(c)).WithInstance(c.Request.URL.Path)
+	problem.Send(c)
+}
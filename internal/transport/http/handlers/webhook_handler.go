@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"net/http"
+	"rttask/internal/domain/service/webhook"
+	"rttask/internal/domain/valueobject"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/dto"
+	"rttask/internal/transport/http/middleware"
+	"rttask/internal/transport/http/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler struct {
+	service *webhook.WebhookService
+	mapper  *response.ErrorMapper
+	logger  *zap.Logger
+}
+
+func InitWebhookHandler(g *gin.RouterGroup, service *webhook.WebhookService, logger *zap.Logger, manager security.JWTManager, mapper *response.ErrorMapper) {
+	h := &WebhookHandler{
+		service: service,
+		mapper:  mapper,
+		logger:  logger,
+	}
+	r := g.Group("/webhook")
+	{
+		r.POST("/", middleware.AuthMiddleware(manager, logger, mapper), h.CreateWebhook)
+		r.PUT("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.UpdateWebhook)
+		r.DELETE("/:id", middleware.AuthMiddleware(manager, logger, mapper), h.DeleteWebhook)
+		r.POST("/:id/test", middleware.AuthMiddleware(manager, logger, mapper), h.SendTest)
+		r.GET("/:id/deliveries", middleware.AuthMiddleware(manager, logger, mapper), h.GetDeliveries)
+		r.POST("/:id/deliveries/:deliveryId/retry", middleware.AuthMiddleware(manager, logger, mapper), h.RetryDelivery)
+	}
+	g.GET("/company/:id/webhooks", middleware.AuthMiddleware(manager, logger, mapper), h.GetWebhooks)
+}
+
+// CreateWebhook godoc
+// @Summary Create webhook
+// @Description Company subscription to events. Every request is a signed JSON POST: X-RTTask-Signature is "sha256=" + hex HMAC-SHA256 of "<X-RTTask-Timestamp>.<body>" with the webhook secret. Failed deliveries are retried with exponential backoff and become dead after the last attempt
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.WebhookRequest true "Webhook"
+// @Success 201 {object} dto.WebhookResponse "Created webhook with its secret"
+// @Failure 400 {object} response.ProblemDetail "Invalid webhook"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no company:update permission"
+// @Router /webhook [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req dto.WebhookRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	created, err := h.service.CreateWebhook(c.Request.Context(), webhook.WebhookInput{
+		CompanyID: req.CompanyID,
+		URL:       req.URL,
+		Events:    req.Events,
+		Secret:    req.Secret,
+	}, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	resp := dto.NewWebhookResponse(created)
+	resp.Secret = created.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetWebhooks godoc
+// @Summary List webhooks of the company
+// @Tags webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Company ID"
+// @Success 200 {array} dto.WebhookResponse "Webhooks"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no company:update permission"
+// @Router /company/{id}/webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	var req dto.CompanyIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	webhooks, err := h.service.ListWebhooks(c.Request.Context(), req.ID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewMultiplyWebhookResponse(webhooks))
+}
+
+// UpdateWebhook godoc
+// @Summary Update webhook
+// @Description companyId is ignored. Empty secret keeps the current one, missing active keeps the webhook active
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param request body dto.WebhookRequest true "Webhook"
+// @Success 200 {object} dto.WebhookResponse "Updated webhook"
+// @Failure 400 {object} response.ProblemDetail "Invalid webhook"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no company:update permission"
+// @Failure 404 {object} response.ProblemDetail "Webhook not found"
+// @Router /webhook/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	var uri dto.WebhookIDRequest
+	var req dto.WebhookRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	updated, err := h.service.UpdateWebhook(c.Request.Context(), uri.ID, webhook.WebhookInput{
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: req.Secret,
+		Active: req.Active == nil || *req.Active,
+	}, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewWebhookResponse(updated))
+}
+
+// DeleteWebhook godoc
+// @Summary Delete webhook
+// @Description Pending deliveries of the webhook become dead
+// @Tags webhook
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 204 "Deleted"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no company:update permission"
+// @Failure 404 {object} response.ProblemDetail "Webhook not found"
+// @Router /webhook/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	var uri dto.WebhookIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), uri.ID, userID); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SendTest godoc
+// @Summary Send test event
+// @Description Sends a signed webhook.test event right away and returns the delivery with its result. Test events are not retried automatically
+// @Tags webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} dto.WebhookDeliveryResponse "Delivery"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no company:update permission"
+// @Failure 404 {object} response.ProblemDetail "Webhook not found"
+// @Router /webhook/{id}/test [post]
+func (h *WebhookHandler) SendTest(c *gin.Context) {
+	var uri dto.WebhookIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	delivery, err := h.service.SendTest(c.Request.Context(), uri.ID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewWebhookDeliveryResponse(delivery))
+}
+
+// GetDeliveries godoc
+// @Summary Webhook delivery log
+// @Description Deliveries newest first, each with its attempts
+// @Tags webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param page query int false "Page number"
+// @Param pageSize query int false "Page size (max 100)"
+// @Success 200 {object} dto.PaginationResponse[dto.WebhookDeliveryResponse] "Deliveries"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no company:update permission"
+// @Failure 404 {object} response.ProblemDetail "Webhook not found"
+// @Router /webhook/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	var uri dto.WebhookIDRequest
+	var params dto.PaginationRequest
+	params.Default()
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	deliveries, total, err := h.service.ListDeliveries(c.Request.Context(), uri.ID,
+		valueobject.NewPaginationParams(params.Page, params.PageSize), userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewPaginationResponse(dto.NewMultiplyWebhookDeliveryResponse(deliveries), params, total))
+}
+
+// RetryDelivery godoc
+// @Summary Retry delivery
+// @Description Puts a dead or delivered delivery back to the queue with a fresh attempt counter
+// @Tags webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} dto.WebhookDeliveryResponse "Delivery"
+// @Failure 401 {object} response.ProblemDetail "Unauthorized - invalid or missing token"
+// @Failure 403 {object} response.ProblemDetail "Not a company member or no company:update permission"
+// @Failure 404 {object} response.ProblemDetail "Delivery not found"
+// @Failure 409 {object} response.ProblemDetail "Delivery is already pending"
+// @Router /webhook/{id}/deliveries/{deliveryId}/retry [post]
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	var uri dto.DeliveryIDRequest
+	userID := response.GetUserID(c)
+	traceID := response.GetTraceID(c)
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+
+	delivery, err := h.service.RetryDelivery(c.Request.Context(), uri.ID, uri.DeliveryID, userID)
+	if err != nil {
+		problem := h.mapper.MapError(c, err).WithTraceID(traceID).WithInstance(c.Request.URL.Path)
+		problem.Send(c)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewWebhookDeliveryResponse(delivery))
+}
@@ -88,3 +88,20 @@ func AuthMiddleware(
 		c.Next()
 	}
 }
+
+// OptionalAuthMiddleware пропускает запросы без заголовка Authorization,
+// а переданный заголовок проверяет так же, как AuthMiddleware
+func OptionalAuthMiddleware(
+	manager security.JWTManager,
+	logger *zap.Logger,
+	mapper *response.ErrorMapper,
+) gin.HandlerFunc {
+	auth := AuthMiddleware(manager, logger, mapper)
+	return func(c *gin.Context) {
+		if c.GetHeader(authorizationHeader) == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
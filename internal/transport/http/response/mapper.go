@@ -46,7 +46,7 @@ func (m *ErrorMapper) mapDomainErrorToHTTPStatus(errorType domainerrors.ErrorTyp
 		return http.StatusBadRequest // 400
 	case domainerrors.ErrorTypeNotFound:
 		return http.StatusNotFound // 404
-	case domainerrors.ErrorTypeAlreadyExist:
+	case domainerrors.ErrorTypeAlreadyExist, domainerrors.ErrorTypeConflict:
 		return http.StatusConflict // 409
 	case domainerrors.ErrorTypeUnauthorize:
 		return http.StatusUnauthorized // 401
@@ -65,6 +65,8 @@ func (m *ErrorMapper) getTitle(errorType domainerrors.ErrorType) string {
 		return "Resource Not Found"
 	case domainerrors.ErrorTypeAlreadyExist:
 		return "Resource Already Exists"
+	case domainerrors.ErrorTypeConflict:
+		return "Conflict"
 	case domainerrors.ErrorTypeUnauthorize:
 		return "Unauthorized"
 	case domainerrors.ErrorTypeForbidden:
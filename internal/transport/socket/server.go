@@ -1,32 +1,252 @@
 package socket
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"rttask/internal/domain/event"
 	"rttask/internal/domain/service/task"
+	"rttask/internal/infrastructure/security"
+	"rttask/internal/transport/dto"
+	"sync"
 
 	"github.com/doquangtan/socketio/v4"
 	"go.uber.org/zap"
 )
 
+// События от клиента
+const (
+	authenticateEvent = "authenticate"
+	boardJoinEvent    = "board:join"
+	boardLeaveEvent   = "board:leave"
+)
+
+// События от сервера
+const (
+	TaskMovedEvent        = "task:moved"
+	BlockerCompletedEvent = "task:blocker-completed"
+	DeadlineEvent         = "task:deadline"
+	NotificationEvent     = "notification:new"
+)
+
 type SocketServer struct {
-	io     *socketio.Io
-	logger *zap.Logger
+	io      *socketio.Io
+	manager security.JWTManager
+	logger  *zap.Logger
 
 	taskService *task.TaskService
+
+	mu    sync.RWMutex
+	users map[string]uint // id сокета -> id пользователя
 }
 
-func NewSocketServer(taskService *task.TaskService, logger *zap.Logger) *SocketServer {
+func NewSocketServer(taskService *task.TaskService, manager security.JWTManager, bus *event.Bus, logger *zap.Logger) *SocketServer {
 	io := socketio.New()
 
 	server := &SocketServer{
 		io:          io,
+		manager:     manager,
 		logger:      logger,
 		taskService: taskService,
+		users:       make(map[string]uint),
 	}
 
+	io.OnConnection(server.onConnection)
+	bus.Subscribe(event.TaskMoved, server.onTaskMoved)
+	bus.Subscribe(event.BlockerCompleted, server.onBlockerCompleted)
+	bus.Subscribe(event.DeadlineReminder, server.onDeadline)
+	bus.Subscribe(event.TaskOverdue, server.onDeadline)
+	bus.Subscribe(event.NotificationCreated, server.onNotification)
+
 	return server
 }
 
 func (s *SocketServer) HttpHandler() http.Handler {
 	return s.io.HttpHandler()
 }
+
+// UserRoom комната всех сокетов пользователя
+func UserRoom(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// BoardRoom комната тех, у кого открыта доска компании
+func BoardRoom(companyID uint) string {
+	return fmt.Sprintf("company:%d:board", companyID)
+}
+
+func (s *SocketServer) onConnection(socket *socketio.Socket) {
+	socket.On(authenticateEvent, s.onAuthenticate)
+	socket.On(boardJoinEvent, s.onBoardJoin)
+	socket.On(boardLeaveEvent, s.onBoardLeave)
+	socket.On("disconnect", func(payload *socketio.EventPayload) {
+		s.mu.Lock()
+		delete(s.users, payload.SID)
+		s.mu.Unlock()
+	})
+}
+
+type authenticateMessage struct {
+	Token string `json:"token"`
+}
+
+type boardMessage struct {
+	CompanyID uint `json:"companyId"`
+}
+
+// onAuthenticate клиент присылает access токен первым событием, без него остальные события отклоняются
+func (s *SocketServer) onAuthenticate(payload *socketio.EventPayload) {
+	var msg authenticateMessage
+	if err := decode(payload, &msg); err != nil {
+		reply(payload, err)
+		return
+	}
+
+	claims, err := s.manager.ValidateToken(msg.Token)
+	if err != nil || claims.Type != security.AccessToken {
+		s.logger.Warn("socket authentication failed", zap.String("socketID", payload.SID), zap.Error(err))
+		reply(payload, fmt.Errorf("invalid or expired token"))
+		payload.Socket.Disconnect()
+		return
+	}
+
+	s.mu.Lock()
+	s.users[payload.SID] = claims.UserID
+	s.mu.Unlock()
+
+	payload.Socket.Join(UserRoom(claims.UserID))
+	reply(payload, nil)
+}
+
+func (s *SocketServer) onBoardJoin(payload *socketio.EventPayload) {
+	userID, ok := s.userID(payload.SID)
+	if !ok {
+		reply(payload, fmt.Errorf("not authenticated"))
+		return
+	}
+
+	var msg boardMessage
+	if err := decode(payload, &msg); err != nil {
+		reply(payload, err)
+		return
+	}
+
+	if err := s.taskService.AuthorizeBoard(context.Background(), msg.CompanyID, userID); err != nil {
+		reply(payload, err)
+		return
+	}
+
+	payload.Socket.Join(BoardRoom(msg.CompanyID))
+	reply(payload, nil)
+}
+
+func (s *SocketServer) onBoardLeave(payload *socketio.EventPayload) {
+	var msg boardMessage
+	if err := decode(payload, &msg); err != nil {
+		reply(payload, err)
+		return
+	}
+	payload.Socket.Leave(BoardRoom(msg.CompanyID))
+	reply(payload, nil)
+}
+
+// onTaskMoved рассылает перемещение всем, у кого открыта доска, кроме сокетов самого автора:
+// его клиент уже применил изменение по ответу HTTP
+func (s *SocketServer) onTaskMoved(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.TaskMovedPayload)
+	if !ok {
+		return
+	}
+	msg := dto.NewTaskMovedMessage(e, payload)
+
+	for _, socket := range s.io.To(BoardRoom(e.CompanyID)).Sockets() {
+		if userID, _ := s.userID(socket.Id); userID == e.ActorID {
+			continue
+		}
+		if err := socket.Emit(TaskMovedEvent, msg); err != nil {
+			s.logger.Warn("failed to emit socket event", zap.String("socketID", socket.Id), zap.Error(err))
+		}
+	}
+}
+
+// onBlockerCompleted сообщение получают исполнители ожидающей задачи во все свои сокеты
+func (s *SocketServer) onBlockerCompleted(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.BlockerCompletedPayload)
+	if !ok {
+		return
+	}
+	msg := dto.NewBlockerCompletedMessage(e, payload)
+	for _, executorID := range payload.Task.ExecutorIDs() {
+		if executorID == e.ActorID {
+			continue
+		}
+		if err := s.io.To(UserRoom(executorID)).Emit(BlockerCompletedEvent, msg); err != nil {
+			s.logger.Warn("failed to emit socket event", zap.Uint("userID", executorID), zap.Error(err))
+		}
+	}
+}
+
+// onDeadline напоминание о сроке и просрочке получают исполнители и автор задачи
+func (s *SocketServer) onDeadline(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.DeadlinePayload)
+	if !ok {
+		return
+	}
+	msg := dto.NewDeadlineMessage(payload)
+	for _, userID := range payload.Recipients {
+		if err := s.io.To(UserRoom(userID)).Emit(DeadlineEvent, msg); err != nil {
+			s.logger.Warn("failed to emit socket event", zap.Uint("userID", userID), zap.Error(err))
+		}
+	}
+}
+
+// onNotification новое уведомление и число непрочитанных во все сокеты получателя
+func (s *SocketServer) onNotification(ctx context.Context, e event.Event) {
+	payload, ok := e.Payload.(event.NotificationCreatedPayload)
+	if !ok {
+		return
+	}
+	msg := dto.NotificationMessage{
+		Notification: dto.NewNotificationResponse(payload.Notification),
+		Unread:       payload.Unread,
+	}
+	recipientID := payload.Notification.RecipientID
+	if err := s.io.To(UserRoom(recipientID)).Emit(NotificationEvent, msg); err != nil {
+		s.logger.Warn("failed to emit socket event", zap.Uint("userID", recipientID), zap.Error(err))
+	}
+}
+
+func (s *SocketServer) userID(socketID string) (uint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	userID, ok := s.users[socketID]
+	return userID, ok
+}
+
+// decode первый аргумент события приходит разобранным JSON, поэтому перекладывается в структуру через json
+func decode(payload *socketio.EventPayload, dst any) error {
+	if len(payload.Data) == 0 {
+		return fmt.Errorf("empty payload")
+	}
+	raw, err := json.Marshal(payload.Data[0])
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("invalid payload")
+	}
+	return nil
+}
+
+// reply отвечает на событие, если клиент ждет подтверждения
+func reply(payload *socketio.EventPayload, err error) {
+	if payload.Ack == nil {
+		return
+	}
+	if err != nil {
+		payload.Ack(map[string]any{"ok": false, "error": err.Error()})
+		return
+	}
+	payload.Ack(map[string]any{"ok": true})
+}